@@ -35,13 +35,41 @@ func NewAuthorizer(v *viper.Viper, logger *zap.SugaredLogger) (IAuthorizer, erro
 		a = &BasicAuthorizer{}
 	case "keycloak":
 		a = &KeycloakAuthorizer{}
+	case "oidc":
+		a = &OIDCAuthorizer{}
+	case "mtls":
+		a = &MTLSAuthorizer{}
 	default:
 		return nil, fmt.Errorf("backend %q is not supported", cfg.Backend)
 	}
 
-	if err := a.Init(v, logger); err != nil {
+	permissions, err := NewPermissionsFromConfig(v.Get("permissions"))
+	if err != nil {
 		return nil, err
 	}
 
+	if err := a.Init(withoutPermissions(v), logger); err != nil {
+		return nil, err
+	}
+
+	if setter, ok := a.(permissionsSetter); ok {
+		setter.setPermissions(permissions)
+	}
+
 	return a, nil
 }
+
+// withoutPermissions returns a copy of the specified config without the
+// "permissions" entry, which is common to all backends (and so is not
+// expected by their config loaders).
+func withoutPermissions(v *viper.Viper) *viper.Viper {
+	ret := viper.New()
+
+	for key, val := range v.AllSettings() {
+		if key != "permissions" {
+			ret.Set(key, val)
+		}
+	}
+
+	return ret
+}
@@ -1,18 +1,23 @@
-// Copyright 2023 Contributors to the Veraison project.
+// Copyright 2023-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 
 package auth
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"github.com/veraison/services/log"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type basicAuthUser struct {
@@ -35,6 +40,10 @@ func newBasicAuthUser(m map[string]interface{}) (*basicAuthUser, error) {
 		return nil, fmt.Errorf("invalid password: expected string found %T", t)
 	}
 
+	if err := checkPasswordHash(newUser.Password); err != nil {
+		return nil, fmt.Errorf("invalid password: %w", err)
+	}
+
 	rolesRaw, ok := m["roles"]
 	if ok {
 		switch t := rolesRaw.(type) {
@@ -57,8 +66,22 @@ func newBasicAuthUser(m map[string]interface{}) (*basicAuthUser, error) {
 }
 
 type BasicAuthorizer struct {
+	permissionEnforcer
+
 	logger *zap.SugaredLogger
-	users  map[string]*basicAuthUser
+
+	// configUsers are the users specified in the config; they are fixed
+	// for the lifetime of the authorizer.
+	configUsers map[string]*basicAuthUser
+
+	// usersFile is the path to the htpasswd-style file users are
+	// additionally loaded from (if any). It is watched, and users are
+	// re-loaded whenever it changes.
+	usersFile string
+	watcher   *fsnotify.Watcher
+
+	mu    sync.RWMutex
+	users map[string]*basicAuthUser
 }
 
 func (o *BasicAuthorizer) Init(v *viper.Viper, logger *zap.SugaredLogger) error {
@@ -67,7 +90,7 @@ func (o *BasicAuthorizer) Init(v *viper.Viper, logger *zap.SugaredLogger) error
 	}
 	o.logger = logger
 
-	o.users = make(map[string]*basicAuthUser)
+	o.configUsers = make(map[string]*basicAuthUser)
 	if rawUsers := v.GetStringMap("users"); rawUsers != nil {
 		for name, rawUser := range rawUsers {
 			switch t := rawUser.(type) {
@@ -79,10 +102,9 @@ func (o *BasicAuthorizer) Init(v *viper.Viper, logger *zap.SugaredLogger) error
 				}
 				o.logger.Debugw("registered user",
 					"user", name,
-					"password", newUser.Password,
 					"roles", newUser.Roles,
 				)
-				o.users[name] = newUser
+				o.configUsers[name] = newUser
 			default:
 				return fmt.Errorf(
 					"invalid user %q: expected map[string]interface{}, got %T",
@@ -92,10 +114,24 @@ func (o *BasicAuthorizer) Init(v *viper.Viper, logger *zap.SugaredLogger) error
 		}
 	}
 
-	return nil
+	o.usersFile = v.GetString("users-file")
+	if o.usersFile == "" {
+		o.users = o.configUsers
+		return nil
+	}
+
+	if err := o.loadUsersFile(); err != nil {
+		return err
+	}
+
+	return o.watchUsersFile()
 }
 
 func (o *BasicAuthorizer) Close() error {
+	if o.watcher != nil {
+		return o.watcher.Close()
+	}
+
 	return nil
 }
 
@@ -111,18 +147,20 @@ func (o *BasicAuthorizer) GetGinHandler(role string) gin.HandlerFunc {
 			return
 		}
 
-		userInfo, ok := o.users[userName]
+		userInfo, ok := o.getUser(userName)
 		if !ok {
+			// Perform a comparison anyway, so that unknown users
+			// cannot be distinguished from wrong passwords by the
+			// time taken to respond.
+			_ = comparePassword(dummyHash, password)
+
 			c.Writer.Header().Set("WWW-Authenticate", "Basic realm=veraison")
 			ReportProblem(c, http.StatusUnauthorized,
-				"no Basic Authorizaiton given")
+				"wrong username or password")
 			return
 		}
 
-		if err := bcrypt.CompareHashAndPassword(
-			[]byte(userInfo.Password),
-			[]byte(password),
-		); err != nil {
+		if err := comparePassword(userInfo.Password, password); err != nil {
 			o.logger.Debugf("password check failed: %v", err)
 			c.Writer.Header().Set("WWW-Authenticate", "Basic realm=veraison")
 			ReportProblem(c, http.StatusUnauthorized,
@@ -143,6 +181,8 @@ func (o *BasicAuthorizer) GetGinHandler(role string) gin.HandlerFunc {
 		}
 
 		if gotRole {
+			c.Set(PrincipalKey, userName)
+			c.Set(RolesKey, userInfo.Roles)
 			log.Debugw("user authenticated", "user", userName, "role", role)
 		} else {
 			c.Writer.Header().Set("WWW-Authenticate", "Basic realm=veraison")
@@ -151,3 +191,174 @@ func (o *BasicAuthorizer) GetGinHandler(role string) gin.HandlerFunc {
 		}
 	}
 }
+
+func (o *BasicAuthorizer) GetPermissionHandler(action string, getResource ResourceGetter) gin.HandlerFunc {
+	return o.permissionHandler(o.GetGinHandler(NoRole), action, getResource)
+}
+
+func (o *BasicAuthorizer) getUser(name string) (*basicAuthUser, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	user, ok := o.users[name]
+
+	return user, ok
+}
+
+// loadUsersFile (re-)loads the users from the users file, merging them with
+// the users specified in the config. The current users are only replaced if
+// the whole file could be loaded successfully.
+func (o *BasicAuthorizer) loadUsersFile() error {
+	fileUsers, err := readUsersFile(o.usersFile)
+	if err != nil {
+		return fmt.Errorf("could not load users from %s: %w", o.usersFile, err)
+	}
+
+	users := make(map[string]*basicAuthUser, len(o.configUsers)+len(fileUsers))
+	for name, user := range o.configUsers {
+		users[name] = user
+	}
+
+	for name, user := range fileUsers {
+		if _, ok := users[name]; ok {
+			return fmt.Errorf("could not load users from %s: user %q is also specified in the config",
+				o.usersFile, name)
+		}
+		users[name] = user
+	}
+
+	o.mu.Lock()
+	o.users = users
+	o.mu.Unlock()
+
+	o.logger.Infow("loaded users file", "path", o.usersFile, "users", len(fileUsers))
+
+	return nil
+}
+
+// watchUsersFile starts watching the users file, reloading users whenever it
+// changes. The file's directory, rather than the file itself, is watched, so
+// that changes are still picked up if the file is replaced (as is done by
+// many editors). Kubernetes updates mounted secrets and config maps by
+// atomically swapping a "..data" symlink in the directory to point to a new
+// directory, so the file itself (which is a symlink via "..data") is never
+// written; the users are therefore also reloaded whenever the file the users
+// file resolves to changes.
+func (o *BasicAuthorizer) watchUsersFile() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	usersFile := filepath.Clean(o.usersFile)
+
+	if err := watcher.Add(filepath.Dir(usersFile)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("could not watch users file: %w", err)
+	}
+
+	o.watcher = watcher
+
+	// the file has just been loaded, so it must resolve.
+	realPath, _ := filepath.EvalSymlinks(usersFile)
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				written := filepath.Clean(event.Name) == usersFile &&
+					event.Has(fsnotify.Write|fsnotify.Create)
+
+				// The file may not resolve while it is being
+				// replaced; it will once the replacement is
+				// complete, resulting in another event.
+				newRealPath, err := filepath.EvalSymlinks(usersFile)
+				if err != nil {
+					continue
+				}
+
+				swapped := newRealPath != realPath
+				realPath = newRealPath
+
+				if !written && !swapped {
+					continue
+				}
+
+				if err := o.loadUsersFile(); err != nil {
+					o.logger.Errorf("%v (keeping previously loaded users)", err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				o.logger.Errorf("users file watcher: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// readUsersFile reads users from an htpasswd-style file. Each line contains a
+// user name and password hash delimited by a colon, optionally followed by
+// another colon and a comma-separated list of the user's roles, e.g.
+//
+//	user1:$2y$05$...:provisioner
+//	user2:$2y$05$...:manager,provisioner
+//
+// Empty lines and lines starting with '#' are ignored.
+func readUsersFile(path string) (map[string]*basicAuthUser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	users := make(map[string]*basicAuthUser)
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return nil, fmt.Errorf("line %d: expected <user>:<hash>[:<roles>]", lineNo)
+		}
+
+		name := fields[0]
+		if _, ok := users[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate user %q", lineNo, name)
+		}
+
+		if err := checkPasswordHash(fields[1]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid password for user %q: %w",
+				lineNo, name, err)
+		}
+
+		user := basicAuthUser{Password: fields[1], Roles: make([]string, 0)}
+
+		if len(fields) == 3 {
+			for _, role := range strings.Split(fields[2], ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					user.Roles = append(user.Roles, role)
+				}
+			}
+		}
+
+		users[name] = &user
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+const (
+	// bcrypt hash of "Passw0rd!"
+	testPasswordHash = "$2b$05$XgVBveh6QPrRHXI.8S/J9uobBR7Wv9z4CL8yACHEmKIQmYSSyKAqC"
+
+	// reloads are asynchronous, so tests wait for them for up to
+	// reloadTimeout
+	reloadTimeout = 5 * time.Second
+	reloadTick    = 20 * time.Millisecond
+)
+
+func writeUsersFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func newTestBasicAuthorizer(t *testing.T, settings map[string]any) *BasicAuthorizer {
+	v := viper.New()
+	v.Set("backend", "basic")
+	for key, val := range settings {
+		v.Set(key, val)
+	}
+
+	authorizer, err := NewAuthorizer(v, log.Named("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() { authorizer.Close() })
+
+	return authorizer.(*BasicAuthorizer)
+}
+
+func doBasicRequest(router *gin.Engine, user, password string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.SetBasicAuth(user, password)
+
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func hasUser(authorizer *BasicAuthorizer, name string) func() bool {
+	return func() bool {
+		_, ok := authorizer.getUser(name)
+		return ok
+	}
+}
+
+func Test_readUsersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	writeUsersFile(t, path, `
+# <user>:<hash>[:<roles>]
+user1:`+testPasswordHash+`
+
+  user2:`+testPasswordHash+`:provisioner
+user3:`+testPasswordHash+`:manager, provisioner,
+`)
+
+	users, err := readUsersFile(path)
+	require.NoError(t, err)
+
+	require.Len(t, users, 3)
+	assert.Equal(t, testPasswordHash, users["user1"].Password)
+	assert.Equal(t, []string{}, users["user1"].Roles)
+	assert.Equal(t, []string{"provisioner"}, users["user2"].Roles)
+	assert.Equal(t, []string{"manager", "provisioner"}, users["user3"].Roles)
+}
+
+func Test_readUsersFile_bad(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"no hash", "user1\n", "line 1: expected <user>:<hash>[:<roles>]"},
+		{"too many fields", "user1:" + testPasswordHash + ":manager:extra\n", "line 1: expected"},
+		{"no user", ":" + testPasswordHash + "\n", "line 1: expected"},
+		{"bad hash", "# comment\nuser1:Passw0rd!\n", `line 2: invalid password for user "user1"`},
+		{
+			"duplicate user",
+			"user1:" + testPasswordHash + "\nuser1:" + testPasswordHash + "\n",
+			`line 2: duplicate user "user1"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "users")
+			writeUsersFile(t, path, tc.content)
+
+			_, err := readUsersFile(path)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+
+	_, err := readUsersFile(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBasicAuthorizer_GetGinHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	writeUsersFile(t, path, "file-user:"+newTestArgon2Hash("argon2id", "s3cr3t")+":provisioner\n")
+
+	authorizer := newTestBasicAuthorizer(t, map[string]any{
+		"users": map[string]any{
+			"config-user": map[string]any{
+				"password": testPasswordHash,
+				"roles":    "manager",
+			},
+		},
+		"users-file": path,
+	})
+
+	router := newTestAuthRouter(authorizer, "provisioner")
+
+	w := doBasicRequest(router, "file-user", "s3cr3t")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"principal": "file-user", "tenant": "", "roles": "provisioner"}`,
+		w.Body.String())
+
+	w = doBasicRequest(router, "file-user", "Passw0rd!")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+	w = doBasicRequest(router, "config-user", "Passw0rd!")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "API unauthorized for user")
+
+	w = doBasicRequest(router, "unknown", "Passw0rd!")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "wrong username or password")
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+	assert.Equal(t, "Basic realm=veraison", w.Header().Get("WWW-Authenticate"))
+}
+
+func TestBasicAuthorizer_Init_bad_users_file(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	writeUsersFile(t, path, "user1:"+testPasswordHash+"\n")
+
+	v := viper.New()
+	v.Set("backend", "basic")
+	v.Set("users", map[string]any{
+		"user1": map[string]any{"password": testPasswordHash},
+	})
+	v.Set("users-file", path)
+
+	_, err := NewAuthorizer(v, log.Named("test"))
+	assert.ErrorContains(t, err, `user "user1" is also specified in the config`)
+
+	v.Set("users", map[string]any{})
+	v.Set("users-file", filepath.Join(t.TempDir(), "missing"))
+
+	_, err = NewAuthorizer(v, log.Named("test"))
+	assert.ErrorContains(t, err, "could not load users")
+}
+
+func TestBasicAuthorizer_reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users")
+	writeUsersFile(t, path, "user1:"+testPasswordHash+"\n")
+
+	authorizer := newTestBasicAuthorizer(t, map[string]any{"users-file": path})
+	require.True(t, hasUser(authorizer, "user1")())
+
+	// written in place
+	writeUsersFile(t, path, "user1:"+testPasswordHash+"\nuser2:"+testPasswordHash+"\n")
+	require.Eventually(t, hasUser(authorizer, "user2"), reloadTimeout, reloadTick)
+
+	// replaced, as done by many editors
+	tmpPath := path + ".tmp"
+	writeUsersFile(t, tmpPath, "user3:"+testPasswordHash+"\n")
+	require.NoError(t, os.Rename(tmpPath, path))
+	require.Eventually(t, hasUser(authorizer, "user3"), reloadTimeout, reloadTick)
+	assert.False(t, hasUser(authorizer, "user1")())
+
+	// users are kept if the changed file cannot be loaded (the file is
+	// replaced, as writing it in place may be observed as an empty, and
+	// therefore valid, file)
+	writeUsersFile(t, tmpPath, "user4:Passw0rd!\n")
+	require.NoError(t, os.Rename(tmpPath, path))
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, hasUser(authorizer, "user3")())
+	assert.False(t, hasUser(authorizer, "user4")())
+}
+
+// TestBasicAuthorizer_reload_kubernetes replicates the way Kubernetes updates
+// mounted secrets: the users file is a symlink to "..data/users", and
+// "..data" is a symlink to a timestamped directory, which is atomically
+// swapped for a new directory on update.
+func TestBasicAuthorizer_reload_kubernetes(t *testing.T) {
+	mount := t.TempDir()
+
+	writeVersion := func(name, content string) {
+		dir := filepath.Join(mount, name)
+		require.NoError(t, os.Mkdir(dir, 0700))
+		writeUsersFile(t, filepath.Join(dir, "users"), content)
+	}
+
+	swapData := func(name string) {
+		tmpLink := filepath.Join(mount, "..data_tmp")
+		require.NoError(t, os.Symlink(name, tmpLink))
+		require.NoError(t, os.Rename(tmpLink, filepath.Join(mount, "..data")))
+	}
+
+	writeVersion("..2026_10_18_12_00_00.1", "user1:"+testPasswordHash+"\n")
+	require.NoError(t, os.Symlink("..2026_10_18_12_00_00.1", filepath.Join(mount, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "users"), filepath.Join(mount, "users")))
+
+	authorizer := newTestBasicAuthorizer(t, map[string]any{
+		"users-file": filepath.Join(mount, "users"),
+	})
+	require.True(t, hasUser(authorizer, "user1")())
+
+	writeVersion("..2026_10_18_12_05_00.2", "user2:"+testPasswordHash+"\n")
+	swapData("..2026_10_18_12_05_00.2")
+	require.NoError(t, os.RemoveAll(filepath.Join(mount, "..2026_10_18_12_00_00.1")))
+
+	require.Eventually(t, hasUser(authorizer, "user2"), reloadTimeout, reloadTick)
+	assert.False(t, hasUser(authorizer, "user1")())
+
+	writeVersion("..2026_10_18_12_10_00.3", "user3:"+testPasswordHash+"\n")
+	swapData("..2026_10_18_12_10_00.3")
+
+	require.Eventually(t, hasUser(authorizer, "user3"), reloadTimeout, reloadTick)
+}
@@ -4,6 +4,8 @@
 package auth
 
 import (
+	"crypto/tls"
+
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -22,4 +24,32 @@ type IAuthorizer interface {
 	// based on the specified role. This function can be set as gin
 	// middleware by passing it to gin.Engine.Use().
 	GetGinHandler(role string) gin.HandlerFunc
+	// GetPermissionHandler returns a gin.HandlerFunc that authenticates
+	// the request, and checks that the principal is permitted to perform
+	// the specified action on the resource returned by getResource, as
+	// determined by the configured Permissions. If getResource is nil, the
+	// principal must be permitted to perform the action on some resources,
+	// and the handler for the request must check the specific resource
+	// using IsPermitted().
+	GetPermissionHandler(action string, getResource ResourceGetter) gin.HandlerFunc
+}
+
+// ITLSAuthorizer is implemented by the backends that authenticate clients as
+// part of the TLS handshake (i.e. using client certificates).
+type ITLSAuthorizer interface {
+	IAuthorizer
+	// GetTLSConfig returns the config that must be used by the TLS
+	// listener for the backend to be able to authenticate clients.
+	GetTLSConfig() *tls.Config
+}
+
+// GetTLSConfig returns the TLS listener config required by the specified
+// authorizer, or nil if it does not authenticate clients as part of the TLS
+// handshake.
+func GetTLSConfig(a IAuthorizer) *tls.Config {
+	if tlsAuthorizer, ok := a.(ITLSAuthorizer); ok {
+		return tlsAuthorizer.GetTLSConfig()
+	}
+
+	return nil
 }
@@ -28,6 +28,8 @@ type keycloakCfg struct {
 }
 
 type KeycloakAuthorizer struct {
+	permissionEnforcer
+
 	logger *zap.SugaredLogger
 	config ginkeycloak.KeycloakConfig
 }
@@ -80,14 +82,26 @@ func (o *KeycloakAuthorizer) GetGinHandler(role string) gin.HandlerFunc {
 	return ginkeycloak.Auth(o.getAuthCheck([]string{role}), o.config)
 }
 
+func (o *KeycloakAuthorizer) GetPermissionHandler(action string, getResource ResourceGetter) gin.HandlerFunc {
+	return o.permissionHandler(o.GetGinHandler(NoRole), action, getResource)
+}
+
 func (o *KeycloakAuthorizer) getAuthCheck(
 	roles []string,
 ) ginkeycloak.AccessCheckFunction {
 	return func(tc *ginkeycloak.TokenContainer, ctx *gin.Context) bool {
 		ctx.Set("token", *tc.KeyCloakToken)
-		ctx.Set("uid", tc.KeyCloakToken.PreferredUsername)
+		ctx.Set(PrincipalKey, tc.KeyCloakToken.PreferredUsername)
+		ctx.Set(RolesKey, tc.KeyCloakToken.RealmAccess.Roles)
 
-		roleOK := ginkeycloak.RealmCheck(roles)(tc, ctx)
+		if claims, ok := tc.KeyCloakToken.CustomClaims.(map[string]string); ok {
+			ctx.Set(TenantKey, claims["tenant_id"])
+		}
+
+		roleOK := len(roles) == 1 && roles[0] == NoRole
+		if !roleOK {
+			roleOK = ginkeycloak.RealmCheck(roles)(tc, ctx)
+		}
 
 		o.logger.Debugw("auth check", "role", roleOK)
 
@@ -0,0 +1,254 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	"go.uber.org/zap"
+)
+
+type mtlsCfg struct {
+	Backend string           `mapstructure:"backend"`
+	CACert  string           `mapstructure:"ca-cert"`
+	Rules   []map[string]any `mapstructure:"rules"`
+}
+
+// mtlsRule maps client certificates onto roles and a tenant. A certificate
+// matches the rule if it matches all of the rule's patterns that are set; for
+// multi-valued fields (e.g. DNS SANs) it is enough for one of the values to
+// match. Patterns use the syntax of path.Match, e.g. "*.ci.example.com".
+type mtlsRule struct {
+	SubjectCN string   `mapstructure:"subject-cn"`
+	SubjectO  string   `mapstructure:"subject-o"`
+	SubjectOU string   `mapstructure:"subject-ou"`
+	DNSSAN    string   `mapstructure:"dns-san"`
+	URISAN    string   `mapstructure:"uri-san"`
+	EmailSAN  string   `mapstructure:"email-san"`
+	Roles     []string `mapstructure:"roles"`
+	Tenant    string   `mapstructure:"tenant"`
+}
+
+func newMTLSRule(m map[string]any) (*mtlsRule, error) {
+	var rule mtlsRule
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           &rule,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(m); err != nil {
+		return nil, err
+	}
+
+	patterns := rule.patterns()
+	if len(patterns) == 0 {
+		return nil, errors.New("no certificate fields to match specified")
+	}
+
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	return &rule, nil
+}
+
+func (o *mtlsRule) patterns() []string {
+	var ret []string
+
+	for _, pattern := range []string{
+		o.SubjectCN, o.SubjectO, o.SubjectOU, o.DNSSAN, o.URISAN, o.EmailSAN,
+	} {
+		if pattern != "" {
+			ret = append(ret, pattern)
+		}
+	}
+
+	return ret
+}
+
+func (o *mtlsRule) Matches(cert *x509.Certificate) bool {
+	var uris []string
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	return matchAny(o.SubjectCN, []string{cert.Subject.CommonName}) &&
+		matchAny(o.SubjectO, cert.Subject.Organization) &&
+		matchAny(o.SubjectOU, cert.Subject.OrganizationalUnit) &&
+		matchAny(o.DNSSAN, cert.DNSNames) &&
+		matchAny(o.URISAN, uris) &&
+		matchAny(o.EmailSAN, cert.EmailAddresses)
+}
+
+// matchAny returns true if the pattern is unset, or if it matches at least one
+// of the values.
+func matchAny(pattern string, values []string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	for _, val := range values {
+		// the pattern has been validated when the rule was created, so
+		// there is no error to handle.
+		if ok, _ := path.Match(pattern, val); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MTLSAuthorizer authenticates clients using the certificates they present
+// during the TLS handshake. Certificates must chain to the configured CA
+// bundle, and are mapped onto roles and tenants using the configured rules.
+// The first rule matching a certificate applies; certificates not matching
+// any rule are not granted any roles.
+//
+// Since authentication is performed by the TLS layer, this authorizer
+// implements ITLSAuthorizer, and the REST listener must be set up using the
+// tls.Config it provides.
+type MTLSAuthorizer struct {
+	permissionEnforcer
+
+	logger    *zap.SugaredLogger
+	clientCAs *x509.CertPool
+	rules     []*mtlsRule
+}
+
+func (o *MTLSAuthorizer) Init(v *viper.Viper, logger *zap.SugaredLogger) error {
+	if logger == nil {
+		return errors.New("nil logger")
+	}
+	o.logger = logger
+
+	var cfg mtlsCfg
+
+	loader := config.NewLoader(&cfg)
+	if err := loader.LoadFromViper(v); err != nil {
+		return err
+	}
+
+	rawCerts, err := os.ReadFile(cfg.CACert)
+	if err != nil {
+		return fmt.Errorf("could not read CA cert bundle: %w", err)
+	}
+
+	o.clientCAs = x509.NewCertPool()
+	if ok := o.clientCAs.AppendCertsFromPEM(rawCerts); !ok {
+		return fmt.Errorf("no valid certs in %s", cfg.CACert)
+	}
+
+	o.rules = make([]*mtlsRule, len(cfg.Rules))
+	for i, rawRule := range cfg.Rules {
+		rule, err := newMTLSRule(rawRule)
+		if err != nil {
+			return fmt.Errorf("invalid rule %d: %w", i, err)
+		}
+
+		o.rules[i] = rule
+	}
+
+	return nil
+}
+
+func (o *MTLSAuthorizer) Close() error {
+	return nil
+}
+
+// GetTLSConfig returns the config for the TLS listener, requiring clients to
+// present a certificate that chains to the configured CA bundle.
+func (o *MTLSAuthorizer) GetTLSConfig() *tls.Config {
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  o.clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+func (o *MTLSAuthorizer) GetGinHandler(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		o.logger.Debugw("auth mtls", "path", c.Request.URL.Path)
+
+		// Only trust certificates that have been verified during the
+		// handshake; there will be none if the listener was not set
+		// up using GetTLSConfig().
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			ReportProblem(c, http.StatusUnauthorized,
+				"no verified client certificate given")
+			return
+		}
+
+		cert := c.Request.TLS.VerifiedChains[0][0]
+		principal := getCertPrincipal(cert)
+
+		rule := o.findRule(cert)
+		if rule == nil {
+			o.logger.Debugw("no rule matches certificate", "subject", cert.Subject)
+		}
+
+		if role != NoRole && (rule == nil || !hasRole(rule.Roles, role)) {
+			ReportProblem(c, http.StatusForbidden,
+				"API unauthorized for user")
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		if rule != nil {
+			c.Set(TenantKey, rule.Tenant)
+			c.Set(RolesKey, rule.Roles)
+		}
+
+		o.logger.Debugw("user authenticated", "user", principal, "role", role)
+	}
+}
+
+func (o *MTLSAuthorizer) GetPermissionHandler(action string, getResource ResourceGetter) gin.HandlerFunc {
+	return o.permissionHandler(o.GetGinHandler(NoRole), action, getResource)
+}
+
+func (o *MTLSAuthorizer) findRule(cert *x509.Certificate) *mtlsRule {
+	for _, rule := range o.rules {
+		if rule.Matches(cert) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// getCertPrincipal returns the identity of the principal presenting the
+// certificate: its subject common name, if set, or its subject as a whole.
+func getCertPrincipal(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+
+	return cert.Subject.String()
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
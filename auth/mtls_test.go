@@ -0,0 +1,344 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (o *testCA) pemFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: o.cert.Raw})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	return path
+}
+
+// issue returns a client certificate, signed by the CA, based on the specified
+// template.
+func (o *testCA) issue(t *testing.T, template *x509.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, o.cert, &key.PublicKey, o.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+}
+
+func newTestMTLSAuthorizer(t *testing.T, ca *testCA, rules []map[string]any) *MTLSAuthorizer {
+	v := viper.New()
+	v.Set("backend", "mtls")
+	v.Set("ca-cert", ca.pemFile(t))
+	v.Set("rules", rules)
+
+	authorizer, err := NewAuthorizer(v, log.Named("test"))
+	require.NoError(t, err)
+
+	return authorizer.(*MTLSAuthorizer)
+}
+
+func mustParseURL(t *testing.T, text string) *url.URL {
+	u, err := url.Parse(text)
+	require.NoError(t, err)
+
+	return u
+}
+
+func Test_newMTLSRule_bad(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		rule     map[string]any
+		expected string
+	}{
+		{"no fields", map[string]any{"roles": []string{"provisioner"}}, "no certificate fields"},
+		{"bad pattern", map[string]any{"subject-cn": "[a-"}, `invalid pattern "[a-"`},
+		{"unknown field", map[string]any{"subject-c": "UK"}, "subject-c"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newMTLSRule(tc.rule)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func Test_mtlsRule_Matches(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "builder-7.ci.example.com",
+			Organization:       []string{"ACME Corp."},
+			OrganizationalUnit: []string{"Firmware", "CI"},
+		},
+		DNSNames:       []string{"builder-7", "builder-7.ci.example.com"},
+		URIs:           []*url.URL{mustParseURL(t, "spiffe://example.com/ci/builder")},
+		EmailAddresses: []string{"ci@example.com"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		rule     map[string]any
+		expected bool
+	}{
+		{"CN exact", map[string]any{"subject-cn": "builder-7.ci.example.com"}, true},
+		{"CN glob", map[string]any{"subject-cn": "*.ci.example.com"}, true},
+		{"CN glob matches across dots", map[string]any{"subject-cn": "*.example.com"}, true},
+		{"CN mismatch", map[string]any{"subject-cn": "builder-8.ci.example.com"}, false},
+		{"O", map[string]any{"subject-o": "ACME*"}, true},
+		{"O mismatch", map[string]any{"subject-o": "Evil Corp."}, false},
+		{"any OU", map[string]any{"subject-ou": "CI"}, true},
+		{"any DNS SAN", map[string]any{"dns-san": "builder-?"}, true},
+		{"DNS SAN mismatch", map[string]any{"dns-san": "*.prod.example.com"}, false},
+		{"URI SAN", map[string]any{"uri-san": "spiffe://example.com/ci/*"}, true},
+		{"URI SAN glob does not cross slashes", map[string]any{"uri-san": "spiffe://example.com/*"}, false},
+		{"email SAN", map[string]any{"email-san": "*@example.com"}, true},
+		{
+			"all fields match",
+			map[string]any{"subject-o": "ACME Corp.", "subject-ou": "Firmware", "dns-san": "builder-*"},
+			true,
+		},
+		{
+			"one field mismatches",
+			map[string]any{"subject-o": "ACME Corp.", "subject-ou": "Hardware"},
+			false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := newMTLSRule(tc.rule)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, rule.Matches(cert))
+		})
+	}
+
+	// fields absent from the certificate do not match
+	rule, err := newMTLSRule(map[string]any{"uri-san": "*"})
+	require.NoError(t, err)
+	assert.False(t, rule.Matches(&x509.Certificate{Subject: pkix.Name{CommonName: "x"}}))
+}
+
+func TestMTLSAuthorizer_Init_bad(t *testing.T) {
+	ca := newTestCA(t)
+
+	v := viper.New()
+	v.Set("backend", "mtls")
+	v.Set("rules", []map[string]any{})
+	v.Set("ca-cert", filepath.Join(t.TempDir(), "missing.pem"))
+	_, err := NewAuthorizer(v, log.Named("test"))
+	assert.ErrorContains(t, err, "could not read CA cert bundle")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a cert"), 0600))
+	v.Set("ca-cert", notPEM)
+	_, err = NewAuthorizer(v, log.Named("test"))
+	assert.ErrorContains(t, err, "no valid certs")
+
+	v.Set("ca-cert", ca.pemFile(t))
+	v.Set("rules", []map[string]any{
+		{"subject-cn": "ok", "roles": []string{"provisioner"}},
+		{"roles": []string{"manager"}},
+	})
+	_, err = NewAuthorizer(v, log.Named("test"))
+	assert.ErrorContains(t, err, "invalid rule 1")
+}
+
+func TestMTLSAuthorizer_findRule_first_match(t *testing.T) {
+	ca := newTestCA(t)
+	authorizer := newTestMTLSAuthorizer(t, ca, []map[string]any{
+		{"subject-cn": "admin.example.com", "roles": []string{"manager"}, "tenant": "ops"},
+		{"subject-o": "ACME Corp.", "roles": []string{"provisioner"}, "tenant": "acme"},
+		{"subject-cn": "*.example.com", "roles": []string{"attester"}},
+	})
+
+	admin := &x509.Certificate{Subject: pkix.Name{
+		CommonName: "admin.example.com", Organization: []string{"ACME Corp."},
+	}}
+	rule := authorizer.findRule(admin)
+	require.NotNil(t, rule)
+	assert.Equal(t, "ops", rule.Tenant)
+
+	builder := &x509.Certificate{Subject: pkix.Name{
+		CommonName: "builder.example.com", Organization: []string{"ACME Corp."},
+	}}
+	rule = authorizer.findRule(builder)
+	require.NotNil(t, rule)
+	assert.Equal(t, []string{"provisioner"}, rule.Roles)
+
+	device := &x509.Certificate{Subject: pkix.Name{CommonName: "device.example.com"}}
+	rule = authorizer.findRule(device)
+	require.NotNil(t, rule)
+	assert.Equal(t, []string{"attester"}, rule.Roles)
+
+	assert.Nil(t, authorizer.findRule(&x509.Certificate{Subject: pkix.Name{CommonName: "other"}}))
+}
+
+func TestMTLSAuthorizer_GetGinHandler(t *testing.T) {
+	ca := newTestCA(t)
+	authorizer := newTestMTLSAuthorizer(t, ca, []map[string]any{
+		{"subject-o": "ACME Corp.", "roles": []string{"provisioner"}, "tenant": "acme"},
+	})
+
+	acme := ca.issue(t, &x509.Certificate{Subject: pkix.Name{
+		CommonName: "builder", Organization: []string{"ACME Corp."},
+	}})
+	other := ca.issue(t, &x509.Certificate{Subject: pkix.Name{
+		Organization: []string{"Other Corp."},
+	}})
+
+	for _, tc := range []struct {
+		name     string
+		role     string
+		state    *tls.ConnectionState
+		status   int
+		expected string
+	}{
+		{
+			name:   "no TLS",
+			role:   NoRole,
+			state:  nil,
+			status: http.StatusUnauthorized,
+		},
+		{
+			// e.g. the listener was not set up with GetTLSConfig(),
+			// so the peer certificate was not verified
+			name:   "unverified certificate",
+			role:   NoRole,
+			state:  &tls.ConnectionState{PeerCertificates: []*x509.Certificate{acme.Leaf}},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:     "matching rule",
+			role:     "provisioner",
+			state:    &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{acme.Leaf, ca.cert}}},
+			status:   http.StatusOK,
+			expected: `{"principal": "builder", "tenant": "acme", "roles": "provisioner"}`,
+		},
+		{
+			name:   "missing role",
+			role:   "manager",
+			state:  &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{acme.Leaf, ca.cert}}},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "no matching rule",
+			role:   "provisioner",
+			state:  &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{other.Leaf, ca.cert}}},
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "no matching rule, no role required",
+			role:     NoRole,
+			state:    &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{other.Leaf, ca.cert}}},
+			status:   http.StatusOK,
+			expected: `{"principal": "O=Other Corp.", "tenant": "", "roles": ""}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newTestAuthRouter(authorizer, tc.role)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.TLS = tc.state
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code, w.Body.String())
+			if tc.expected != "" {
+				assert.JSONEq(t, tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMTLSAuthorizer_GetTLSConfig(t *testing.T) {
+	ca := newTestCA(t)
+	authorizer := newTestMTLSAuthorizer(t, ca, []map[string]any{
+		{"subject-cn": "builder", "roles": []string{"provisioner"}},
+	})
+
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewUnstartedServer(newTestAuthRouter(authorizer, "provisioner"))
+	server.TLS = authorizer.GetTLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		client := server.Client()
+		transport := client.Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = certs
+		client.Transport = transport
+
+		return client
+	}
+
+	trusted := ca.issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "builder"}})
+	resp, err := newClient(trusted).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// certificates that do not chain to the CA bundle are rejected during
+	// the handshake
+	untrusted := newTestCA(t).issue(t, &x509.Certificate{Subject: pkix.Name{CommonName: "builder"}})
+	_, err = newClient(untrusted).Get(server.URL)
+	assert.Error(t, err)
+
+	_, err = newClient().Get(server.URL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,379 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	"go.uber.org/zap"
+)
+
+type oidcCfg struct {
+	Backend        string `mapstructure:"backend"`
+	Issuer         string `mapstructure:"issuer"`
+	Audience       string `mapstructure:"audience" config:"zerodefault"`
+	JWKSURL        string `mapstructure:"jwks-url" config:"zerodefault"`
+	JWKSFile       string `mapstructure:"jwks-file" config:"zerodefault"`
+	JWKSRefresh    string `mapstructure:"jwks-refresh"`
+	CACert         string `mapstructure:"ca-cert" config:"zerodefault"`
+	PrincipalClaim string `mapstructure:"principal-claim"`
+	RolesClaim     string `mapstructure:"roles-claim"`
+	TenantClaim    string `mapstructure:"tenant-claim"`
+	ClockSkew      string `mapstructure:"clock-skew"`
+}
+
+func (o oidcCfg) Validate() error {
+	if o.Issuer == "" {
+		return errors.New(`"issuer" must be specified`)
+	}
+
+	if (o.JWKSURL == "") == (o.JWKSFile == "") {
+		return errors.New(`exactly one of "jwks-url" and "jwks-file" must be specified`)
+	}
+
+	if o.CACert != "" && o.JWKSURL == "" {
+		return errors.New(`"ca-cert" may only be specified with "jwks-url"`)
+	}
+
+	for _, claim := range []string{o.PrincipalClaim, o.RolesClaim, o.TenantClaim} {
+		if claim == "" || strings.HasPrefix(claim, ".") || strings.HasSuffix(claim, ".") {
+			return fmt.Errorf("invalid claim path %q", claim)
+		}
+	}
+
+	return nil
+}
+
+// OIDCAuthorizer authenticates requests using JWT Bearer tokens issued by a
+// generic OpenID Connect provider. Tokens are verified against a JWKS that is
+// either fetched from the provider or read from a local file (for deployments
+// that are unable to reach the provider). The claims containing the
+// principal, its roles and its tenant are configurable.
+type OIDCAuthorizer struct {
+	permissionEnforcer
+
+	logger *zap.SugaredLogger
+
+	issuer         string
+	audience       string
+	principalClaim string
+	rolesClaim     string
+	tenantClaim    string
+	clockSkew      time.Duration
+
+	jwksURL     string
+	jwksRefresh time.Duration
+	httpClient  *http.Client
+
+	mu         sync.Mutex
+	keySet     jwk.Set
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+func (o *OIDCAuthorizer) Init(v *viper.Viper, logger *zap.SugaredLogger) error {
+	if logger == nil {
+		return errors.New("nil logger")
+	}
+	o.logger = logger
+
+	cfg := oidcCfg{
+		JWKSRefresh:    "1h",
+		PrincipalClaim: "sub",
+		RolesClaim:     "roles",
+		TenantClaim:    "tenant_id",
+		ClockSkew:      "30s",
+	}
+
+	loader := config.NewLoader(&cfg)
+	if err := loader.LoadFromViper(v); err != nil {
+		return err
+	}
+
+	jwksRefresh, err := time.ParseDuration(cfg.JWKSRefresh)
+	if err != nil {
+		return fmt.Errorf("invalid jwks-refresh: %w", err)
+	}
+
+	clockSkew, err := time.ParseDuration(cfg.ClockSkew)
+	if err != nil {
+		return fmt.Errorf("invalid clock-skew: %w", err)
+	}
+
+	o.issuer = cfg.Issuer
+	o.audience = cfg.Audience
+	o.principalClaim = cfg.PrincipalClaim
+	o.rolesClaim = cfg.RolesClaim
+	o.tenantClaim = cfg.TenantClaim
+	o.clockSkew = clockSkew
+
+	if cfg.JWKSFile != "" {
+		keySet, err := jwk.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return fmt.Errorf("could not read JWKS from %s: %w", cfg.JWKSFile, err)
+		}
+
+		o.keySet = keySet
+
+		return nil
+	}
+
+	o.jwksURL = cfg.JWKSURL
+	o.jwksRefresh = jwksRefresh
+	o.httpClient = http.DefaultClient
+
+	if cfg.CACert != "" {
+		httpClient, err := getHTTPClient(cfg.CACert)
+		if err != nil {
+			return err
+		}
+
+		o.httpClient = httpClient
+	}
+
+	keySet, err := o.fetchKeySet()
+	if err != nil {
+		return err
+	}
+
+	o.keySet = keySet
+	o.fetchedAt = time.Now()
+
+	return nil
+}
+
+func (o *OIDCAuthorizer) Close() error {
+	return nil
+}
+
+func (o *OIDCAuthorizer) GetGinHandler(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		o.logger.Debugw("auth oidc", "path", c.Request.URL.Path)
+
+		rawToken, ok := getBearerToken(c.Request)
+		if !ok {
+			c.Writer.Header().Set("WWW-Authenticate", "Bearer realm=veraison")
+			ReportProblem(c, http.StatusUnauthorized,
+				"no Bearer token given")
+			return
+		}
+
+		token, err := o.parseToken(rawToken)
+		if err != nil {
+			o.logger.Debugf("token verification failed: %v", err)
+			c.Writer.Header().Set("WWW-Authenticate",
+				`Bearer realm=veraison, error="invalid_token"`)
+			ReportProblem(c, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		principal, err := getStringClaim(token, o.principalClaim)
+		if err == nil && principal == "" {
+			err = fmt.Errorf("%s is empty", o.principalClaim)
+		}
+		if err != nil {
+			o.logger.Debugf("could not get principal: %v", err)
+			c.Writer.Header().Set("WWW-Authenticate",
+				`Bearer realm=veraison, error="invalid_token"`)
+			ReportProblem(c, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		tenantID, err := getStringClaim(token, o.tenantClaim)
+		if err != nil && !errors.Is(err, errClaimNotFound) {
+			o.logger.Debugf("could not get tenant: %v", err)
+			c.Writer.Header().Set("WWW-Authenticate",
+				`Bearer realm=veraison, error="invalid_token"`)
+			ReportProblem(c, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		roles, err := getRolesClaim(token, o.rolesClaim)
+		if err != nil && !errors.Is(err, errClaimNotFound) {
+			o.logger.Debugf("could not get roles: %v", err)
+		}
+
+		if role != NoRole {
+			if !hasRole(roles, role) {
+				c.Writer.Header().Set("WWW-Authenticate",
+					`Bearer realm=veraison, error="insufficient_scope"`)
+				ReportProblem(c, http.StatusForbidden,
+					"API unauthorized for user")
+				return
+			}
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Set(TenantKey, tenantID)
+		c.Set(RolesKey, roles)
+
+		o.logger.Debugw("user authenticated",
+			"user", principal, "tenant", tenantID, "role", role)
+	}
+}
+
+func (o *OIDCAuthorizer) GetPermissionHandler(action string, getResource ResourceGetter) gin.HandlerFunc {
+	return o.permissionHandler(o.GetGinHandler(NoRole), action, getResource)
+}
+
+func (o *OIDCAuthorizer) parseToken(rawToken string) (jwt.Token, error) {
+	options := []jwt.ParseOption{
+		jwt.WithKeySet(o.getKeySet(),
+			jws.WithInferAlgorithmFromKey(true),
+			jws.WithUseDefault(true),
+		),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(o.issuer),
+		jwt.WithAcceptableSkew(o.clockSkew),
+	}
+
+	if o.audience != "" {
+		options = append(options, jwt.WithAudience(o.audience))
+	}
+
+	return jwt.ParseString(rawToken, options...)
+}
+
+// getKeySet returns the JWKS used to verify tokens. If the JWKS was fetched
+// from a URL and is older than the configured refresh interval, a refresh is
+// started in the background; in the meantime (and should the refresh fail),
+// the previously fetched JWKS continues to be used. The JWKS is never fetched
+// while holding the lock, so that requests are not blocked on the provider.
+func (o *OIDCAuthorizer) getKeySet() jwk.Set {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.jwksURL != "" && !o.refreshing && time.Since(o.fetchedAt) >= o.jwksRefresh {
+		o.refreshing = true
+		go o.refreshKeySet()
+	}
+
+	return o.keySet
+}
+
+// refreshKeySet re-fetches the JWKS from the URL, replacing the current one if
+// successful.
+func (o *OIDCAuthorizer) refreshKeySet() {
+	keySet, err := o.fetchKeySet()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err != nil {
+		o.logger.Warnf("could not refresh JWKS (using previous): %v", err)
+	} else {
+		o.keySet = keySet
+	}
+
+	// Whether or not the refresh succeeded, do not try again until the
+	// next interval, so that an unavailable provider is not hit on every
+	// request.
+	o.fetchedAt = time.Now()
+	o.refreshing = false
+}
+
+func (o *OIDCAuthorizer) fetchKeySet() (jwk.Set, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keySet, err := jwk.Fetch(ctx, o.jwksURL, jwk.WithHTTPClient(o.httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch JWKS from %s: %w", o.jwksURL, err)
+	}
+
+	return keySet, nil
+}
+
+func getBearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+var errClaimNotFound = errors.New("claim not found")
+
+// getClaim returns the value of the claim at the specified path. The path is
+// the name of a top-level claim, optionally followed by the names of nested
+// object members delimited by dots (e.g. "realm_access.roles").
+func getClaim(token jwt.Token, path string) (any, error) {
+	names := strings.Split(path, ".")
+
+	var val any
+	if err := token.Get(names[0], &val); err != nil {
+		return nil, fmt.Errorf("%w: %q", errClaimNotFound, path)
+	}
+
+	for _, name := range names[1:] {
+		obj, ok := val.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errClaimNotFound, path)
+		}
+
+		if val, ok = obj[name]; !ok {
+			return nil, fmt.Errorf("%w: %q", errClaimNotFound, path)
+		}
+	}
+
+	return val, nil
+}
+
+func getStringClaim(token jwt.Token, path string) (string, error) {
+	val, err := getClaim(token, path)
+	if err != nil {
+		return "", err
+	}
+
+	ret, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%s not a string: %v (%T)", path, val, val)
+	}
+
+	return ret, nil
+}
+
+// getRolesClaim returns the roles in the claim at the specified path, which
+// may either be an array of strings, or a single string of space-delimited
+// roles (as is the convention for the "scope" claim).
+func getRolesClaim(token jwt.Token, path string) ([]string, error) {
+	val, err := getClaim(token, path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := val.(type) {
+	case string:
+		return strings.Fields(t), nil
+	case []string:
+		return t, nil
+	case []any:
+		ret := make([]string, 0, len(t))
+		for _, elt := range t {
+			role, ok := elt.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s contains a non-string: %v (%T)",
+					path, elt, elt)
+			}
+			ret = append(ret, role)
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("%s not a string or an array: %v (%T)", path, val, val)
+	}
+}
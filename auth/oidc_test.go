@@ -0,0 +1,425 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+const (
+	testIssuer   = "https://idp.example.com/realms/veraison"
+	testAudience = "veraison"
+)
+
+type testSigner struct {
+	key jwk.Key
+	set jwk.Set
+}
+
+func newTestSigner(t *testing.T, kid string) *testSigner {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	key, err := jwk.Import(raw)
+	require.NoError(t, err)
+	require.NoError(t, key.Set(jwk.KeyIDKey, kid))
+
+	pub, err := jwk.PublicKeyOf(key)
+	require.NoError(t, err)
+
+	set := jwk.NewSet()
+	require.NoError(t, set.AddKey(pub))
+
+	return &testSigner{key: key, set: set}
+}
+
+func (o *testSigner) jwksBytes(t *testing.T) []byte {
+	data, err := json.Marshal(o.set)
+	require.NoError(t, err)
+
+	return data
+}
+
+func (o *testSigner) jwksFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, o.jwksBytes(t), 0600))
+
+	return path
+}
+
+// sign returns a token with valid standard claims and the specified private
+// claims; edit may be used to modify the token before it is signed.
+func (o *testSigner) sign(t *testing.T, claims map[string]any, edit func(*jwt.Builder)) string {
+	builder := jwt.NewBuilder().
+		Issuer(testIssuer).
+		Audience([]string{testAudience}).
+		Subject("alice").
+		IssuedAt(time.Now()).
+		Expiration(time.Now().Add(time.Hour))
+
+	for name, val := range claims {
+		builder = builder.Claim(name, val)
+	}
+
+	if edit != nil {
+		edit(builder)
+	}
+
+	token, err := builder.Build()
+	require.NoError(t, err)
+
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.ES256(), o.key))
+	require.NoError(t, err)
+
+	return string(signed)
+}
+
+func newTestOIDCAuthorizer(t *testing.T, settings map[string]any) IAuthorizer {
+	v := viper.New()
+	v.Set("backend", "oidc")
+	v.Set("issuer", testIssuer)
+	for key, val := range settings {
+		v.Set(key, val)
+	}
+
+	authorizer, err := NewAuthorizer(v, log.Named("test"))
+	require.NoError(t, err)
+
+	return authorizer
+}
+
+// newTestAuthRouter returns a router that authenticates requests using the
+// specified authorizer, responding with the principal, tenant and roles it
+// established.
+func newTestAuthRouter(authorizer IAuthorizer, role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(authorizer.GetGinHandler(role))
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"principal": GetPrincipal(c),
+			"tenant":    GetTenant(c),
+			"roles":     strings.Join(GetRoles(c), ","),
+		})
+	})
+
+	return router
+}
+
+func doBearerRequest(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestOIDCAuthorizer_Init_bad_config(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+	jwksFile := signer.jwksFile(t)
+
+	for _, tc := range []struct {
+		name     string
+		settings map[string]any
+		expected string
+	}{
+		{
+			"no JWKS",
+			map[string]any{},
+			`exactly one of "jwks-url" and "jwks-file" must be specified`,
+		},
+		{
+			"both JWKS",
+			map[string]any{"jwks-file": jwksFile, "jwks-url": "https://idp.example.com"},
+			`exactly one of "jwks-url" and "jwks-file" must be specified`,
+		},
+		{
+			"ca-cert with file",
+			map[string]any{"jwks-file": jwksFile, "ca-cert": "ca.pem"},
+			`"ca-cert" may only be specified with "jwks-url"`,
+		},
+		{
+			"bad claim path",
+			map[string]any{"jwks-file": jwksFile, "roles-claim": "realm_access."},
+			`invalid claim path "realm_access."`,
+		},
+		{
+			"missing file",
+			map[string]any{"jwks-file": filepath.Join(t.TempDir(), "missing.json")},
+			"could not read JWKS",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("backend", "oidc")
+			v.Set("issuer", testIssuer)
+			for key, val := range tc.settings {
+				v.Set(key, val)
+			}
+
+			_, err := NewAuthorizer(v, log.Named("test"))
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestOIDCAuthorizer_token_validation(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+	other := newTestSigner(t, "key-1")
+
+	authorizer := newTestOIDCAuthorizer(t, map[string]any{
+		"jwks-file": signer.jwksFile(t),
+		"audience":  testAudience,
+	})
+	defer authorizer.Close()
+
+	router := newTestAuthRouter(authorizer, NoRole)
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{
+			"valid",
+			signer.sign(t, nil, nil),
+			http.StatusOK,
+		},
+		{
+			"no token",
+			"",
+			http.StatusUnauthorized,
+		},
+		{
+			"malformed",
+			"not-a-jwt",
+			http.StatusUnauthorized,
+		},
+		{
+			"wrong issuer",
+			signer.sign(t, nil, func(b *jwt.Builder) { b.Issuer("https://evil.example.com") }),
+			http.StatusUnauthorized,
+		},
+		{
+			"wrong audience",
+			signer.sign(t, nil, func(b *jwt.Builder) { b.Audience([]string{"other"}) }),
+			http.StatusUnauthorized,
+		},
+		{
+			"expired",
+			signer.sign(t, nil, func(b *jwt.Builder) {
+				b.Expiration(time.Now().Add(-time.Hour))
+			}),
+			http.StatusUnauthorized,
+		},
+		{
+			"expired within skew",
+			signer.sign(t, nil, func(b *jwt.Builder) {
+				b.Expiration(time.Now().Add(-10 * time.Second))
+			}),
+			http.StatusOK,
+		},
+		{
+			"not yet valid",
+			signer.sign(t, nil, func(b *jwt.Builder) {
+				b.NotBefore(time.Now().Add(time.Hour))
+			}),
+			http.StatusUnauthorized,
+		},
+		{
+			"unknown key",
+			other.sign(t, nil, nil),
+			http.StatusUnauthorized,
+		},
+		{
+			"no principal",
+			signer.sign(t, nil, func(b *jwt.Builder) { b.Subject("") }),
+			http.StatusUnauthorized,
+		},
+		{
+			"non-string tenant",
+			signer.sign(t, map[string]any{"tenant_id": 7}, nil),
+			http.StatusUnauthorized,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doBearerRequest(router, tc.token)
+
+			assert.Equal(t, tc.status, w.Code, w.Body.String())
+			if tc.status == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer realm=veraison")
+			}
+		})
+	}
+}
+
+func TestOIDCAuthorizer_claims(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+
+	authorizer := newTestOIDCAuthorizer(t, map[string]any{
+		"jwks-file":       signer.jwksFile(t),
+		"principal-claim": "preferred_username",
+		"roles-claim":     "realm_access.roles",
+		"tenant-claim":    "org.veraison.tenant",
+	})
+	defer authorizer.Close()
+
+	router := newTestAuthRouter(authorizer, NoRole)
+
+	token := signer.sign(t, map[string]any{
+		"preferred_username": "alice@example.com",
+		"realm_access": map[string]any{
+			"roles": []string{"provisioner", "manager"},
+		},
+		"org": map[string]any{
+			"veraison": map[string]any{"tenant": "acme"},
+		},
+	}, nil)
+
+	w := doBearerRequest(router, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t,
+		`{"principal": "alice@example.com", "tenant": "acme", "roles": "provisioner,manager"}`,
+		w.Body.String())
+
+	// the tenant and roles are optional
+	token = signer.sign(t, map[string]any{"preferred_username": "bob"}, nil)
+
+	w = doBearerRequest(router, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"principal": "bob", "tenant": "", "roles": ""}`, w.Body.String())
+
+	// a path through a non-object does not resolve
+	token = signer.sign(t, map[string]any{
+		"preferred_username": "carol",
+		"realm_access":       "provisioner",
+	}, nil)
+
+	w = doBearerRequest(router, token)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"principal": "carol", "tenant": "", "roles": ""}`, w.Body.String())
+}
+
+func TestOIDCAuthorizer_scope_roles(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+
+	authorizer := newTestOIDCAuthorizer(t, map[string]any{
+		"jwks-file":   signer.jwksFile(t),
+		"roles-claim": "scope",
+	})
+	defer authorizer.Close()
+
+	router := newTestAuthRouter(authorizer, "provisioner")
+
+	w := doBearerRequest(router, signer.sign(t, map[string]any{"scope": "openid provisioner"}, nil))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doBearerRequest(router, signer.sign(t, map[string]any{"scope": "openid manager"}, nil))
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+}
+
+func TestOIDCAuthorizer_jwks_url_refresh(t *testing.T) {
+	first := newTestSigner(t, "key-1")
+	second := newTestSigner(t, "key-2")
+
+	var jwks atomic.Value
+	jwks.Store(first.jwksBytes(t))
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(jwks.Load().([]byte))
+	}))
+	defer server.Close()
+
+	authorizer := newTestOIDCAuthorizer(t, map[string]any{
+		"jwks-url":     server.URL,
+		"jwks-refresh": "50ms",
+	})
+	defer authorizer.Close()
+
+	assert.Equal(t, int32(1), fetches.Load())
+
+	router := newTestAuthRouter(authorizer, NoRole)
+
+	w := doBearerRequest(router, first.sign(t, nil, nil))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doBearerRequest(router, second.sign(t, nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+
+	// the provider rotates its key; once the refresh interval has elapsed,
+	// the new JWKS is fetched in the background
+	jwks.Store(second.jwksBytes(t))
+
+	require.Eventually(t, func() bool {
+		w := doBearerRequest(router, second.sign(t, nil, nil))
+		return w.Code == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	w = doBearerRequest(router, first.sign(t, nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, w.Body.String())
+}
+
+func TestOIDCAuthorizer_jwks_url_refresh_failure(t *testing.T) {
+	signer := newTestSigner(t, "key-1")
+
+	var fail atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(signer.jwksBytes(t))
+	}))
+	defer server.Close()
+
+	authorizer := newTestOIDCAuthorizer(t, map[string]any{
+		"jwks-url":     server.URL,
+		"jwks-refresh": "50ms",
+	})
+	defer authorizer.Close()
+
+	fail.Store(true)
+	router := newTestAuthRouter(authorizer, NoRole)
+
+	// the previous JWKS continues to be used if the refresh fails
+	require.Eventually(t, func() bool {
+		w := doBearerRequest(router, signer.sign(t, nil, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		return fetches.Load() > 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	w := doBearerRequest(router, signer.sign(t, nil, nil))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
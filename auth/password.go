@@ -0,0 +1,130 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errPasswordMismatch = errors.New("password does not match")
+
+// dummyHash is compared against when authenticating a user that does not
+// exist, so that the time taken to reject the request does not reveal which
+// user names are valid. It is the bcrypt hash of a random string.
+const dummyHash = "$2a$10$rtZRo1DBsY7Ptc.fzX5e3uGk4h06quRjbFDdHVjIvFA98Jztit5DW"
+
+// checkPasswordHash returns an error if hash is not a password hash in one of
+// the supported formats: bcrypt ("$2a$", "$2b$" or "$2y$" prefixes), or
+// argon2 in the PHC string format ("$argon2id$" or "$argon2i$" prefixes).
+func checkPasswordHash(hash string) error {
+	switch {
+	case isBcryptHash(hash):
+		_, err := bcrypt.Cost([]byte(hash))
+		return err
+	case isArgon2Hash(hash):
+		_, err := parseArgon2Hash(hash)
+		return err
+	default:
+		return errors.New("not a bcrypt or argon2 hash")
+	}
+}
+
+// comparePassword returns nil if the password matches the hash, and an error
+// otherwise. The comparison is performed in constant time.
+func comparePassword(hash, password string) error {
+	switch {
+	case isBcryptHash(hash):
+		// bcrypt compares the hashes in constant time.
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	case isArgon2Hash(hash):
+		params, err := parseArgon2Hash(hash)
+		if err != nil {
+			return err
+		}
+
+		var key []byte
+		if params.variant == "argon2id" {
+			key = argon2.IDKey([]byte(password), params.salt, params.time,
+				params.memory, params.threads, uint32(len(params.key)))
+		} else {
+			key = argon2.Key([]byte(password), params.salt, params.time,
+				params.memory, params.threads, uint32(len(params.key)))
+		}
+
+		if subtle.ConstantTimeCompare(key, params.key) != 1 {
+			return errPasswordMismatch
+		}
+
+		return nil
+	default:
+		return errors.New("not a bcrypt or argon2 hash")
+	}
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
+
+func isArgon2Hash(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$") || strings.HasPrefix(hash, "$argon2i$")
+}
+
+type argon2Params struct {
+	variant string
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// parseArgon2Hash parses an argon2 hash in the PHC string format, e.g.
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<base64 salt>$<base64 key>
+func parseArgon2Hash(hash string) (*argon2Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, errors.New("invalid argon2 hash: unexpected number of fields")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("invalid argon2 hash version: %w", err)
+	}
+
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version: %d", version)
+	}
+
+	params := argon2Params{variant: parts[1]}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d",
+		&params.memory, &params.time, &params.threads); err != nil {
+		return nil, fmt.Errorf("invalid argon2 hash parameters: %w", err)
+	}
+
+	var err error
+
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2 hash salt: %w", err)
+	}
+
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("invalid argon2 hash key: %w", err)
+	}
+
+	if len(params.key) == 0 {
+		return nil, errors.New("invalid argon2 hash: empty key")
+	}
+
+	return &params, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestBcryptHash(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	return string(hash)
+}
+
+// newTestArgon2Hash returns the PHC string of the argon2 hash of the password,
+// using the specified variant ("argon2id" or "argon2i").
+func newTestArgon2Hash(variant, password string) string {
+	salt := []byte("0123456789abcdef")
+
+	var key []byte
+	if variant == "argon2id" {
+		key = argon2.IDKey([]byte(password), salt, 1, 64, 1, 32)
+	} else {
+		key = argon2.Key([]byte(password), salt, 1, 64, 1, 32)
+	}
+
+	return fmt.Sprintf("$%s$v=%d$m=64,t=1,p=1$%s$%s", variant, argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+func Test_comparePassword(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		hash string
+	}{
+		{"bcrypt", newTestBcryptHash(t, "Passw0rd!")},
+		// hash generated by mkpasswd, which uses the "$2b$" prefix
+		{"bcrypt 2b", "$2b$05$XgVBveh6QPrRHXI.8S/J9uobBR7Wv9z4CL8yACHEmKIQmYSSyKAqC"},
+		{"argon2id", newTestArgon2Hash("argon2id", "Passw0rd!")},
+		{"argon2i", newTestArgon2Hash("argon2i", "Passw0rd!")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, checkPasswordHash(tc.hash))
+
+			assert.NoError(t, comparePassword(tc.hash, "Passw0rd!"))
+			assert.Error(t, comparePassword(tc.hash, "passw0rd!"))
+			assert.Error(t, comparePassword(tc.hash, ""))
+		})
+	}
+
+	assert.ErrorIs(t,
+		comparePassword(newTestArgon2Hash("argon2id", "Passw0rd!"), "wrong"),
+		errPasswordMismatch)
+
+	// the variant is part of the hash, so hashes of one variant cannot be
+	// passed off as the other
+	idHash := newTestArgon2Hash("argon2id", "Passw0rd!")
+	assert.Error(t, comparePassword("$argon2i"+idHash[len("$argon2id"):], "Passw0rd!"))
+
+	// the dummy hash used for unknown users is valid
+	assert.NoError(t, checkPasswordHash(dummyHash))
+}
+
+func Test_checkPasswordHash_bad(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+	key := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+	for _, tc := range []struct {
+		name     string
+		hash     string
+		expected string
+	}{
+		{"plain text", "Passw0rd!", "not a bcrypt or argon2 hash"},
+		{"unsupported scheme", "$6$salt$hash", "not a bcrypt or argon2 hash"},
+		{"truncated bcrypt", "$2a$05$XgVBveh6QPrRHXI", ""},
+		{"bad bcrypt cost", "$2a$99$XgVBveh6QPrRHXI.8S/J9uobBR7Wv9z4CL8yACHEmKIQmYSSyKAqC", ""},
+		{"argon2 missing fields", "$argon2id$v=19$m=64,t=1,p=1$" + salt, "unexpected number of fields"},
+		{"argon2 bad version", "$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key, "unsupported argon2 version"},
+		{"argon2 no version", "$argon2id$m=64$t=1,p=1$" + salt + "$" + key, "invalid argon2 hash version"},
+		{"argon2 bad params", "$argon2id$v=19$m=64$" + salt + "$" + key, "invalid argon2 hash parameters"},
+		{"argon2 bad salt", "$argon2id$v=19$m=64,t=1,p=1$!!!$" + key, "invalid argon2 hash salt"},
+		{"argon2 bad key", "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$!!!", "invalid argon2 hash key"},
+		{"argon2 empty key", "$argon2id$v=19$m=64,t=1,p=1$" + salt + "$", "empty key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPasswordHash(tc.hash)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tc.expected)
+
+			// invalid hashes never match
+			assert.Error(t, comparePassword(tc.hash, "Passw0rd!"))
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Actions that may be performed on Veraison resources. Actions are of the form
+// "<resource type>:<verb>".
+const (
+	ProvisionEndorsementsAction = "endorsements:provision"
+	ViewEndorsementsAction      = "endorsements:view"
+
+	ViewPoliciesAction      = "policies:view"
+	CreatePoliciesAction    = "policies:create"
+	ActivatePoliciesAction  = "policies:activate"
+	DeletePoliciesAction    = "policies:delete"
+	ConfigurePoliciesAction = "policies:configure"
+
+	ViewDecisionsAction = "decisions:view"
+
+	ViewTenantsAction   = "tenants:view"
+	ManageTenantsAction = "tenants:manage"
+
+	VerifyEvidenceAction = "evidence:verify"
+)
+
+// Resource identifies what an action is performed on. Either field may be
+// empty if the resource is not specific to a scheme or tenant (e.g. the list
+// of tenants).
+type Resource struct {
+	Scheme string
+	Tenant string
+}
+
+// ResourceGetter returns the Resource a request acts on.
+type ResourceGetter func(c *gin.Context) Resource
+
+// PermissionRule grants the principals with any of the Roles permission to
+// perform any of the Actions on resources of any of the Schemes and Tenants.
+// Actions, schemes and tenants are patterns using the syntax of path.Match,
+// so that, e.g., "policies:*" matches all policy actions, and "*" matches
+// everything. If Schemes (or Tenants) are empty, the rule applies to all
+// schemes (or tenants), including resources that are not specific to one.
+type PermissionRule struct {
+	Roles   []string `mapstructure:"roles"`
+	Actions []string `mapstructure:"actions"`
+	Schemes []string `mapstructure:"schemes"`
+	Tenants []string `mapstructure:"tenants"`
+}
+
+// Validate returns an error if the rule is invalid.
+func (o *PermissionRule) Validate() error {
+	if len(o.Roles) == 0 {
+		return errors.New("no roles specified")
+	}
+
+	if len(o.Actions) == 0 {
+		return errors.New("no actions specified")
+	}
+
+	for _, patterns := range [][]string{o.Actions, o.Schemes, o.Tenants} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *PermissionRule) permits(roles []string, action string, resource Resource) bool {
+	if !hasAnyRole(roles, o.Roles) || !matchesPatterns(o.Actions, action) {
+		return false
+	}
+
+	return matchesPatterns(o.Schemes, resource.Scheme) &&
+		matchesPatterns(o.Tenants, resource.Tenant)
+}
+
+// Permissions evaluates whether principals are permitted to perform actions,
+// based on their roles. It is shared by all authorizer backends, which are
+// responsible for establishing the roles of the principal.
+type Permissions struct {
+	rules    []*PermissionRule
+	allowAll bool
+}
+
+// NewPermissions returns a new Permissions evaluating the specified rules.
+// Actions are only permitted if granted by at least one rule.
+func NewPermissions(rules []*PermissionRule) (*Permissions, error) {
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid permission rule %d: %w", i, err)
+		}
+	}
+
+	return &Permissions{rules: rules}, nil
+}
+
+// NewPermissionsFromConfig returns a new Permissions evaluating the rules
+// contained in raw config (a list of maps, e.g. as returned by
+// viper.Get("permissions")). If raw is nil, DefaultPermissions are returned.
+func NewPermissionsFromConfig(raw any) (*Permissions, error) {
+	if raw == nil {
+		return DefaultPermissions(), nil
+	}
+
+	var rules []*PermissionRule
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           &rules,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(raw); err != nil {
+		return nil, fmt.Errorf("invalid permissions: %w", err)
+	}
+
+	return NewPermissions(rules)
+}
+
+// DefaultPermissions returns the Permissions used when none have been
+// configured: managers may perform all management actions, provisioners may
+// provision endorsements, and attesters may submit evidence for verification,
+// for all schemes and tenants.
+func DefaultPermissions() *Permissions {
+	return &Permissions{
+		rules: []*PermissionRule{
+			{
+				Roles: []string{ManagerRole},
+				Actions: []string{
+					"policies:*",
+					ViewEndorsementsAction,
+					ViewDecisionsAction,
+					"tenants:*",
+				},
+			},
+			{
+				Roles:   []string{ProvisionerRole},
+				Actions: []string{ProvisionEndorsementsAction},
+			},
+			{
+				Roles:   []string{AttesterRole},
+				Actions: []string{VerifyEvidenceAction},
+			},
+		},
+	}
+}
+
+// AllowAll returns Permissions that permit all actions, irrespective of roles.
+func AllowAll() *Permissions {
+	return &Permissions{allowAll: true}
+}
+
+// IsPermitted returns true if a principal with the specified roles is
+// permitted to perform the action on the resource.
+func (o *Permissions) IsPermitted(roles []string, action string, resource Resource) bool {
+	if o.allowAll {
+		return true
+	}
+
+	for _, rule := range o.rules {
+		if rule.permits(roles, action, resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsPermittedForAny returns true if a principal with the specified roles is
+// permitted to perform the action on at least some resources.
+func (o *Permissions) IsPermittedForAny(roles []string, action string) bool {
+	if o.allowAll {
+		return true
+	}
+
+	for _, rule := range o.rules {
+		if hasAnyRole(roles, rule.Roles) && matchesPatterns(rule.Actions, action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const permissionsKey = "permissions"
+
+// IsPermitted returns true if the principal authenticated for the request is
+// permitted to perform the action on the resource. This is for use by
+// handlers that are only able to establish the resource a request acts on
+// after processing it; the request must have passed through a handler
+// returned by IAuthorizer.GetPermissionHandler() first, otherwise false is
+// returned.
+func IsPermitted(c *gin.Context, action string, resource Resource) bool {
+	permissions, ok := c.Get(permissionsKey)
+	if !ok {
+		return false
+	}
+
+	return permissions.(*Permissions).IsPermitted(GetRoles(c), action, resource)
+}
+
+// permissionEnforcer is embedded by authorizer backends in order to enforce
+// permissions using the shared Permissions evaluator.
+type permissionEnforcer struct {
+	permissions *Permissions
+}
+
+func (o *permissionEnforcer) setPermissions(permissions *Permissions) {
+	o.permissions = permissions
+}
+
+// permissionHandler returns a gin.HandlerFunc that authenticates the request
+// using authenticate (which must record the principal's roles in the
+// context), and then checks that the principal is permitted to perform the
+// action on the resource returned by getResource. If getResource is nil, the
+// principal must be permitted to perform the action on at least some
+// resources, and the handler for the request is responsible for checking the
+// specific resource using IsPermitted().
+func (o *permissionEnforcer) permissionHandler(
+	authenticate gin.HandlerFunc,
+	action string,
+	getResource ResourceGetter,
+) gin.HandlerFunc {
+	permissions := o.permissions
+	if permissions == nil {
+		permissions = DefaultPermissions()
+	}
+
+	return func(c *gin.Context) {
+		authenticate(c)
+		if c.IsAborted() {
+			return
+		}
+
+		c.Set(permissionsKey, permissions)
+
+		var permitted bool
+		if getResource == nil {
+			permitted = permissions.IsPermittedForAny(GetRoles(c), action)
+		} else {
+			permitted = permissions.IsPermitted(GetRoles(c), action, getResource(c))
+		}
+
+		if !permitted {
+			ReportProblem(c, http.StatusForbidden,
+				fmt.Sprintf("%s not permitted for user", action))
+		}
+	}
+}
+
+type permissionsSetter interface {
+	setPermissions(permissions *Permissions)
+}
+
+// matchesPatterns returns true if there are no patterns, or if one of them
+// matches the value.
+func matchesPatterns(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasAnyRole(roles, wanted []string) bool {
+	for _, role := range wanted {
+		if hasRole(roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissions_IsPermitted_patterns(t *testing.T) {
+	permissions, err := NewPermissions([]*PermissionRule{
+		{
+			Roles:   []string{ManagerRole},
+			Actions: []string{"policies:*"},
+			Schemes: []string{"PSA_*"},
+			Tenants: []string{"acme", "acme-*"},
+		},
+		{
+			Roles:   []string{ProvisionerRole},
+			Actions: []string{ProvisionEndorsementsAction},
+		},
+	})
+	require.NoError(t, err)
+
+	manager := []string{ManagerRole}
+	provisioner := []string{ProvisionerRole}
+
+	for _, tc := range []struct {
+		name     string
+		roles    []string
+		action   string
+		resource Resource
+		expected bool
+	}{
+		{"matching", manager, ViewPoliciesAction, Resource{"PSA_IOT", "acme"}, true},
+		{"tenant glob", manager, DeletePoliciesAction, Resource{"PSA_IOT", "acme-eu"}, true},
+		{"other action", manager, ViewDecisionsAction, Resource{"PSA_IOT", "acme"}, false},
+		{"other scheme", manager, ViewPoliciesAction, Resource{"ARM_CCA", "acme"}, false},
+		{"other tenant", manager, ViewPoliciesAction, Resource{"PSA_IOT", "globex"}, false},
+		{"no tenant", manager, ViewPoliciesAction, Resource{"PSA_IOT", ""}, false},
+		{"wrong role", provisioner, ViewPoliciesAction, Resource{"PSA_IOT", "acme"}, false},
+		{"no roles", nil, ViewPoliciesAction, Resource{"PSA_IOT", "acme"}, false},
+		{"unrestricted", provisioner, ProvisionEndorsementsAction, Resource{"ARM_CCA", "globex"}, true},
+		{"no resource", provisioner, ProvisionEndorsementsAction, Resource{}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected,
+				permissions.IsPermitted(tc.roles, tc.action, tc.resource))
+		})
+	}
+}
+
+func TestPermissions_IsPermitted_default_deny(t *testing.T) {
+	permissions, err := NewPermissions(nil)
+	require.NoError(t, err)
+
+	assert.False(t, permissions.IsPermitted(
+		[]string{ManagerRole}, ViewPoliciesAction, Resource{}))
+	assert.False(t, permissions.IsPermittedForAny([]string{ManagerRole}, ViewPoliciesAction))
+}
+
+func TestPermissions_IsPermittedForAny(t *testing.T) {
+	permissions, err := NewPermissions([]*PermissionRule{
+		{
+			Roles:   []string{ManagerRole},
+			Actions: []string{ViewDecisionsAction},
+			Tenants: []string{"acme"},
+		},
+	})
+	require.NoError(t, err)
+
+	// the resource restrictions are ignored
+	assert.True(t, permissions.IsPermittedForAny([]string{ManagerRole}, ViewDecisionsAction))
+
+	assert.False(t, permissions.IsPermittedForAny([]string{ManagerRole}, ViewPoliciesAction))
+	assert.False(t, permissions.IsPermittedForAny([]string{AttesterRole}, ViewDecisionsAction))
+}
+
+func TestDefaultPermissions(t *testing.T) {
+	permissions := DefaultPermissions()
+	resource := Resource{Scheme: "PSA_IOT", Tenant: "acme"}
+
+	assert.True(t, permissions.IsPermitted([]string{ManagerRole}, ConfigurePoliciesAction, resource))
+	assert.True(t, permissions.IsPermitted([]string{ManagerRole}, ManageTenantsAction, Resource{}))
+	assert.False(t, permissions.IsPermitted([]string{ManagerRole}, ProvisionEndorsementsAction, resource))
+
+	assert.True(t, permissions.IsPermitted([]string{ProvisionerRole}, ProvisionEndorsementsAction, resource))
+	assert.False(t, permissions.IsPermitted([]string{ProvisionerRole}, ViewEndorsementsAction, resource))
+
+	assert.True(t, permissions.IsPermitted([]string{AttesterRole}, VerifyEvidenceAction, resource))
+	assert.False(t, permissions.IsPermitted([]string{AttesterRole}, ViewDecisionsAction, resource))
+
+	assert.False(t, permissions.IsPermitted([]string{NoRole}, VerifyEvidenceAction, resource))
+}
+
+func TestAllowAll(t *testing.T) {
+	permissions := AllowAll()
+
+	assert.True(t, permissions.IsPermitted(nil, ManageTenantsAction, Resource{}))
+	assert.True(t, permissions.IsPermittedForAny(nil, VerifyEvidenceAction))
+}
+
+func TestNewPermissions_bad(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		rule     PermissionRule
+		expected string
+	}{
+		{"no roles", PermissionRule{Actions: []string{"*"}}, "no roles specified"},
+		{"no actions", PermissionRule{Roles: []string{ManagerRole}}, "no actions specified"},
+		{
+			"bad pattern",
+			PermissionRule{
+				Roles:   []string{ManagerRole},
+				Actions: []string{"*"},
+				Tenants: []string{"acme["},
+			},
+			`invalid pattern "acme["`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPermissions([]*PermissionRule{&tc.rule})
+			assert.ErrorContains(t, err, "invalid permission rule 0: "+tc.expected)
+		})
+	}
+}
+
+func TestNewPermissionsFromConfig(t *testing.T) {
+	permissions, err := NewPermissionsFromConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPermissions(), permissions)
+
+	permissions, err = NewPermissionsFromConfig([]any{
+		map[string]any{
+			"roles":   []any{ManagerRole},
+			"actions": []any{"policies:view"},
+			"tenants": []any{"acme"},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, permissions.IsPermitted(
+		[]string{ManagerRole}, ViewPoliciesAction, Resource{Tenant: "acme"}))
+	assert.False(t, permissions.IsPermitted(
+		[]string{ManagerRole}, ViewPoliciesAction, Resource{Tenant: "globex"}))
+
+	_, err = NewPermissionsFromConfig([]any{
+		map[string]any{
+			"roles":   []any{ManagerRole},
+			"actions": []any{"*"},
+			"verbs":   []any{"view"},
+		},
+	})
+	assert.ErrorContains(t, err, "invalid permissions")
+
+	_, err = NewPermissionsFromConfig([]any{
+		map[string]any{"roles": []any{ManagerRole}},
+	})
+	assert.ErrorContains(t, err, "no actions specified")
+}
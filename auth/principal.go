@@ -0,0 +1,38 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package auth
+
+import "github.com/gin-gonic/gin"
+
+// PrincipalKey is the gin.Context key under which authorizers record the
+// identity of the authenticated principal.
+const PrincipalKey = "uid"
+
+// TenantKey is the gin.Context key under which authorizers record the ID of
+// the tenant the authenticated principal belongs to, if known.
+const TenantKey = "tenant_id"
+
+// RolesKey is the gin.Context key under which authorizers record the roles of
+// the authenticated principal.
+const RolesKey = "roles"
+
+// GetPrincipal returns the identity of the principal authenticated for the
+// request, as recorded by the authorizer. An empty string is returned if the
+// authorizer did not record one (e.g. passthrough).
+func GetPrincipal(c *gin.Context) string {
+	return c.GetString(PrincipalKey)
+}
+
+// GetTenant returns the ID of the tenant of the principal authenticated for
+// the request, as recorded by the authorizer. An empty string is returned if
+// the authorizer did not record one (e.g. because the principal's token did
+// not contain a tenant claim).
+func GetTenant(c *gin.Context) string {
+	return c.GetString(TenantKey)
+}
+
+// GetRoles returns the roles of the principal authenticated for the request,
+// as recorded by the authorizer.
+func GetRoles(c *gin.Context) []string {
+	return c.GetStringSlice(RolesKey)
+}
@@ -5,3 +5,4 @@ package auth
 var NoRole = ""
 var ManagerRole = "manager"
 var ProvisionerRole = "provisioner"
+var AttesterRole = "attester"
@@ -0,0 +1,164 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package bodylimit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mitchellh/mapstructure"
+	"github.com/veraison/services/api"
+)
+
+var ErrTooLarge = errors.New("request body too large")
+
+// Limits are the maximum sizes of request bodies, by media type.
+type Limits struct {
+	defaultSize int64
+	sizes       map[string]int64
+}
+
+// New returns new Limits allowing bodies of up to defaultSize bytes for all
+// media types.
+func New(defaultSize int64) *Limits {
+	return &Limits{
+		defaultSize: defaultSize,
+		sizes:       make(map[string]int64),
+	}
+}
+
+// mediaTypeSize is the configuration of the maximum size of the bodies of a
+// media type.
+type mediaTypeSize struct {
+	MediaType string `mapstructure:"media-type"`
+	Size      string `mapstructure:"size"`
+}
+
+// NewFromConfig returns new Limits based on the configured default size and
+// per-media type sizes. Sizes are strings such as "512KiB" or "16MB". If
+// defaultSize is empty, fallback is used as the default size. sizes is a list
+// of maps, each containing a "media-type" and its "size" (media types are not
+// used as keys, as they may contain dots, which would be interpreted as key
+// delimiters by the config loader).
+func NewFromConfig(fallback int64, defaultSize string, sizes []map[string]any) (*Limits, error) {
+	limits := New(fallback)
+
+	if defaultSize != "" {
+		size, err := parseSize(defaultSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default size: %w", err)
+		}
+
+		limits.defaultSize = size
+	}
+
+	for i, raw := range sizes {
+		var entry mediaTypeSize
+
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			ErrorUnused:      true,
+			WeaklyTypedInput: true,
+			Result:           &entry,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decoder.Decode(raw); err != nil {
+			return nil, fmt.Errorf("invalid size %d: %w", i, err)
+		}
+
+		size, err := parseSize(entry.Size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %d: %w", i, err)
+		}
+
+		if err := limits.Set(entry.MediaType, size); err != nil {
+			return nil, fmt.Errorf("invalid size %d: %w", i, err)
+		}
+	}
+
+	return limits, nil
+}
+
+// Set the maximum size of the bodies of the specified media type. If the media
+// type does not have parameters, the size also applies to the media type with
+// any parameters, unless they have been set separately.
+func (o *Limits) Set(mediaType string, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("size for %q must be positive", mediaType)
+	}
+
+	normalized, err := api.NormalizeMediaType(mediaType)
+	if err != nil {
+		return fmt.Errorf("invalid media type %q: %w", mediaType, err)
+	}
+
+	o.sizes[normalized] = size
+
+	return nil
+}
+
+// Get returns the maximum size of the bodies of the specified media type.
+func (o *Limits) Get(mediaType string) int64 {
+	normalized, err := api.NormalizeMediaType(mediaType)
+	if err != nil {
+		return o.defaultSize
+	}
+
+	if size, ok := o.sizes[normalized]; ok {
+		return size
+	}
+
+	base, _, _ := mime.ParseMediaType(normalized)
+	if size, ok := o.sizes[base]; ok {
+		return size
+	}
+
+	return o.defaultSize
+}
+
+// ReadBody reads the body of the request, which is of the specified media
+// type. An error wrapping ErrTooLarge is returned if the body exceeds the
+// maximum size for the media type; in that case, the body is not read beyond
+// the maximum size.
+func (o *Limits) ReadBody(w http.ResponseWriter, r *http.Request, mediaType string) ([]byte, error) {
+	limit := o.Get(mediaType)
+
+	if r.ContentLength > limit {
+		return nil, tooLargeError(limit)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, tooLargeError(limit)
+		}
+
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func tooLargeError(limit int64) error {
+	return fmt.Errorf("%w: the maximum size is %d bytes", ErrTooLarge, limit)
+}
+
+func parseSize(s string) (int64, error) {
+	size, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if size == 0 || size > uint64(1<<62) {
+		return 0, fmt.Errorf("size %q out of range", s)
+	}
+
+	return int64(size), nil
+}
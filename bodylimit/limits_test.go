@@ -0,0 +1,74 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package bodylimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits_Get(t *testing.T) {
+	limits, err := NewFromConfig(1024, "2KiB", []map[string]any{
+		{"media-type": "application/eat+cwt", "size": "4KiB"},
+		{"media-type": `application/eat+cwt; eat_profile="tag:psacertified.org,2023:psa#tfm"`, "size": "8KiB"},
+		{"media-type": "application/rim+cbor", "size": "1MB"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2048), limits.Get("application/json"))
+	assert.Equal(t, int64(2048), limits.Get("not a media type"))
+	assert.Equal(t, int64(4096), limits.Get("application/eat+cwt"))
+	assert.Equal(t, int64(4096), limits.Get(`application/eat+cwt; eat_profile=2.999.1`))
+	assert.Equal(t, int64(8192),
+		limits.Get(`application/EAT+CWT; eat_profile="tag:psacertified.org,2023:psa#tfm"`))
+	assert.Equal(t, int64(1000000), limits.Get("application/rim+cbor"))
+
+	limits, err = NewFromConfig(1024, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), limits.Get("application/json"))
+}
+
+func TestNewFromConfig_invalid(t *testing.T) {
+	_, err := NewFromConfig(1024, "lots", nil)
+	assert.ErrorContains(t, err, "invalid default size")
+
+	_, err = NewFromConfig(1024, "", []map[string]any{
+		{"media-type": "application/json", "size": "0"},
+	})
+	assert.EqualError(t, err, `invalid size 0: size "0" out of range`)
+
+	_, err = NewFromConfig(1024, "", []map[string]any{
+		{"media-type": "application/json", "size": "1KiB", "typo": 1},
+	})
+	assert.ErrorContains(t, err, "invalid size 0")
+
+	_, err = NewFromConfig(1024, "", []map[string]any{
+		{"media-type": "application/", "size": "1KiB"},
+	})
+	assert.ErrorContains(t, err, `invalid size 0: invalid media type "application/"`)
+}
+
+func TestLimits_ReadBody(t *testing.T) {
+	limits := New(4)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("abcd"))
+	body, err := limits.ReadBody(httptest.NewRecorder(), req, "text/plain")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("abcd"), body)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("abcde"))
+	_, err = limits.ReadBody(httptest.NewRecorder(), req, "text/plain")
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.EqualError(t, err, "request body too large: the maximum size is 4 bytes")
+
+	// without a Content-Length, the body is read up to the limit
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("abcde"))
+	req.ContentLength = -1
+	_, err = limits.ReadBody(httptest.NewRecorder(), req, "text/plain")
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
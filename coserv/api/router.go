@@ -1,4 +1,4 @@
-// Copyright 2025 Contributors to the Veraison project.
+// Copyright 2025-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package api
 
@@ -6,6 +6,7 @@ import (
 	"path"
 
 	"github.com/gin-gonic/gin"
+	"github.com/veraison/services/ratelimit"
 )
 
 const (
@@ -14,7 +15,10 @@ const (
 
 var publicApiMap = make(map[string]string)
 
-func NewRouter(handler Handler) *gin.Engine {
+// NewRouter returns the router for the endorsement distribution API. If limiter
+// is not nil, CoSERV requests are subject to its client rate limits and
+// quotas (the API is not tenant-specific, so tenant limits do not apply).
+func NewRouter(handler Handler, limiter *ratelimit.Limiter) *gin.Engine {
 	router := gin.New()
 
 	router.Use(gin.Logger())
@@ -25,7 +29,12 @@ func NewRouter(handler Handler) *gin.Engine {
 	coservEndpoint := path.Join(edApiPath, "coserv/:query")
 	// use URI template syntax to indicate the variable part in the discovery document
 	publicApiMap["CoSERVRequestResponse"] = path.Join(edApiPath, "coserv/{query}")
-	router.GET(coservEndpoint, handler.CoservRequest)
+	if limiter != nil {
+		limiter.SetTrustedProxies(router)
+		router.GET(coservEndpoint, limiter.GetGinHandler(nil), handler.CoservRequest)
+	} else {
+		router.GET(coservEndpoint, handler.CoservRequest)
+	}
 
 	return router
 }
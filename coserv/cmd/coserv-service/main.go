@@ -11,6 +11,7 @@ import (
 	"github.com/veraison/services/coserv/endorsementdistributor"
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/proto"
+	"github.com/veraison/services/ratelimit"
 	"github.com/veraison/services/vtsclient"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -93,27 +94,37 @@ func main() {
 	log.Info("initializing endorsement distributor")
 	endorsementdistributor := endorsementdistributor.New(vtsClient)
 
+	log.Info("initializing rate limiter")
+	limiter, err := ratelimit.NewLimiterFromConfig(v.Sub("rate-limit"), nil, log.Named("ratelimit"))
+	if err != nil {
+		log.Fatalf("could not init rate limiter: %v", err)
+	}
+
 	apiHandler := api.NewHandler(endorsementdistributor, log.Named("coserv"), cfg.DiscoveryMaxAge)
 
 	if cfg.Protocol == "https" {
-		apiServerTLS(apiHandler, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
+		apiServerTLS(apiHandler, limiter, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
 	} else {
-		apiServer(apiHandler, cfg.ListenAddr)
+		apiServer(apiHandler, limiter, cfg.ListenAddr)
 	}
 }
 
-func apiServer(apiHandler api.Handler, listenAddr string) {
+func apiServer(apiHandler api.Handler, limiter *ratelimit.Limiter, listenAddr string) {
 	log.Infow("initializing endorsement distribution API HTTP service", "address", listenAddr)
 
-	if err := api.NewRouter(apiHandler).Run(listenAddr); err != nil {
+	if err := api.NewRouter(apiHandler, limiter).Run(listenAddr); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
 
-func apiServerTLS(apiHandler api.Handler, listenAddr, certFile, keyFile string) {
+func apiServerTLS(
+	apiHandler api.Handler,
+	limiter *ratelimit.Limiter,
+	listenAddr, certFile, keyFile string,
+) {
 	log.Infow("initializing endorsement distribution API HTTPS service", "address", listenAddr)
 
-	if err := api.NewRouter(apiHandler).RunTLS(listenAddr, certFile, keyFile); err != nil {
+	if err := api.NewRouter(apiHandler, limiter).RunTLS(listenAddr, certFile, keyFile); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
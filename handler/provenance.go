@@ -0,0 +1,117 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/ear"
+)
+
+// EndorsementProvenanceClaim is the name of the annotated evidence claim under
+// which AnnotateEndorsements records the endorsements matched during
+// appraisal. If endorsement provenance is enabled in the VTS, the recorded
+// endorsements are replaced with the provenance of the CoRIMs that supplied
+// them; otherwise, the claim is removed.
+const EndorsementProvenanceClaim = "veraison.endorsement-provenance"
+
+// EndorsementIDs returns the IDs of the measurements inside the specified
+// triple. The ID of a measurement is the SHA-256 digest of the CBOR encodings
+// of its environment and of the measurement itself, so that it identifies the
+// measurement regardless of the triple it is grouped in.
+func EndorsementIDs(triple *comid.ValueTriple) ([]string, error) {
+	env, err := triple.Environment.ToCBOR()
+	if err != nil {
+		return nil, fmt.Errorf("environment: %w", err)
+	}
+
+	ids := make([]string, 0, len(triple.Measurements.Values))
+
+	for i := range triple.Measurements.Values {
+		var measurements comid.Measurements
+		measurements.Add(&triple.Measurements.Values[i])
+
+		data, err := measurements.MarshalCBOR()
+		if err != nil {
+			return nil, fmt.Errorf("measurement %d: %w", i, err)
+		}
+
+		h := sha256.New()
+		h.Write(env)
+		h.Write(data)
+
+		ids = append(ids, hex.EncodeToString(h.Sum(nil)))
+	}
+
+	return ids, nil
+}
+
+// VerificationKeyIDs returns the IDs of the verification keys inside the
+// specified triple. As with EndorsementIDs, the ID of a key is the SHA-256
+// digest of the CBOR encodings of its environment and of the key itself.
+func VerificationKeyIDs(triple *comid.KeyTriple) ([]string, error) {
+	env, err := triple.Environment.ToCBOR()
+	if err != nil {
+		return nil, fmt.Errorf("environment: %w", err)
+	}
+
+	ids := make([]string, 0, len(triple.VerifKeys))
+
+	for i, key := range triple.VerifKeys {
+		if key == nil {
+			return nil, fmt.Errorf("key %d: nil key", i)
+		}
+
+		data, err := key.MarshalCBOR()
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+
+		h := sha256.New()
+		h.Write(env)
+		h.Write(data)
+
+		ids = append(ids, hex.EncodeToString(h.Sum(nil)))
+	}
+
+	return ids, nil
+}
+
+// AnnotateEndorsements records the specified endorsements as having been
+// matched during the appraisal, so that their provenance may be reported in
+// the attestation result. As it adds to the appraisal's annotated evidence,
+// it must be called after the annotated evidence has been set.
+func AnnotateEndorsements(appraisal *ear.Appraisal, triples ...*comid.ValueTriple) error {
+	if appraisal.VeraisonAnnotatedEvidence == nil {
+		appraisal.VeraisonAnnotatedEvidence = &map[string]interface{}{}
+	}
+
+	annotated := *appraisal.VeraisonAnnotatedEvidence
+
+	ids, _ := annotated[EndorsementProvenanceClaim].([]interface{})
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[fmt.Sprint(id)] = true
+	}
+
+	for i, triple := range triples {
+		tripleIDs, err := EndorsementIDs(triple)
+		if err != nil {
+			return fmt.Errorf("endorsement %d: %w", i, err)
+		}
+
+		for _, id := range tripleIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	annotated[EndorsementProvenanceClaim] = ids
+
+	return nil
+}
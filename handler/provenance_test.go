@@ -0,0 +1,117 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package handler
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/ear"
+	"github.com/veraison/swid"
+)
+
+func newTestValueTriple(names ...string) *comid.ValueTriple {
+	vendor, model := "ACME", "RoadRunner"
+	triple := comid.ValueTriple{
+		Environment: comid.Environment{
+			Class: &comid.Class{Vendor: &vendor, Model: &model},
+		},
+	}
+
+	for i, name := range names {
+		m := comid.Measurement{}
+		m.Val.Name = &name
+		m.AddDigest(int(swid.Sha256), []byte{byte(i), 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13,
+			14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31})
+		triple.Measurements.Add(&m)
+	}
+
+	return &triple
+}
+
+func Test_EndorsementIDs(t *testing.T) {
+	triple := newTestValueTriple("BL", "PRoT")
+
+	ids, err := EndorsementIDs(triple)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+
+	// IDs survive the CBOR round-trip endorsements go through on their
+	// way to scheme plugins
+	data, err := cbor.Marshal(triple)
+	require.NoError(t, err)
+
+	var decoded comid.ValueTriple
+	require.NoError(t, cbor.Unmarshal(data, &decoded))
+
+	decodedIDs, err := EndorsementIDs(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, ids, decodedIDs)
+
+	// IDs do not depend on how measurements are grouped into triples
+	split := newTestValueTriple("BL")
+	splitIDs, err := EndorsementIDs(split)
+	require.NoError(t, err)
+	assert.Equal(t, ids[:1], splitIDs)
+}
+
+func Test_VerificationKeyIDs(t *testing.T) {
+	pkixKey, err := comid.NewPKIXBase64Key(comid.TestECPubKey)
+	require.NoError(t, err)
+
+	bytesKey, err := comid.NewCryptoKeyTaggedBytes([]byte{1, 2, 3, 4})
+	require.NoError(t, err)
+
+	triple := comid.KeyTriple{
+		Environment: newTestValueTriple().Environment,
+		VerifKeys:   comid.CryptoKeys{pkixKey, bytesKey},
+	}
+
+	ids, err := VerificationKeyIDs(&triple)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+
+	data, err := cbor.Marshal(triple)
+	require.NoError(t, err)
+
+	var decoded comid.KeyTriple
+	require.NoError(t, cbor.Unmarshal(data, &decoded))
+
+	decodedIDs, err := VerificationKeyIDs(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, ids, decodedIDs)
+
+	triple.VerifKeys = comid.CryptoKeys{nil}
+	_, err = VerificationKeyIDs(&triple)
+	assert.EqualError(t, err, "key 0: nil key")
+}
+
+func Test_AnnotateEndorsements(t *testing.T) {
+	appraisal := ear.NewAppraisal()
+	claims := map[string]interface{}{"psa-nonce": "AAAA"}
+	appraisal.VeraisonAnnotatedEvidence = &claims
+
+	triple := newTestValueTriple("BL", "PRoT")
+	ids, err := EndorsementIDs(triple)
+	require.NoError(t, err)
+
+	require.NoError(t, AnnotateEndorsements(appraisal, triple))
+	require.NoError(t, AnnotateEndorsements(appraisal, newTestValueTriple("BL")))
+
+	annotated := *appraisal.VeraisonAnnotatedEvidence
+	assert.Equal(t, "AAAA", annotated["psa-nonce"])
+	assert.Equal(t, []interface{}{ids[0], ids[1]}, annotated[EndorsementProvenanceClaim])
+}
+
+func Test_AnnotateEndorsements_no_annotated_evidence(t *testing.T) {
+	appraisal := ear.NewAppraisal()
+
+	require.NoError(t, AnnotateEndorsements(appraisal, newTestValueTriple("BL")))
+	require.NotNil(t, appraisal.VeraisonAnnotatedEvidence)
+	assert.Len(t, (*appraisal.VeraisonAnnotatedEvidence)[EndorsementProvenanceClaim], 1)
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+var (
+	DefaultBBoltBucket  = "kvstore"
+	DefaultBBoltTimeout = "1s"
+)
+
+type bboltConfig struct {
+	Path    string `mapstructure:"path"`
+	Bucket  string `mapstructure:"bucket" config:"zerodefault"`
+	Timeout string `mapstructure:"timeout" config:"zerodefault"`
+}
+
+func (o bboltConfig) Validate() error {
+	if _, err := time.ParseDuration(o.Timeout); err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	return nil
+}
+
+// BBolt is a store backed by an embedded bbolt database file. All keys are
+// stored inside a single bucket, with each key's values JSON-encoded as a
+// list. Writes are durable once they return: each is performed in its own
+// transaction, which is fsync'ed to disk on commit.
+type BBolt struct {
+	Bucket []byte
+	DB     *bolt.DB
+
+	logger *zap.SugaredLogger
+}
+
+// Init initializes the KVStore. The config may contain the following values:
+// "bbolt.path" - The path to the database file. It is created if it does not
+//
+//	exist.
+//
+// "bbolt.bucket" (optional) - The name of the bucket containing the key-value
+//
+//	pairs (defaults to "kvstore").
+//
+// "bbolt.timeout" (optional) - How long to wait for the file lock, should the
+//
+//	database be opened by another process (defaults to "1s").
+func (o *BBolt) Init(v *viper.Viper, logger *zap.SugaredLogger) error {
+	o.logger = logger
+
+	cfg := bboltConfig{
+		Bucket:  DefaultBBoltBucket,
+		Timeout: DefaultBBoltTimeout,
+	}
+
+	loader := config.NewLoader(&cfg)
+	if err := loader.LoadFromViper(v.Sub("bbolt")); err != nil {
+		return fmt.Errorf("bbolt: %w", err)
+	}
+
+	// already validated by the loader
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+
+	db, err := bolt.Open(cfg.Path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return fmt.Errorf("bbolt: %w", err)
+	}
+
+	o.Bucket = []byte(cfg.Bucket)
+	o.DB = db
+	o.logger.Infow("store opened", "path", cfg.Path, "bucket", cfg.Bucket)
+
+	return nil
+}
+
+func (o *BBolt) Close() error {
+	if o.DB == nil {
+		return nil
+	}
+
+	return o.DB.Close()
+}
+
+func (o BBolt) Setup() error {
+	if o.DB == nil {
+		return errors.New("bbolt store uninitialized")
+	}
+
+	o.logger.Debugw("create bucket", "bucket", string(o.Bucket))
+	return o.DB.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucket(o.Bucket); err != nil {
+			return fmt.Errorf("bucket %s: %w", o.Bucket, err)
+		}
+
+		return nil
+	})
+}
+
+func (o BBolt) Get(key string) ([]string, error) {
+	vals, _, err := o.GetVersioned(key)
+	return vals, err
+}
+
+func (o BBolt) GetVersioned(key string) ([]string, Version, error) {
+	if o.DB == nil {
+		return nil, NoVersion, errors.New("bbolt store uninitialized")
+	}
+
+	if err := sanitizeK(key); err != nil {
+		return nil, NoVersion, err
+	}
+
+	var vals []string
+
+	err := o.DB.View(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		vals, err = getBBoltVals(bucket, key)
+		return err
+	})
+	if err != nil {
+		return nil, NoVersion, err
+	}
+
+	if len(vals) == 0 {
+		return nil, NoVersion, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+
+	return vals, versionOf(vals), nil
+}
+
+func (o BBolt) GetKeys() ([]string, error) {
+	if o.DB == nil {
+		return nil, errors.New("bbolt store uninitialized")
+	}
+
+	var keys []string
+
+	err := o.DB.View(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (o BBolt) ScanKeys(prefix, cursor string, limit int) ([]string, string, error) {
+	if o.DB == nil {
+		return nil, "", errors.New("bbolt store uninitialized")
+	}
+
+	var (
+		keys []string
+		next string
+	)
+
+	err := o.DB.View(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// keys are stored in byte order, so the scan can start from the
+		// later of the prefix and the cursor
+		start := prefix
+		if cursor > start {
+			start = cursor
+		}
+
+		c := bucket.Cursor()
+		for k, _ := c.Seek([]byte(start)); k != nil; k, _ = c.Next() {
+			key := string(k)
+			if !strings.HasPrefix(key, prefix) {
+				break
+			}
+
+			if key == cursor {
+				continue
+			}
+
+			if limit > 0 && len(keys) == limit {
+				next = keys[limit-1]
+				break
+			}
+
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return keys, next, nil
+}
+
+func (o BBolt) Add(key string, val string) error {
+	if o.DB == nil {
+		return errors.New("bbolt store uninitialized")
+	}
+
+	if err := sanitizeKV(key, val); err != nil {
+		return err
+	}
+
+	return o.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		vals, err := getBBoltVals(bucket, key)
+		if err != nil {
+			return err
+		}
+
+		// check if val is already present
+		for _, v := range vals {
+			if v == val {
+				return nil
+			}
+		}
+
+		return putBBoltVals(bucket, key, append(vals, val))
+	})
+}
+
+func (o BBolt) Set(key string, val string) error {
+	if o.DB == nil {
+		return errors.New("bbolt store uninitialized")
+	}
+
+	if err := sanitizeKV(key, val); err != nil {
+		return err
+	}
+
+	return o.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		return putBBoltVals(bucket, key, []string{val})
+	})
+}
+
+func (o BBolt) Del(key string) error {
+	if o.DB == nil {
+		return errors.New("bbolt store uninitialized")
+	}
+
+	if err := sanitizeK(key); err != nil {
+		return err
+	}
+
+	return o.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		if bucket.Get([]byte(key)) == nil {
+			return fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+		}
+
+		return bucket.Delete([]byte(key))
+	})
+}
+
+func (o BBolt) CompareAndSwap(key string, version Version, vals []string) error {
+	if o.DB == nil {
+		return errors.New("bbolt store uninitialized")
+	}
+
+	if err := sanitizeKVs(key, vals); err != nil {
+		return err
+	}
+
+	// bbolt allows a single read-write transaction at a time, so the check
+	// and the swap cannot be interleaved with other writes.
+	return o.DB.Update(func(tx *bolt.Tx) error {
+		bucket, err := o.getBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		current, err := getBBoltVals(bucket, key)
+		if err != nil {
+			return err
+		}
+
+		if versionOf(current) != version {
+			return fmt.Errorf("%w: %q", ErrVersionMismatch, key)
+		}
+
+		if len(vals) == 0 {
+			return bucket.Delete([]byte(key))
+		}
+
+		return putBBoltVals(bucket, key, vals)
+	})
+}
+
+func (o BBolt) getBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bucket := tx.Bucket(o.Bucket)
+	if bucket == nil {
+		return nil, fmt.Errorf("bucket %s not found (has the store been set up?)", o.Bucket)
+	}
+
+	return bucket, nil
+}
+
+func getBBoltVals(bucket *bolt.Bucket, key string) ([]string, error) {
+	data := bucket.Get([]byte(key))
+	if data == nil {
+		return nil, nil
+	}
+
+	var vals []string
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return nil, fmt.Errorf("corrupt values for key %q: %w", key, err)
+	}
+
+	return vals, nil
+}
+
+func putBBoltVals(bucket *bolt.Bucket, key string, vals []string) error {
+	data, err := json.Marshal(vals)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(key), data)
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package kvstore
+
+import (
+	"path"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func newBBoltConfig(t *testing.T, storeFile string) *viper.Viper {
+	if storeFile == "" {
+		storeFile = path.Join(t.TempDir(), "store.db")
+	}
+
+	cfg := viper.New()
+	cfg.Set("bbolt.path", storeFile)
+
+	return cfg
+}
+
+func newBBolt(t *testing.T) *BBolt {
+	s := BBolt{}
+
+	err := s.Init(newBBoltConfig(t, ""), log.Named("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	require.NoError(t, s.Setup())
+
+	return &s
+}
+
+func TestBBolt_Init_missing_path(t *testing.T) {
+	s := BBolt{}
+
+	cfg := viper.New()
+	cfg.Set("bbolt.bucket", "endorsement")
+
+	err := s.Init(cfg, log.Named("test"))
+	assert.EqualError(t, err, "bbolt: directives not found: path")
+}
+
+func TestBBolt_Init_bad_timeout(t *testing.T) {
+	s := BBolt{}
+
+	cfg := newBBoltConfig(t, "")
+	cfg.Set("bbolt.timeout", "soon")
+
+	err := s.Init(cfg, log.Named("test"))
+	assert.ErrorContains(t, err, "invalid timeout")
+}
+
+func TestBBolt_Set_Get_Del_with_uninitialised_store(t *testing.T) {
+	s := BBolt{}
+
+	expectedErr := `bbolt store uninitialized`
+
+	err := s.Set(testKey, testVal)
+	assert.EqualError(t, err, expectedErr)
+
+	err = s.Del(testKey)
+	assert.EqualError(t, err, expectedErr)
+
+	_, err = s.Get(testKey)
+	assert.EqualError(t, err, expectedErr)
+}
+
+func TestBBolt_Setup(t *testing.T) {
+	s := BBolt{}
+
+	err := s.Init(newBBoltConfig(t, ""), log.Named("test"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Get(testKey)
+	assert.EqualError(t, err, "bucket kvstore not found (has the store been set up?)")
+
+	err = s.Setup()
+	assert.NoError(t, err)
+
+	err = s.Setup()
+	assert.EqualError(t, err, "bucket kvstore: bucket already exists")
+}
+
+func TestBBolt_Set_Get_ok(t *testing.T) {
+	s := newBBolt(t)
+
+	err := s.Set(testKey, testVal)
+	assert.NoError(t, err)
+
+	val, err := s.Get(testKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testVal}, val)
+
+	err = s.Set(testKey, altTestVal)
+	assert.NoError(t, err)
+
+	val, err = s.Get(testKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{altTestVal}, val)
+}
+
+func TestBBolt_Set_bad_json(t *testing.T) {
+	s := newBBolt(t)
+
+	err := s.Set(testKey, "")
+	assert.EqualError(t, err, `the supplied val contains invalid JSON: unexpected end of JSON input`)
+}
+
+func TestBBolt_Add_using_same_key(t *testing.T) {
+	s := newBBolt(t)
+
+	err := s.Add(testKey, testVal)
+	require.NoError(t, err)
+
+	err = s.Add(testKey, altTestVal)
+	require.NoError(t, err)
+
+	// duplicate values are not added
+	err = s.Add(testKey, testVal)
+	require.NoError(t, err)
+
+	val, err := s.Get(testKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testVal, altTestVal}, val)
+}
+
+func TestBBolt_GetKeys_Del(t *testing.T) {
+	s := newBBolt(t)
+
+	require.NoError(t, s.Set(testKey, testVal))
+	require.NoError(t, s.Set(altTestKey, altTestVal))
+
+	keys, err := s.GetKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{testKey, altTestKey}, keys)
+
+	err = s.Del(testKey)
+	require.NoError(t, err)
+
+	_, err = s.Get(testKey)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	err = s.Del(testKey)
+	assert.EqualError(t, err, `key not found: "psa://tenant-1/deadbeef/beefdead"`)
+
+	keys, err = s.GetKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{altTestKey}, keys)
+}
+
+func TestBBolt_CompareAndSwap(t *testing.T) {
+	s := newBBolt(t)
+
+	err := s.CompareAndSwap(testKey, NoVersion, []string{testVal})
+	require.NoError(t, err)
+
+	err = s.CompareAndSwap(testKey, NoVersion, []string{altTestVal})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	vals, version, err := s.GetVersioned(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testVal}, vals)
+
+	err = s.CompareAndSwap(testKey, version, []string{testVal, altTestVal})
+	require.NoError(t, err)
+
+	err = s.CompareAndSwap(testKey, version, nil)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	_, version, err = s.GetVersioned(testKey)
+	require.NoError(t, err)
+
+	err = s.CompareAndSwap(testKey, version, nil)
+	require.NoError(t, err)
+
+	_, err = s.Get(testKey)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestBBolt_persistence(t *testing.T) {
+	storeFile := path.Join(t.TempDir(), "store.db")
+
+	s := BBolt{}
+	require.NoError(t, s.Init(newBBoltConfig(t, storeFile), log.Named("test")))
+	require.NoError(t, s.Setup())
+	require.NoError(t, s.Add(testKey, testVal))
+	require.NoError(t, s.Add(testKey, altTestVal))
+	require.NoError(t, s.Close())
+
+	s = BBolt{}
+	require.NoError(t, s.Init(newBBoltConfig(t, storeFile), log.Named("test")))
+	defer s.Close()
+
+	val, err := s.Get(testKey)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testVal, altTestVal}, val)
+}
+
+func TestBBolt_shared_file(t *testing.T) {
+	s := newBBolt(t)
+	require.NoError(t, s.Set(testKey, testVal))
+
+	// a second store cannot open the file while it is locked by the first
+	other := BBolt{}
+	cfg := newBBoltConfig(t, s.DB.Path())
+	cfg.Set("bbolt.timeout", "10ms")
+
+	err := other.Init(cfg, log.Named("test"))
+	assert.ErrorContains(t, err, "timeout")
+}
+
+func TestBBolt_ScanKeys(t *testing.T) {
+	s := newBBolt(t)
+
+	for _, k := range []string{"a:3", "a:1", "b:1", "a:2", "ab:1"} {
+		require.NoError(t, s.Set(k, testVal))
+	}
+
+	keys, next, err := s.ScanKeys("a:", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "a:2"}, keys)
+	assert.Equal(t, "a:2", next)
+
+	keys, next, err = s.ScanKeys("a:", next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:3"}, keys)
+	assert.Equal(t, "", next)
+
+	// a page ending exactly on the last key
+	keys, next, err = s.ScanKeys("a:", "a:1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:2", "a:3"}, keys)
+	assert.Equal(t, "", next)
+
+	keys, next, err = s.ScanKeys("", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "a:2", "a:3", "ab:1", "b:1"}, keys)
+	assert.Equal(t, "", next)
+}
@@ -1,14 +1,46 @@
-// Copyright 2021-2023 Contributors to the Veraison project.
+// Copyright 2021-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package kvstore
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 )
 
 var ErrKeyNotFound = errors.New("key not found")
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// Version identifies the values associated with a key at a point in time. It
+// changes whenever the values change.
+type Version string
+
+// NoVersion is the Version of a key that is not in the store.
+const NoVersion Version = ""
+
+// versionOf returns the Version of the specified values.
+func versionOf(vals []string) Version {
+	if len(vals) == 0 {
+		return NoVersion
+	}
+
+	// the order in which values are returned is not guaranteed by all
+	// backends, so it must not affect the version
+	sorted := append([]string(nil), vals...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, v := range sorted {
+		// length-prefix values so that different splits of the same
+		// content result in different versions
+		fmt.Fprintf(h, "%d:%s", len(v), v)
+	}
+
+	return Version(hex.EncodeToString(h.Sum(nil)))
+}
 
 func sanitizeKV(key, val string) error {
 	if err := sanitizeK(key); err != nil {
@@ -22,6 +54,20 @@ func sanitizeKV(key, val string) error {
 	return nil
 }
 
+func sanitizeKVs(key string, vals []string) error {
+	if err := sanitizeK(key); err != nil {
+		return err
+	}
+
+	for _, val := range vals {
+		if err := sanitizeV(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func sanitizeK(key string) error {
 	if key == "" {
 		return errors.New("the supplied key is empty")
@@ -39,3 +85,22 @@ func sanitizeV(val string) error {
 
 	return nil
 }
+
+// pageKeys returns the page of keys following the cursor from sortedKeys
+// (which must be sorted in ascending order and only contain keys matching the
+// scanned prefix), along with the cursor for the next page.
+func pageKeys(sortedKeys []string, cursor string, limit int) ([]string, string) {
+	start := sort.SearchStrings(sortedKeys, cursor)
+	if start < len(sortedKeys) && sortedKeys[start] == cursor {
+		start++
+	}
+
+	keys := sortedKeys[start:]
+	if limit <= 0 || len(keys) <= limit {
+		return keys, ""
+	}
+
+	keys = keys[:limit]
+
+	return keys, keys[limit-1]
+}
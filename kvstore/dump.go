@@ -0,0 +1,156 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package kvstore
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// DumpPageSize is the number of keys retrieved from the store at a time when
+// it is being dumped or summarized.
+var DumpPageSize = 1000
+
+// Record is a key along with its values, as written by Dump and read by Load.
+type Record struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// Summary describes the contents of a store, so that they may be checked
+// against those of another store without comparing them value by value.
+type Summary struct {
+	// Keys is the number of keys in the store.
+	Keys int `json:"keys"`
+	// Digest is a SHA-256 digest over all keys and their values. It does
+	// not depend on the order in which keys are stored, or in which a
+	// key's values are returned.
+	Digest string `json:"digest"`
+}
+
+// Summarizer computes the Summary of contents that are not necessarily held in a
+// store (e.g. so that they may be checked against a store's).
+type Summarizer struct {
+	keys    int
+	digests []string
+}
+
+// Add includes a key and its values in the Summary.
+func (o *Summarizer) Add(key string, vals []string) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:%s%s", len(key), key, versionOf(vals))
+
+	o.keys++
+	o.digests = append(o.digests, hex.EncodeToString(h.Sum(nil)))
+}
+
+// Summary returns the Summary of the keys added so far.
+func (o Summarizer) Summary() Summary {
+	// backends may order keys differently (e.g. according to the collation
+	// of a SQL database), so they are sorted by their digests instead
+	sort.Strings(o.digests)
+
+	h := sha256.New()
+	for _, d := range o.digests {
+		fmt.Fprint(h, d)
+	}
+
+	return Summary{Keys: o.keys, Digest: hex.EncodeToString(h.Sum(nil))}
+}
+
+// Dump writes all keys in the store, along with their values, to w as a
+// stream of JSON-encoded Records, one per line. It returns the Summary of the
+// dumped contents.
+func Dump(s IKVStore, w io.Writer) (Summary, error) {
+	enc := json.NewEncoder(w)
+
+	return walk(s, func(rec Record) error {
+		return enc.Encode(rec)
+	})
+}
+
+// Summarize returns the Summary of the contents of the store.
+func Summarize(s IKVStore) (Summary, error) {
+	return walk(s, func(Record) error { return nil })
+}
+
+// Load adds the Records read from r (as written by Dump) to the store. None
+// of the keys may already be in the store. It returns the Summary of the
+// loaded contents.
+func Load(s IKVStore, r io.Reader) (Summary, error) {
+	var sum Summarizer
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var rec Record
+
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return Summary{}, fmt.Errorf("record %d: %w", sum.keys+1, err)
+		}
+
+		if len(rec.Values) == 0 {
+			return Summary{}, fmt.Errorf("record %d: no values for %q", sum.keys+1, rec.Key)
+		}
+
+		if err := s.CompareAndSwap(rec.Key, NoVersion, rec.Values); err != nil {
+			if errors.Is(err, ErrVersionMismatch) {
+				return Summary{}, fmt.Errorf("%q is already in the store", rec.Key)
+			}
+
+			return Summary{}, fmt.Errorf("%q: %w", rec.Key, err)
+		}
+
+		sum.Add(rec.Key, rec.Values)
+	}
+
+	return sum.Summary(), nil
+}
+
+func walk(s IKVStore, fn func(Record) error) (Summary, error) {
+	var (
+		sum    Summarizer
+		cursor string
+	)
+
+	for {
+		keys, next, err := s.ScanKeys("", cursor, DumpPageSize)
+		if err != nil {
+			return Summary{}, err
+		}
+
+		for _, key := range keys {
+			vals, err := s.Get(key)
+			if err != nil {
+				if errors.Is(err, ErrKeyNotFound) {
+					continue // removed since it was scanned
+				}
+
+				return Summary{}, err
+			}
+
+			if err := fn(Record{Key: key, Values: vals}); err != nil {
+				return Summary{}, err
+			}
+
+			sum.Add(key, vals)
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	return sum.Summary(), nil
+}
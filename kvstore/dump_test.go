@@ -0,0 +1,127 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package kvstore
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func newPopulatedMemory(t *testing.T, numKeys int) *Memory {
+	s := &Memory{}
+	require.NoError(t, s.Init(nil, log.Named("test")))
+
+	for i := 0; i < numKeys; i++ {
+		key := fmt.Sprintf("0:PSA:key-%02d", i)
+		require.NoError(t, s.Add(key, fmt.Sprintf(`{"n": %d}`, i)))
+		require.NoError(t, s.Add(key, `"second"`))
+	}
+
+	return s
+}
+
+func TestDump_Load_ok(t *testing.T) {
+	defer func(old int) { DumpPageSize = old }(DumpPageSize)
+	DumpPageSize = 3
+
+	src := newPopulatedMemory(t, 10)
+
+	var buf bytes.Buffer
+	dumped, err := Dump(src, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 10, dumped.Keys)
+	assert.Equal(t, 10, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(),
+		`{"key":"0:PSA:key-03","values":["{\"n\": 3}","\"second\""]}`)
+
+	dst := newBBolt(t)
+
+	loaded, err := Load(dst, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, dumped, loaded)
+
+	summary, err := Summarize(dst)
+	require.NoError(t, err)
+	assert.Equal(t, dumped, summary)
+
+	vals, err := dst.Get("0:PSA:key-03")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"n": 3}`, `"second"`}, vals)
+}
+
+func TestDump_empty_store(t *testing.T) {
+	var buf bytes.Buffer
+
+	summary, err := Dump(newPopulatedMemory(t, 0), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.Keys)
+	assert.Empty(t, buf.String())
+}
+
+func TestSummarize_differences(t *testing.T) {
+	base, err := Summarize(newPopulatedMemory(t, 3))
+	require.NoError(t, err)
+
+	s := newPopulatedMemory(t, 3)
+	require.NoError(t, s.Add("0:PSA:key-01", `"third"`))
+
+	changed, err := Summarize(s)
+	require.NoError(t, err)
+	assert.Equal(t, base.Keys, changed.Keys)
+	assert.NotEqual(t, base.Digest, changed.Digest)
+
+	// the order of values does not matter
+	s = newPopulatedMemory(t, 3)
+	s.Data["0:PSA:key-01"] = []string{`"second"`, `{"n": 1}`}
+
+	reordered, err := Summarize(s)
+	require.NoError(t, err)
+	assert.Equal(t, base, reordered)
+}
+
+func TestLoad_existing_key(t *testing.T) {
+	var buf bytes.Buffer
+
+	_, err := Dump(newPopulatedMemory(t, 3), &buf)
+	require.NoError(t, err)
+
+	_, err = Load(newPopulatedMemory(t, 1), &buf)
+	assert.EqualError(t, err, `"0:PSA:key-00" is already in the store`)
+}
+
+func TestLoad_bad_records(t *testing.T) {
+	tvs := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "bad JSON",
+			input:    `{"key": "a", "values": ["1"]}` + "\n{",
+			expected: "record 2: unexpected EOF",
+		},
+		{
+			desc:     "no values",
+			input:    `{"key": "a", "values": []}`,
+			expected: `record 1: no values for "a"`,
+		},
+		{
+			desc:     "no key",
+			input:    `{"values": ["1"]}`,
+			expected: `"": the supplied key is empty`,
+		},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.desc, func(t *testing.T) {
+			_, err := Load(newPopulatedMemory(t, 0), strings.NewReader(tv.input))
+			assert.EqualError(t, err, tv.expected)
+		})
+	}
+}
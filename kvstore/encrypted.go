@@ -0,0 +1,355 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package kvstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	"github.com/veraison/services/vts/earsigner"
+	"go.uber.org/zap"
+)
+
+const dataKeySize = 32
+
+// maxAddAttempts is the number of times Encrypted.Add attempts to append a
+// value to a key that is being concurrently updated.
+const maxAddAttempts = 10
+
+type encryptionConfig struct {
+	Keys      map[string]string `mapstructure:"keys"`
+	ActiveKey string            `mapstructure:"active-key" config:"zerodefault"`
+}
+
+func (o encryptionConfig) Validate() error {
+	if len(o.Keys) == 0 {
+		return errors.New("no keys specified")
+	}
+
+	if o.ActiveKey == "" {
+		if len(o.Keys) > 1 {
+			return errors.New("active-key must be specified when there are multiple keys")
+		}
+
+		return nil
+	}
+
+	if _, ok := o.Keys[o.ActiveKey]; !ok {
+		return fmt.Errorf("active-key %q is not in keys", o.ActiveKey)
+	}
+
+	return nil
+}
+
+// envelope is the stored form of an encrypted value. The value is sealed with
+// a random data encryption key, which is in turn sealed with the key
+// encryption key identified by KeyID. Both are bound to the store key they
+// are associated with, so that they cannot be moved to another key.
+type envelope struct {
+	KeyID   string `json:"kid"`
+	DataKey []byte `json:"dek"`
+	Data    []byte `json:"data"`
+}
+
+// Encrypted wraps an IKVStore, transparently encrypting values before they are
+// written to it, and decrypting them after they are read from it, using
+// AES-GCM envelope encryption. Only values are encrypted; the keys they are
+// associated with are stored as-is.
+type Encrypted struct {
+	Store IKVStore
+
+	// Keys are the key encryption keys, indexed by their IDs. Values
+	// encrypted with any of these keys can be decrypted, but new values are
+	// only ever encrypted with the one identified by ActiveKey.
+	Keys      map[string]cipher.AEAD
+	ActiveKey string
+
+	logger *zap.SugaredLogger
+}
+
+// Init initializes the KVStore. In addition to the configuration of the
+// wrapped Store, the config must contain the following values:
+// "encryption.keys" - A map of key IDs onto the URLs from which the
+//
+//	corresponding keys are loaded. The URL schemes are the same as for the
+//	EAR signing key (e.g. "file:" and "aws:"). Each key must be a base64-encoded
+//	AES key (16, 24 or 32 bytes long).
+//
+// "encryption.active-key" (optional) - The ID of the key used to encrypt new
+//
+//	values. May be omitted if there is only one key.
+func (o *Encrypted) Init(v *viper.Viper, logger *zap.SugaredLogger) error {
+	if o.Store == nil {
+		return errors.New("encrypted store has no underlying store")
+	}
+
+	o.logger = logger
+
+	var cfg encryptionConfig
+
+	loader := config.NewLoader(&cfg)
+	if err := loader.LoadFromViper(v.Sub("encryption")); err != nil {
+		return fmt.Errorf("encryption: %w", err)
+	}
+
+	keyLoader := earsigner.NewKeyLoader(afero.NewOsFs())
+
+	o.Keys = make(map[string]cipher.AEAD, len(cfg.Keys))
+	for id, location := range cfg.Keys {
+		aead, err := loadKeyEncryptionKey(keyLoader, location)
+		if err != nil {
+			return fmt.Errorf("encryption: key %q: %w", id, err)
+		}
+
+		o.Keys[id] = aead
+	}
+
+	o.ActiveKey = cfg.ActiveKey
+	if o.ActiveKey == "" {
+		// there is exactly one key (see encryptionConfig.Validate())
+		for id := range cfg.Keys {
+			o.ActiveKey = id
+		}
+	}
+
+	o.logger.Infow("encryption enabled", "active-key", o.ActiveKey)
+
+	return o.Store.Init(v, logger)
+}
+
+func (o *Encrypted) Close() error {
+	return o.Store.Close()
+}
+
+func (o *Encrypted) Setup() error {
+	return o.Store.Setup()
+}
+
+func (o Encrypted) Get(key string) ([]string, error) {
+	vals, _, err := o.GetVersioned(key)
+	return vals, err
+}
+
+func (o Encrypted) GetVersioned(key string) ([]string, Version, error) {
+	sealed, version, err := o.Store.GetVersioned(key)
+	if err != nil {
+		return nil, NoVersion, err
+	}
+
+	vals := make([]string, len(sealed))
+	for i, s := range sealed {
+		if vals[i], err = o.open(key, s); err != nil {
+			return nil, NoVersion, err
+		}
+	}
+
+	// the version is that of the ciphertexts, which is what CompareAndSwap
+	// will be checking against
+	return vals, version, nil
+}
+
+func (o Encrypted) GetKeys() ([]string, error) {
+	return o.Store.GetKeys()
+}
+
+func (o Encrypted) ScanKeys(prefix, cursor string, limit int) ([]string, string, error) {
+	return o.Store.ScanKeys(prefix, cursor, limit)
+}
+
+func (o Encrypted) Set(key string, val string) error {
+	if err := sanitizeKV(key, val); err != nil {
+		return err
+	}
+
+	sealed, err := o.seal(key, val)
+	if err != nil {
+		return err
+	}
+
+	return o.Store.Set(key, sealed)
+}
+
+func (o Encrypted) Del(key string) error {
+	return o.Store.Del(key)
+}
+
+// Add the specified value to the specified key, unless it is already
+// associated with it. As each encryption of a value results in a different
+// ciphertext, the existing values are decrypted in order to be compared with
+// the new one, which is then appended using CompareAndSwap, so that
+// concurrent additions of the same value are not duplicated.
+func (o Encrypted) Add(key string, val string) error {
+	if err := sanitizeKV(key, val); err != nil {
+		return err
+	}
+
+	for i := 0; i < maxAddAttempts; i++ {
+		sealed, version, err := o.Store.GetVersioned(key)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return err
+		}
+
+		for _, s := range sealed {
+			existing, err := o.open(key, s)
+			if err != nil {
+				return err
+			}
+
+			if existing == val {
+				return nil
+			}
+		}
+
+		newSealed, err := o.seal(key, val)
+		if err != nil {
+			return err
+		}
+
+		err = o.Store.CompareAndSwap(key, version, append(sealed, newSealed))
+		if !errors.Is(err, ErrVersionMismatch) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: could not add to %q due to concurrent updates",
+		ErrVersionMismatch, key)
+}
+
+func (o Encrypted) CompareAndSwap(key string, version Version, vals []string) error {
+	if err := sanitizeKVs(key, vals); err != nil {
+		return err
+	}
+
+	sealed := make([]string, len(vals))
+	for i, val := range vals {
+		var err error
+		if sealed[i], err = o.seal(key, val); err != nil {
+			return err
+		}
+	}
+
+	return o.Store.CompareAndSwap(key, version, sealed)
+}
+
+func (o Encrypted) seal(key, val string) (string, error) {
+	kek, ok := o.Keys[o.ActiveKey]
+	if !ok {
+		return "", errors.New("encrypted store uninitialized")
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	env := envelope{KeyID: o.ActiveKey}
+
+	if env.DataKey, err = sealWithNonce(kek, dataKey, []byte(key)); err != nil {
+		return "", err
+	}
+
+	if env.Data, err = sealWithNonce(dek, []byte(val), []byte(key)); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func (o Encrypted) open(key, sealed string) (string, error) {
+	var env envelope
+
+	if err := json.Unmarshal([]byte(sealed), &env); err != nil || env.KeyID == "" {
+		return "", fmt.Errorf("value of %q is not encrypted", key)
+	}
+
+	kek, ok := o.Keys[env.KeyID]
+	if !ok {
+		return "", fmt.Errorf("value of %q is encrypted with unknown key %q", key, env.KeyID)
+	}
+
+	dataKey, err := openWithNonce(kek, env.DataKey, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("decrypting data key for %q: %w", key, err)
+	}
+
+	dek, err := newAEAD(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("decrypting data key for %q: %w", key, err)
+	}
+
+	val, err := openWithNonce(dek, env.Data, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("decrypting value of %q: %w", key, err)
+	}
+
+	return string(val), nil
+}
+
+func loadKeyEncryptionKey(loader *earsigner.KeyLoader, location string) (cipher.AEAD, error) {
+	keyURL, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key location %q: %w", location, err)
+	}
+
+	encoded, err := loader.Load(keyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("key is not base64-encoded: %w", err)
+	}
+
+	return newAEAD(key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealWithNonce encrypts plaintext with a random nonce, which is prepended to
+// the returned ciphertext.
+func sealWithNonce(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openWithNonce(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,261 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package kvstore
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+// writeTestKey writes a new random base64-encoded key of the specified size
+// to a file inside dir, and returns its location.
+func writeTestKey(t *testing.T, dir string, size int) string {
+	key := make([]byte, size)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	f, err := os.CreateTemp(dir, "key-*.b64")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+
+	return "file:" + f.Name()
+}
+
+func newEncryptedConfig(keys map[string]string, activeKey string) *viper.Viper {
+	cfg := viper.New()
+	cfg.Set("encryption.keys", keys)
+	if activeKey != "" {
+		cfg.Set("encryption.active-key", activeKey)
+	}
+
+	return cfg
+}
+
+func newEncrypted(t *testing.T, inner IKVStore, keys map[string]string, activeKey string) *Encrypted {
+	s := Encrypted{Store: inner}
+
+	err := s.Init(newEncryptedConfig(keys, activeKey), log.Named("test"))
+	require.NoError(t, err)
+
+	return &s
+}
+
+func TestEncrypted_Init_bad_config(t *testing.T) {
+	dir := t.TempDir()
+	k1 := writeTestKey(t, dir, 16)
+	k2 := writeTestKey(t, dir, 32)
+
+	badKey := path.Join(dir, "bad.key")
+	require.NoError(t, os.WriteFile(badKey, []byte("not base64!"), 0600))
+
+	shortKey := path.Join(dir, "short.key")
+	require.NoError(t, os.WriteFile(shortKey, []byte("c2hvcnQ="), 0600))
+
+	tvs := []struct {
+		desc      string
+		keys      map[string]string
+		activeKey string
+		expected  string
+	}{
+		{
+			desc:     "no keys",
+			expected: "encryption: nil configuration",
+		},
+		{
+			desc:     "no active key",
+			keys:     map[string]string{"k1": k1, "k2": k2},
+			expected: "encryption: active-key must be specified when there are multiple keys",
+		},
+		{
+			desc:      "unknown active key",
+			keys:      map[string]string{"k1": k1},
+			activeKey: "k2",
+			expected:  `encryption: active-key "k2" is not in keys`,
+		},
+		{
+			desc:     "not base64",
+			keys:     map[string]string{"k1": "file:" + badKey},
+			expected: `encryption: key "k1": key is not base64-encoded`,
+		},
+		{
+			desc:     "bad key size",
+			keys:     map[string]string{"k1": "file:" + shortKey},
+			expected: `encryption: key "k1": crypto/aes: invalid key size 5`,
+		},
+		{
+			desc:     "missing key",
+			keys:     map[string]string{"k1": "file:" + path.Join(dir, "missing.key")},
+			expected: `encryption: key "k1": loading signing key from`,
+		},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.desc, func(t *testing.T) {
+			s := Encrypted{Store: &Memory{}}
+
+			cfg := viper.New()
+			if tv.keys != nil {
+				cfg = newEncryptedConfig(tv.keys, tv.activeKey)
+			}
+
+			err := s.Init(cfg, log.Named("test"))
+			assert.ErrorContains(t, err, tv.expected)
+		})
+	}
+}
+
+func TestEncrypted_Set_Get_Add_ok(t *testing.T) {
+	inner := &Memory{}
+	s := newEncrypted(t, inner, map[string]string{"k1": writeTestKey(t, t.TempDir(), 32)}, "")
+
+	require.NoError(t, s.Set("key", `{"secret": "one"}`))
+	require.NoError(t, s.Add("key", `{"secret": "two"}`))
+
+	vals, err := s.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"secret": "one"}`, `{"secret": "two"}`}, vals)
+
+	sealed, err := inner.Get("key")
+	require.NoError(t, err)
+	require.Len(t, sealed, 2)
+	for _, v := range sealed {
+		assert.NotContains(t, v, "secret")
+		assert.Contains(t, v, `"kid":"k1"`)
+	}
+
+	keys, err := s.GetKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key"}, keys)
+
+	require.NoError(t, s.Del("key"))
+	_, err = s.Get("key")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestEncrypted_Add_duplicate(t *testing.T) {
+	inner := &Memory{}
+	s := newEncrypted(t, inner, map[string]string{"k1": writeTestKey(t, t.TempDir(), 32)}, "")
+
+	require.NoError(t, s.Add("key", `"one"`))
+	require.NoError(t, s.Add("key", `"two"`))
+	require.NoError(t, s.Add("key", `"one"`))
+
+	vals, err := s.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"one"`, `"two"`}, vals)
+
+	sealed, err := inner.Get("key")
+	require.NoError(t, err)
+	assert.Len(t, sealed, 2)
+}
+
+func TestEncrypted_Add_concurrent(t *testing.T) {
+	s := newEncrypted(t, &Memory{}, map[string]string{"k1": writeTestKey(t, t.TempDir(), 32)}, "")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.Add("key", `"val"`))
+		}()
+	}
+	wg.Wait()
+
+	vals, err := s.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"val"`}, vals)
+}
+
+func TestEncrypted_Set_bad_json(t *testing.T) {
+	s := newEncrypted(t, &Memory{}, map[string]string{"k1": writeTestKey(t, t.TempDir(), 32)}, "")
+
+	err := s.Set("key", "[")
+	assert.ErrorContains(t, err, "the supplied val contains invalid JSON")
+}
+
+func TestEncrypted_CompareAndSwap(t *testing.T) {
+	s := newEncrypted(t, &Memory{}, map[string]string{"k1": writeTestKey(t, t.TempDir(), 32)}, "")
+
+	require.NoError(t, s.CompareAndSwap("key", NoVersion, []string{`"v1"`}))
+
+	vals, version, err := s.GetVersioned("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"v1"`}, vals)
+
+	require.NoError(t, s.CompareAndSwap("key", version, []string{`"v2"`}))
+
+	err = s.CompareAndSwap("key", version, []string{`"v3"`})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	vals, err = s.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"v2"`}, vals)
+}
+
+func TestEncrypted_key_rotation(t *testing.T) {
+	dir := t.TempDir()
+	k1 := writeTestKey(t, dir, 16)
+	k2 := writeTestKey(t, dir, 32)
+
+	inner := &Memory{}
+	old := newEncrypted(t, inner, map[string]string{"k1": k1}, "")
+	require.NoError(t, old.Set("key", `"old"`))
+
+	// the store is re-configured with a new active key, retaining the old
+	// one so that existing values remain readable
+	s := newEncrypted(t, &Memory{}, map[string]string{"k1": k1, "k2": k2}, "k2")
+	s.Store = inner
+
+	require.NoError(t, s.Add("key", `"new"`))
+
+	vals, err := s.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"old"`, `"new"`}, vals)
+
+	sealed, err := inner.Get("key")
+	require.NoError(t, err)
+	assert.Contains(t, sealed[0], `"kid":"k1"`)
+	assert.Contains(t, sealed[1], `"kid":"k2"`)
+
+	// once the old key is retired, values encrypted with it can no longer
+	// be read
+	retired := newEncrypted(t, &Memory{}, map[string]string{"k2": k2}, "")
+	retired.Store = inner
+
+	_, err = retired.Get("key")
+	assert.EqualError(t, err, `value of "key" is encrypted with unknown key "k1"`)
+}
+
+func TestEncrypted_Get_tampered(t *testing.T) {
+	inner := &Memory{}
+	s := newEncrypted(t, inner, map[string]string{"k1": writeTestKey(t, t.TempDir(), 32)}, "")
+
+	require.NoError(t, s.Set("key1", `"secret"`))
+
+	sealed, err := inner.Get("key1")
+	require.NoError(t, err)
+
+	// a value moved to another key must not be decrypted
+	require.NoError(t, inner.Set("key2", sealed[0]))
+	_, err = s.Get("key2")
+	assert.ErrorContains(t, err, `decrypting data key for "key2"`)
+
+	// neither must a value that was not encrypted
+	require.NoError(t, inner.Set("key3", `{"plain": "text"}`))
+	_, err = s.Get("key3")
+	assert.EqualError(t, err, `value of "key3" is not encrypted`)
+}
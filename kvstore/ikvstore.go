@@ -1,4 +1,4 @@
-// Copyright 2021-2023 Contributors to the Veraison project.
+// Copyright 2021-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package kvstore
 
@@ -33,6 +33,15 @@ type IKVStore interface {
 	// GetKeys returns a []string of keys currently set in the store.
 	GetKeys() ([]string, error)
 
+	// ScanKeys returns a page of up to limit keys starting with the
+	// specified prefix, in ascending order, that follow the specified
+	// cursor (or from the first such key, if the cursor is empty). The
+	// returned cursor may be passed to a subsequent call to retrieve the
+	// next page; it is empty if there are no further keys. A page may
+	// contain fewer than limit keys even if the cursor is not empty. If
+	// limit is not positive, all remaining keys are returned.
+	ScanKeys(prefix, cursor string, limit int) ([]string, string, error)
+
 	// Set the specified key to the specified value, discarding any
 	// existing values.
 	Set(key, val string) error
@@ -46,4 +55,17 @@ type IKVStore interface {
 	// not already exist, this behaves like Set. If the key exists, the
 	// specified val is appended to the existing value(s).
 	Add(key, val string) error
+
+	// GetVersioned returns a []string of values for the specified key,
+	// along with their Version. If the specified key is not in the store,
+	// a ErrKeyNotFound is returned.
+	GetVersioned(key string) ([]string, Version, error)
+
+	// CompareAndSwap atomically replaces the values of the specified key
+	// with vals, provided that its values are still at the specified
+	// version (as returned by GetVersioned). NoVersion specifies that the
+	// key must not be in the store. If the version does not match,
+	// ErrVersionMismatch is returned. If vals is empty, the key is
+	// removed from the store.
+	CompareAndSwap(key string, version Version, vals []string) error
 }
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package kvstore
 
@@ -19,8 +19,11 @@ type cfg struct {
 
 func (o cfg) Validate() error {
 	supportedBackends := map[string]bool{
+		"bbolt":  true,
 		"memory": true,
 		"sql":    true,
+		// not a backend, but configures the Encrypted wrapper
+		"encryption": true,
 	}
 
 	var unexpected []string
@@ -49,6 +52,8 @@ func New(v *viper.Viper, logger *zap.SugaredLogger) (IKVStore, error) {
 	var s IKVStore
 
 	switch cfg.Backend {
+	case "bbolt":
+		s = &BBolt{}
 	case "memory":
 		s = &Memory{}
 	case "sql":
@@ -57,6 +62,10 @@ func New(v *viper.Viper, logger *zap.SugaredLogger) (IKVStore, error) {
 		return nil, fmt.Errorf("backend %q is not supported", cfg.Backend)
 	}
 
+	if _, ok := cfg.BackendConfigs["encryption"]; ok {
+		s = &Encrypted{Store: s}
+	}
+
 	if err := s.Init(v, logger); err != nil {
 		return nil, err
 	}
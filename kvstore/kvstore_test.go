@@ -1,8 +1,9 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package kvstore
 
 import (
+	"path"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -67,6 +68,32 @@ func TestKVStore_New_memory_backend_ok(t *testing.T) {
 	assert.IsType(t, &Memory{}, m)
 }
 
+func TestKVStore_New_bbolt_backend_ok(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("backend", "bbolt")
+	cfg.Set("bbolt.path", path.Join(t.TempDir(), "store.db"))
+
+	m, err := New(cfg, log.Named("test"))
+
+	assert.NoError(t, err)
+	assert.IsType(t, &BBolt{}, m)
+	assert.NoError(t, m.Close())
+}
+
+func TestKVStore_New_encrypted_memory_backend_ok(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("backend", "memory")
+	cfg.Set("encryption.keys", map[string]string{
+		"k1": writeTestKey(t, t.TempDir(), 32),
+	})
+
+	m, err := New(cfg, log.Named("test"))
+
+	assert.NoError(t, err)
+	assert.IsType(t, &Encrypted{}, m)
+	assert.IsType(t, &Memory{}, m.(*Encrypted).Store)
+}
+
 func TestKVStore_New_SQL_backend_failed_init(t *testing.T) {
 	cfg := viper.New()
 	cfg.Set("backend", "sql")
@@ -1,4 +1,4 @@
-// Copyright 2021-2023 Contributors to the Veraison project.
+// Copyright 2021-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package kvstore
 
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"text/tabwriter"
 
@@ -79,6 +80,28 @@ func (o Memory) GetKeys() ([]string, error) {
 	return keys, nil
 }
 
+func (o Memory) ScanKeys(prefix, cursor string, limit int) ([]string, string, error) {
+	if o.Data == nil {
+		return nil, "", errors.New("memory store uninitialized")
+	}
+
+	lk.RLock()
+	defer lk.RUnlock()
+
+	var keys []string // nolint:prealloc
+	for k := range o.Data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	keys, next := pageKeys(keys, cursor, limit)
+
+	return keys, next, nil
+}
+
 func (o *Memory) Add(key string, val string) error {
 	if o.Data == nil {
 		return errors.New("memory store uninitialized")
@@ -145,6 +168,51 @@ func (o *Memory) Del(key string) error {
 	return nil
 }
 
+func (o Memory) GetVersioned(key string) ([]string, Version, error) {
+	if o.Data == nil {
+		return nil, NoVersion, errors.New("memory store uninitialized")
+	}
+
+	if err := sanitizeK(key); err != nil {
+		return nil, NoVersion, err
+	}
+
+	lk.RLock()
+	defer lk.RUnlock()
+
+	vals, ok := o.Data[key]
+	if !ok {
+		return nil, NoVersion, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+
+	return vals, versionOf(vals), nil
+}
+
+func (o *Memory) CompareAndSwap(key string, version Version, vals []string) error {
+	if o.Data == nil {
+		return errors.New("memory store uninitialized")
+	}
+
+	if err := sanitizeKVs(key, vals); err != nil {
+		return err
+	}
+
+	lk.Lock()
+	defer lk.Unlock()
+
+	if current := versionOf(o.Data[key]); current != version {
+		return fmt.Errorf("%w: %q", ErrVersionMismatch, key)
+	}
+
+	if len(vals) == 0 {
+		delete(o.Data, key)
+	} else {
+		o.Data[key] = append([]string(nil), vals...)
+	}
+
+	return nil
+}
+
 func (o Memory) dump() string {
 	var b bytes.Buffer
 
@@ -1,4 +1,4 @@
-// Copyright 2021-2023 Contributors to the Veraison project.
+// Copyright 2021-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package kvstore
 
@@ -225,3 +225,64 @@ psa://tenant-2/cafecafe/cafecafe [[1, 2, 3]]
 	assert.Equal(t, expectedTbl, tbl)
 
 }
+
+func TestMemory_CompareAndSwap(t *testing.T) {
+	s := Memory{}
+
+	err := s.Init(nil, log.Named("test"))
+	require.NoError(t, err)
+
+	err = s.CompareAndSwap(testKey, NoVersion, []string{testVal})
+	require.NoError(t, err)
+
+	err = s.CompareAndSwap(testKey, NoVersion, []string{altTestVal})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	vals, version, err := s.GetVersioned(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testVal}, vals)
+	assert.NotEqual(t, NoVersion, version)
+
+	err = s.CompareAndSwap(testKey, version, []string{testVal, altTestVal})
+	require.NoError(t, err)
+
+	// the version is stale following the swap
+	err = s.CompareAndSwap(testKey, version, []string{altTestVal})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	vals, version, err = s.GetVersioned(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testVal, altTestVal}, vals)
+
+	err = s.CompareAndSwap(testKey, version, nil)
+	require.NoError(t, err)
+
+	_, _, err = s.GetVersioned(testKey)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestMemory_ScanKeys(t *testing.T) {
+	s := Memory{}
+
+	err := s.Init(nil, log.Named("test"))
+	require.NoError(t, err)
+
+	for _, k := range []string{"a:3", "a:1", "b:1", "a:2", "ab:1"} {
+		require.NoError(t, s.Set(k, testVal))
+	}
+
+	keys, next, err := s.ScanKeys("a:", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "a:2"}, keys)
+	assert.Equal(t, "a:2", next)
+
+	keys, next, err = s.ScanKeys("a:", next, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:3"}, keys)
+	assert.Equal(t, "", next)
+
+	keys, next, err = s.ScanKeys("", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "a:2", "a:3", "ab:1", "b:1"}, keys)
+	assert.Equal(t, "", next)
+}
@@ -3,18 +3,21 @@
 package kvstore
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/spf13/viper"
 	"github.com/veraison/services/config"
 	"go.uber.org/zap"
 
 	// drivers
-	_ "github.com/go-sql-driver/mysql" // mysql
 	_ "github.com/jackc/pgx/v5/stdlib" // pgx
 )
 
@@ -25,6 +28,7 @@ var (
 
 var (
 	safeTblNameRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	likeEscaper   = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
 )
 
 func isSafeTblName(s string) bool {
@@ -133,6 +137,24 @@ func (o SQL) Get(key string) ([]string, error) {
 		return nil, err
 	}
 
+	return o.getVals(o.DB, key)
+}
+
+func (o SQL) GetVersioned(key string) ([]string, Version, error) {
+	vals, err := o.Get(key)
+	if err != nil {
+		return nil, NoVersion, err
+	}
+
+	return vals, versionOf(vals), nil
+}
+
+// queryer is implemented by both sql.DB and sql.Tx.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func (o SQL) getVals(q queryer, key string) ([]string, error) {
 	query := sq.Select("kv_val").Distinct().
 		From(o.TableName).
 		Where(sq.Eq{"kv_key": key}).
@@ -143,7 +165,7 @@ func (o SQL) Get(key string) ([]string, error) {
 		panic(err)
 	}
 
-	rows, err := o.DB.Query(queryText, args...)
+	rows, err := q.Query(queryText, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +190,10 @@ func (o SQL) Get(key string) ([]string, error) {
 		kvVal = append(kvVal, s.String)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	if count == 0 {
 		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
 	}
@@ -212,6 +238,85 @@ func (o SQL) GetKeys() ([]string, error) {
 	return keys, nil
 }
 
+func (o SQL) ScanKeys(prefix, cursor string, limit int) ([]string, string, error) {
+	if o.DB == nil {
+		return nil, "", errors.New("SQL store uninitialized")
+	}
+
+	query := sq.Select("kv_key").Distinct().
+		From(o.TableName).
+		OrderBy("kv_key").
+		PlaceholderFormat(o.Placeholder)
+
+	if prefix != "" {
+		query = query.Where(sq.Expr("kv_key LIKE ? ESCAPE '!'", escapeLike(prefix)+"%"))
+	}
+
+	if cursor != "" {
+		query = query.Where(sq.Gt{"kv_key": cursor})
+	}
+
+	if limit > 0 {
+		// fetch an extra key to find out whether there is a next page
+		query = query.Limit(uint64(limit) + 1)
+	}
+
+	queryText, args, err := query.ToSql()
+	if err != nil {
+		panic(err)
+	}
+
+	rows, err := o.DB.Query(queryText, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer rows.Close()
+
+	var scanned []string
+
+	for rows.Next() {
+		var s sql.NullString
+
+		if err := rows.Scan(&s); err != nil {
+			return nil, "", err
+		}
+
+		if !s.Valid {
+			panic("broken invariant: found key with null string")
+		}
+
+		scanned = append(scanned, s.String)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if limit > 0 && len(scanned) > limit {
+		scanned = scanned[:limit]
+		next = scanned[limit-1]
+	}
+
+	// depending on the DBMS and collation, LIKE may be case-insensitive, so
+	// the prefix must be checked again
+	keys := make([]string, 0, len(scanned))
+	for _, k := range scanned {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, next, nil
+}
+
+// escapeLike escapes the wildcards in s so that it can be used as a literal in
+// a LIKE pattern with '!' as the escape character.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 func (o SQL) Add(key string, val string) error {
 	if o.DB == nil {
 		return errors.New("SQL store uninitialized")
@@ -262,7 +367,7 @@ func (o SQL) Set(key string, val string) error {
 		panic(err)
 	}
 
-	if _, err = o.DB.Exec(queryText, args...); err != nil {
+	if _, err = txn.Exec(queryText, args...); err != nil {
 		return err
 	}
 
@@ -275,13 +380,105 @@ func (o SQL) Set(key string, val string) error {
 		panic(err)
 	}
 
-	if _, err = o.DB.Exec(queryText, args...); err != nil {
+	if _, err = txn.Exec(queryText, args...); err != nil {
 		return err
 	}
 
 	return txn.Commit()
 }
 
+// CompareAndSwap replaces the values of the key inside a serializable
+// transaction, so that concurrent swaps of the same key cannot both succeed.
+// Depending on the DBMS, the losing transaction either fails the version
+// check, or is aborted with a serialization failure (PostgreSQL) or a
+// deadlock (MySQL); in either case, ErrVersionMismatch is returned, so that
+// callers may retry.
+func (o SQL) CompareAndSwap(key string, version Version, vals []string) error {
+	err := o.compareAndSwap(key, version, vals)
+	if isSerializationFailure(err) {
+		return fmt.Errorf("%w: %q: %v", ErrVersionMismatch, key, err)
+	}
+
+	return err
+}
+
+func (o SQL) compareAndSwap(key string, version Version, vals []string) error {
+	if o.DB == nil {
+		return errors.New("SQL store uninitialized")
+	}
+
+	if err := sanitizeKVs(key, vals); err != nil {
+		return err
+	}
+
+	txn, err := o.DB.BeginTx(context.Background(),
+		&sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = txn.Rollback() }()
+
+	current, err := o.getVals(txn, key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+
+	if versionOf(current) != version {
+		return fmt.Errorf("%w: %q", ErrVersionMismatch, key)
+	}
+
+	delQuery := sq.Delete(o.TableName).
+		Where(sq.Eq{"kv_key": key}).
+		PlaceholderFormat(o.Placeholder)
+
+	queryText, args, err := delQuery.ToSql()
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = txn.Exec(queryText, args...); err != nil {
+		return err
+	}
+
+	if len(vals) > 0 {
+		insQuery := sq.Insert(o.TableName).Columns("kv_key", "kv_val").
+			PlaceholderFormat(o.Placeholder)
+		for _, val := range vals {
+			insQuery = insQuery.Values(key, val)
+		}
+
+		queryText, args, err = insQuery.ToSql()
+		if err != nil {
+			panic(err)
+		}
+
+		if _, err = txn.Exec(queryText, args...); err != nil {
+			return err
+		}
+	}
+
+	return txn.Commit()
+}
+
+// isSerializationFailure returns true if the error is due to the DBMS having
+// aborted the transaction because of a conflict with a concurrent one.
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// serialization_failure, deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_LOCK_DEADLOCK
+		return mysqlErr.Number == 1213
+	}
+
+	return false
+}
+
 func (o SQL) Del(key string) error {
 	if o.DB == nil {
 		return errors.New("SQL store uninitialized")
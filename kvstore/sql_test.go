@@ -11,6 +11,8 @@ import (
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -518,3 +520,203 @@ func TestSQL_Setup(t *testing.T) {
 	err = s.Setup()
 	assert.ErrorContains(t, err, "table test already exists")
 }
+
+func TestSQL_CompareAndSwap_ok(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	s := SQL{TableName: "endorsement", DB: db, Placeholder: sq.Question}
+
+	rows := sqlmock.NewRows([]string{"kv_val"})
+	rows.AddRow(testVal)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT kv_val FROM endorsement WHERE kv_key = ?")).
+		WithArgs(testKey).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM endorsement WHERE kv_key = ?")).
+		WithArgs(testKey).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO endorsement (kv_key,kv_val) VALUES (?,?),(?,?)")).
+		WithArgs(testKey, testVal, testKey, altTestVal).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+
+	err = s.CompareAndSwap(testKey, versionOf([]string{testVal}), []string{testVal, altTestVal})
+	assert.NoError(t, err)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSQL_CompareAndSwap_version_mismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	s := SQL{TableName: "endorsement", DB: db, Placeholder: sq.Question}
+
+	rows := sqlmock.NewRows([]string{"kv_val"})
+	rows.AddRow(altTestVal)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT kv_val FROM endorsement WHERE kv_key = ?")).
+		WithArgs(testKey).
+		WillReturnRows(rows)
+	mock.ExpectRollback()
+
+	err = s.CompareAndSwap(testKey, versionOf([]string{testVal}), []string{testVal})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSQL_CompareAndSwap_conflict(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		execErr    error
+		commitErr  error
+		isMismatch bool
+	}{
+		{"postgres serialization failure", &pgconn.PgError{Code: "40001"}, nil, true},
+		{"postgres deadlock", nil, &pgconn.PgError{Code: "40P01"}, true},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, nil, true},
+		{"postgres other", &pgconn.PgError{Code: "23505"}, nil, false},
+		{"mysql other", nil, &mysql.MySQLError{Number: 1062}, false},
+		{"other", errors.New("boom"), nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			s := SQL{TableName: "endorsement", DB: db, Placeholder: sq.Question}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT kv_val FROM endorsement WHERE kv_key = ?")).
+				WithArgs(testKey).
+				WillReturnRows(sqlmock.NewRows([]string{"kv_val"}))
+
+			e := mock.ExpectExec(regexp.QuoteMeta("DELETE FROM endorsement WHERE kv_key = ?")).
+				WithArgs(testKey)
+			if tc.execErr != nil {
+				e.WillReturnError(tc.execErr)
+				mock.ExpectRollback()
+			} else {
+				e.WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO endorsement (kv_key,kv_val) VALUES (?,?)")).
+					WithArgs(testKey, testVal).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit().WillReturnError(tc.commitErr)
+			}
+
+			err = s.CompareAndSwap(testKey, NoVersion, []string{testVal})
+			require.Error(t, err)
+			assert.Equal(t, tc.isMismatch, errors.Is(err, ErrVersionMismatch), err)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestSQL_CompareAndSwap_sqlite(t *testing.T) {
+	storeFile := path.Join(t.TempDir(), "store.db")
+
+	cfg := viper.New()
+	cfg.Set("sql.driver", "sqlite3")
+	cfg.Set("sql.datasource", fmt.Sprintf("file:%s", storeFile))
+
+	s := SQL{}
+	err := s.Init(cfg, log.Named("test"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Setup())
+
+	err = s.CompareAndSwap(testKey, NoVersion, []string{testVal})
+	require.NoError(t, err)
+
+	err = s.CompareAndSwap(testKey, NoVersion, []string{altTestVal})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	vals, version, err := s.GetVersioned(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testVal}, vals)
+
+	err = s.CompareAndSwap(testKey, version, nil)
+	require.NoError(t, err)
+
+	_, err = s.Get(testKey)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestSQL_ScanKeys_ok(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"kv_key"})
+	rows.AddRow("a_b:2")
+	rows.AddRow("A_B:3") // matched by a case-insensitive LIKE
+	rows.AddRow("a_b:4")
+
+	e := mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT DISTINCT kv_key FROM endorsement WHERE kv_key LIKE ? ESCAPE '!' AND kv_key > ? ORDER BY kv_key LIMIT 3"))
+	e.WithArgs("a!_b:%", "a_b:1")
+	e.WillReturnRows(rows)
+
+	s := SQL{TableName: "endorsement", DB: db, Placeholder: sq.Question}
+
+	keys, next, err := s.ScanKeys("a_b:", "a_b:1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a_b:2"}, keys)
+	assert.Equal(t, "A_B:3", next)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSQL_ScanKeys_sqlite(t *testing.T) {
+	storeFile := path.Join(t.TempDir(), "store.db")
+
+	cfg := viper.New()
+	cfg.Set("sql.driver", "sqlite3")
+	cfg.Set("sql.datasource", fmt.Sprintf("file:%s", storeFile))
+
+	s := SQL{}
+	err := s.Init(cfg, log.Named("test"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Setup())
+
+	for _, k := range []string{"a_:3", "a_:1", "b:1", "a_:2", "ab:1", "A_:4"} {
+		require.NoError(t, s.Add(k, testVal))
+	}
+
+	var all []string
+	cursor := ""
+	for {
+		keys, next, err := s.ScanKeys("a_:", cursor, 2)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(keys), 2)
+
+		all = append(all, keys...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, []string{"a_:1", "a_:2", "a_:3"}, all)
+
+	keys, _, err := s.ScanKeys("", "", 0)
+	require.NoError(t, err)
+	assert.Len(t, keys, 6)
+}
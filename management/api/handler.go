@@ -9,38 +9,75 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/moogar0880/problems"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/services/auth"
 	"github.com/veraison/services/capability"
 	"github.com/veraison/services/config"
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/management"
 	"github.com/veraison/services/policy"
+	"github.com/veraison/services/tenant"
 	"go.uber.org/zap"
 )
 
 const (
-	RulesMediaType    = "application/vnd.veraison.policy.opa"
-	PolicyMediaType   = "application/vnd.veraison.policy+json"
-	PoliciesMediaType = "application/vnd.veraison.policies+json"
+	RulesMediaType              = "application/vnd.veraison.policy.opa"
+	RegoV1RulesMediaType        = "application/vnd.veraison.policy.opa-v1"
+	OPABundleMediaType          = "application/vnd.veraison.policy.opa-bundle+gzip"
+	CELRulesMediaType           = "application/vnd.veraison.policy.cel"
+	PolicyMediaType             = "application/vnd.veraison.policy+json"
+	PoliciesMediaType           = "application/vnd.veraison.policies+json"
+	DiffMediaType               = "text/x-diff"
+	PolicyDataMediaType         = "application/vnd.veraison.policy-data+json"
+	PolicyChainMediaType        = "application/vnd.veraison.policy-chain+json"
+	DecisionsMediaType          = "application/vnd.veraison.policy-decisions+json"
+	TrustAnchorsMediaType       = "application/vnd.veraison.trust-anchors+json"
+	ReferenceValuesMediaType    = "application/vnd.veraison.reference-values+json"
+	EndorsementOriginsMediaType = "application/vnd.veraison.endorsement-origins+json"
+	TenantMediaType             = "application/vnd.veraison.tenant+json"
+	TenantsMediaType            = "application/vnd.veraison.tenants+json"
 )
 
 var (
-	tenantID = "0"
+	// defaultTenantID is the tenant requests act on if neither the
+	// principal nor the request specify one (see getTenantID).
+	defaultTenantID = "0"
+
+	// rulesMediaTypes maps the accepted policy rules media types onto the
+	// type of the policy engine used to evaluate them.
+	rulesMediaTypes = map[string]string{
+		RulesMediaType:       policy.OPAType,
+		RegoV1RulesMediaType: policy.OPAV1Type,
+		OPABundleMediaType:   policy.OPABundleType,
+		CELRulesMediaType:    policy.CELType,
+	}
 )
 
 type Handler struct {
-	Manager *management.PolicyManager
-	Logger  *zap.SugaredLogger
+	Manager      *management.PolicyManager
+	Endorsements *management.EndorsementManager
+	Tenants      *management.TenantManager
+	Logger       *zap.SugaredLogger
 }
 
-func NewHandler(manager *management.PolicyManager, logger *zap.SugaredLogger) Handler {
+func NewHandler(
+	manager *management.PolicyManager,
+	endorsements *management.EndorsementManager,
+	tenants *management.TenantManager,
+	logger *zap.SugaredLogger,
+) Handler {
 	return Handler{
-		Manager: manager,
-		Logger:  logger,
+		Manager:      manager,
+		Endorsements: endorsements,
+		Tenants:      tenants,
+		Logger:       logger,
 	}
 }
 
@@ -56,11 +93,13 @@ func (o Handler) CreatePolicy(c *gin.Context) {
 	}
 
 	mediaType := c.Request.Header.Get("Content-Type")
-	if mediaType != RulesMediaType {
+	policyType, ok := rulesMediaTypes[mediaType]
+	if !ok {
 		reportProblem(c,
 			http.StatusBadRequest,
-			fmt.Sprintf("the only supported rules format is %s",
-				RulesMediaType),
+			fmt.Sprintf("the only supported rules formats are %s, %s, %s and %s",
+				RulesMediaType, RegoV1RulesMediaType, OPABundleMediaType,
+				CELRulesMediaType),
 		)
 		return
 	}
@@ -79,6 +118,10 @@ func (o Handler) CreatePolicy(c *gin.Context) {
 		name = "default"
 	}
 
+	// the name of the policy within the scheme's policy chain; if not
+	// specified, the default policy is updated.
+	policyName := c.Query("policy")
+
 	payload, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("error reading body: %s", err))
@@ -90,23 +133,34 @@ func (o Handler) CreatePolicy(c *gin.Context) {
 		return
 	}
 
+	// bundles are binary archives, so they are stored base64-encoded
 	policyRules := string(payload)
+	if policyType == policy.OPABundleType {
+		policyRules = policy.EncodeOPABundle(payload)
+	}
 
-	if err = o.Manager.Validate(c, policyRules); err != nil {
+	if err = o.Manager.Validate(c, policyType, policyRules); err != nil {
 		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("invalid policy: %s", err))
+		return
 	}
 
-	policy, err := o.Manager.Update(c, tenantID, scheme, name, policyRules)
-	if err != nil {
+	policy, err := o.Manager.Update(c, requestTenantID(c), scheme, policyName, name, policyType,
+		policyRules, auth.GetPrincipal(c))
+	if errors.Is(err, management.ErrBadPolicyName) {
+		reportProblem(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
 		reportProblem(c,
 			http.StatusInternalServerError,
 			fmt.Sprintf("could not update policy: %s", err),
 		)
+		return
 	}
 
 	respBytes, err := json.Marshal(&policy)
 	if err != nil {
 		reportProblem(c, http.StatusInternalServerError, err.Error())
+		return
 	}
 
 	c.Data(http.StatusCreated, PolicyMediaType, respBytes)
@@ -132,7 +186,7 @@ func (o Handler) GetActivePolicy(c *gin.Context) {
 		return
 	}
 
-	pol, err := o.Manager.GetActive(c, tenantID, scheme)
+	pol, err := o.Manager.GetActive(c, requestTenantID(c), scheme, c.Query("policy"))
 	o.respondToGet(c, PolicyMediaType, pol, err)
 }
 
@@ -165,7 +219,7 @@ func (o Handler) GetPolicy(c *gin.Context) {
 		return
 	}
 
-	pol, err := o.Manager.GetPolicy(c, tenantID, scheme, uuid)
+	pol, err := o.Manager.GetPolicy(c, requestTenantID(c), scheme, c.Query("policy"), uuid)
 	o.respondToGet(c, PolicyMediaType, pol, err)
 }
 
@@ -189,10 +243,78 @@ func (o Handler) GetPolicies(c *gin.Context) {
 		return
 	}
 
-	policies, err := o.Manager.GetPolicies(c, tenantID, scheme, c.Query("name"))
+	// If all is true, the versions of all the tenant's policies for the
+	// scheme are returned, a page at a time, rather than those of a
+	// single policy.
+	all := false
+	if allParam := c.Query("all"); allParam != "" {
+		var err error
+		if all, err = strconv.ParseBool(allParam); err != nil {
+			reportProblem(c,
+				http.StatusBadRequest,
+				fmt.Sprintf("bad all %q: must be a boolean", allParam),
+			)
+			return
+		}
+	}
+
+	limitParam, cursor := c.Query("limit"), c.Query("cursor")
+
+	if !all {
+		if limitParam != "" || cursor != "" {
+			reportProblem(c,
+				http.StatusBadRequest,
+				"limit and cursor are only supported when all is true",
+			)
+			return
+		}
+
+		policies, err := o.Manager.GetPolicies(c, requestTenantID(c), scheme, c.Query("policy"),
+			c.Query("name"))
+		o.respondToGet(c, PoliciesMediaType, policies, err)
+		return
+	}
+
+	if c.Query("policy") != "" {
+		reportProblem(c,
+			http.StatusBadRequest,
+			"policy may not be specified when all is true",
+		)
+		return
+	}
+
+	limit := 0
+	if limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 {
+			reportProblem(c,
+				http.StatusBadRequest,
+				fmt.Sprintf("bad limit %q: must be a positive integer", limitParam),
+			)
+			return
+		}
+	}
+
+	policies, next, err := o.Manager.ListPolicies(c, requestTenantID(c), scheme, c.Query("name"),
+		cursor, limit)
+	if err == nil && next != "" {
+		c.Header("Link", nextPageLink(c, next))
+	}
 	o.respondToGet(c, PoliciesMediaType, policies, err)
 }
 
+// nextPageLink returns the value of the Link header (RFC 8288) pointing to the
+// page following the one requested by c, starting after the specified cursor.
+func nextPageLink(c *gin.Context, cursor string) string {
+	nextURL := *c.Request.URL
+
+	query := nextURL.Query()
+	query.Set("cursor", cursor)
+	nextURL.RawQuery = query.Encode()
+
+	return fmt.Sprintf("<%s>; rel=\"next\"", nextURL.RequestURI())
+}
+
 func (o Handler) Activate(c *gin.Context) {
 	scheme := c.Param("scheme")
 	if !o.Manager.IsSchemeSupported(scheme) {
@@ -212,10 +334,191 @@ func (o Handler) Activate(c *gin.Context) {
 		return
 	}
 
-	err = o.Manager.Activate(c, tenantID, scheme, uuid)
+	// An optional RFC 3339 activation time; if it is in the future, the
+	// activation is scheduled rather than performed immediately.
+	var at time.Time
+	if atParam := c.Query("at"); atParam != "" {
+		at, err = time.Parse(time.RFC3339, atParam)
+		if err != nil {
+			reportProblem(c,
+				http.StatusBadRequest,
+				fmt.Sprintf("bad activation time %q: %v", atParam, err),
+			)
+			return
+		}
+	}
+
+	err = o.Manager.Activate(c, requestTenantID(c), scheme, c.Query("policy"), uuid, at,
+		auth.GetPrincipal(c))
+	o.respondSimple(c, err)
+}
+
+func (o Handler) CancelActivation(c *gin.Context) {
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	uuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("bad UUID %q", c.Param("uuid")),
+		)
+		return
+	}
+
+	err = o.Manager.CancelActivation(c, requestTenantID(c), scheme, c.Query("policy"), uuid)
+	o.respondSimple(c, err)
+}
+
+func (o Handler) GetPending(c *gin.Context) {
+	offered := c.NegotiateFormat(PoliciesMediaType)
+	if offered != PoliciesMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PoliciesMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	policies, err := o.Manager.GetPending(c, requestTenantID(c), scheme, c.Query("policy"))
+	o.respondToGet(c, PoliciesMediaType, policies, err)
+}
+
+func (o Handler) DeletePolicy(c *gin.Context) {
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	uuid, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("bad UUID %q", c.Param("uuid")),
+		)
+		return
+	}
+
+	err = o.Manager.DeletePolicy(c, requestTenantID(c), scheme, c.Query("policy"), uuid)
 	o.respondSimple(c, err)
 }
 
+func (o Handler) DiffPolicies(c *gin.Context) {
+	offered := c.NegotiateFormat(DiffMediaType)
+	if offered != DiffMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				DiffMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	fromID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("bad UUID %q", c.Param("uuid")),
+		)
+		return
+	}
+
+	toID, err := uuid.Parse(c.Query("to"))
+	if err != nil {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("bad \"to\" UUID %q", c.Query("to")),
+		)
+		return
+	}
+
+	diff, err := o.Manager.Diff(c, requestTenantID(c), scheme, c.Query("policy"), fromID, toID)
+	if err != nil {
+		o.respondSimple(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, DiffMediaType, []byte(diff))
+}
+
+func (o Handler) GetHistory(c *gin.Context) {
+	offered := c.NegotiateFormat(PoliciesMediaType)
+	if offered != PoliciesMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PoliciesMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	policies, err := o.Manager.GetHistory(c, requestTenantID(c), scheme, c.Query("policy"))
+	o.respondToGet(c, PoliciesMediaType, policies, err)
+}
+
+func (o Handler) Rollback(c *gin.Context) {
+	offered := c.NegotiateFormat(PolicyMediaType)
+	if offered != PolicyMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PolicyMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	pol, err := o.Manager.Rollback(c, requestTenantID(c), scheme, c.Query("policy"),
+		auth.GetPrincipal(c))
+	o.respondToGet(c, PolicyMediaType, pol, err)
+}
+
 func (o Handler) DeactivateAll(c *gin.Context) {
 	scheme := c.Param("scheme")
 	if !o.Manager.IsSchemeSupported(scheme) {
@@ -226,22 +529,462 @@ func (o Handler) DeactivateAll(c *gin.Context) {
 		return
 	}
 
-	err := o.Manager.DeactivateAll(c, tenantID, scheme)
+	err := o.Manager.DeactivateAll(c, requestTenantID(c), scheme, c.Query("policy"))
 	o.respondSimple(c, err)
 }
 
+func (o Handler) GetPolicyData(c *gin.Context) {
+	offered := c.NegotiateFormat(PolicyDataMediaType)
+	if offered != PolicyDataMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PolicyDataMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	data, err := o.Manager.GetData(c, requestTenantID(c), scheme)
+	o.respondToGet(c, PolicyDataMediaType, data, err)
+}
+
+func (o Handler) SetPolicyData(c *gin.Context) {
+	offered := c.NegotiateFormat(PolicyDataMediaType)
+	if offered != PolicyDataMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PolicyDataMediaType),
+		)
+		return
+	}
+
+	mediaType := c.Request.Header.Get("Content-Type")
+	if mediaType != gin.MIMEJSON {
+		reportProblem(c,
+			http.StatusUnsupportedMediaType,
+			fmt.Sprintf("the only supported data format is %s", gin.MIMEJSON),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("error reading body: %s", err))
+		return
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(payload, &data); err != nil || data == nil {
+		reportProblem(c, http.StatusBadRequest, "policy data must be a JSON object")
+		return
+	}
+
+	pd, err := o.Manager.SetData(c, requestTenantID(c), scheme, data, auth.GetPrincipal(c))
+	if err != nil {
+		reportProblem(c,
+			http.StatusInternalServerError,
+			fmt.Sprintf("could not update policy data: %s", err),
+		)
+		return
+	}
+
+	o.respondToGet(c, PolicyDataMediaType, pd, nil)
+}
+
+func (o Handler) DeletePolicyData(c *gin.Context) {
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	err := o.Manager.DeleteData(c, requestTenantID(c), scheme)
+	o.respondSimple(c, err)
+}
+
+func (o Handler) GetPolicyChain(c *gin.Context) {
+	offered := c.NegotiateFormat(PolicyChainMediaType)
+	if offered != PolicyChainMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PolicyChainMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	chain, err := o.Manager.GetChain(c, requestTenantID(c), scheme)
+	o.respondToGet(c, PolicyChainMediaType, chain, err)
+}
+
+func (o Handler) SetPolicyChain(c *gin.Context) {
+	offered := c.NegotiateFormat(PolicyChainMediaType)
+	if offered != PolicyChainMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				PolicyChainMediaType),
+		)
+		return
+	}
+
+	mediaType := c.Request.Header.Get("Content-Type")
+	if mediaType != PolicyChainMediaType {
+		reportProblem(c,
+			http.StatusUnsupportedMediaType,
+			fmt.Sprintf("the only supported chain format is %s", PolicyChainMediaType),
+		)
+		return
+	}
+
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("error reading body: %s", err))
+		return
+	}
+
+	var req struct {
+		Policies []string `json:"policies"`
+	}
+	if err := json.Unmarshal(payload, &req); err != nil {
+		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("bad policy chain: %s", err))
+		return
+	}
+
+	chain, err := o.Manager.SetChain(c, requestTenantID(c), scheme, req.Policies, auth.GetPrincipal(c))
+	if errors.Is(err, policy.ErrBadPolicyChain) {
+		reportProblem(c, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		reportProblem(c,
+			http.StatusInternalServerError,
+			fmt.Sprintf("could not update policy chain: %s", err),
+		)
+		return
+	}
+
+	o.respondToGet(c, PolicyChainMediaType, chain, nil)
+}
+
+func (o Handler) DeletePolicyChain(c *gin.Context) {
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return
+	}
+
+	err := o.Manager.DeleteChain(c, requestTenantID(c), scheme)
+	o.respondSimple(c, err)
+}
+
+func (o Handler) GetDecisions(c *gin.Context) {
+	offered := c.NegotiateFormat(DecisionsMediaType)
+	if offered != DecisionsMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				DecisionsMediaType),
+		)
+		return
+	}
+
+	decisions, err := o.Manager.GetDecisions(c, requestTenantID(c), c.Param("session"))
+	o.respondToGet(c, DecisionsMediaType, decisions, err)
+}
+
 func (o Handler) respondSimple(c *gin.Context, err error) {
 	if err == nil {
 		c.Status(http.StatusOK)
 	} else {
-		if errors.Is(err, policy.ErrNoPolicy) {
+		if errors.Is(err, policy.ErrNoPolicy) ||
+			errors.Is(err, policy.ErrNoPolicyData) ||
+			errors.Is(err, policy.ErrNoPolicyChain) ||
+			errors.Is(err, policy.ErrNoPendingActivation) ||
+			errors.Is(err, management.ErrNoTenantStore) ||
+			errors.Is(err, tenant.ErrNoTenant) {
 			reportProblem(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, management.ErrBadPolicyName) {
+			reportProblem(c, http.StatusBadRequest, err.Error())
+		} else if errors.Is(err, policy.ErrPolicyActive) {
+			reportProblem(c, http.StatusConflict, err.Error())
 		} else {
 			reportProblem(c, http.StatusInternalServerError, err.Error())
 		}
 	}
 }
 
+func (o Handler) GetTrustAnchors(c *gin.Context) {
+	offered := c.NegotiateFormat(TrustAnchorsMediaType)
+	if offered != TrustAnchorsMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				TrustAnchorsMediaType),
+		)
+		return
+	}
+
+	scheme, env, ok := o.getEndorsementQuery(c)
+	if !ok {
+		return
+	}
+
+	triples, err := o.Endorsements.GetTrustAnchors(c, requestTenantID(c), scheme, env)
+	o.respondToGet(c, TrustAnchorsMediaType, triples, err)
+}
+
+func (o Handler) GetReferenceValues(c *gin.Context) {
+	offered := c.NegotiateFormat(ReferenceValuesMediaType)
+	if offered != ReferenceValuesMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				ReferenceValuesMediaType),
+		)
+		return
+	}
+
+	scheme, env, ok := o.getEndorsementQuery(c)
+	if !ok {
+		return
+	}
+
+	triples, err := o.Endorsements.GetReferenceValues(c, requestTenantID(c), scheme, env)
+	o.respondToGet(c, ReferenceValuesMediaType, triples, err)
+}
+
+func (o Handler) GetTrustAnchorOrigins(c *gin.Context) {
+	offered := c.NegotiateFormat(EndorsementOriginsMediaType)
+	if offered != EndorsementOriginsMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				EndorsementOriginsMediaType),
+		)
+		return
+	}
+
+	scheme, env, ok := o.getEndorsementQuery(c)
+	if !ok {
+		return
+	}
+
+	origins, err := o.Endorsements.GetTrustAnchorOrigins(c, requestTenantID(c), scheme, env)
+	o.respondToGet(c, EndorsementOriginsMediaType, origins, err)
+}
+
+func (o Handler) GetReferenceValueOrigins(c *gin.Context) {
+	offered := c.NegotiateFormat(EndorsementOriginsMediaType)
+	if offered != EndorsementOriginsMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				EndorsementOriginsMediaType),
+		)
+		return
+	}
+
+	scheme, env, ok := o.getEndorsementQuery(c)
+	if !ok {
+		return
+	}
+
+	origins, err := o.Endorsements.GetReferenceValueOrigins(c, requestTenantID(c), scheme, env)
+	o.respondToGet(c, EndorsementOriginsMediaType, origins, err)
+}
+
+// getEndorsementQuery returns the scheme and the environment filter specified
+// by the request. If these are not valid, a problem is reported and false is
+// returned.
+func (o Handler) getEndorsementQuery(c *gin.Context) (string, *comid.Environment, bool) {
+	scheme := c.Param("scheme")
+	if !o.Manager.IsSchemeSupported(scheme) {
+		reportProblem(c,
+			http.StatusBadRequest,
+			fmt.Sprintf("unrecognised scheme %q", scheme),
+		)
+		return "", nil, false
+	}
+
+	env, err := management.NewEnvironmentFilter(
+		c.Query("class-id"),
+		c.Query("instance-id"),
+		c.Query("vendor"),
+		c.Query("model"),
+	)
+	if err != nil {
+		reportProblem(c, http.StatusBadRequest, err.Error())
+		return "", nil, false
+	}
+
+	return scheme, env, true
+}
+
+func (o Handler) CreateTenant(c *gin.Context) {
+	offered := c.NegotiateFormat(TenantMediaType)
+	if offered != TenantMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				TenantMediaType),
+		)
+		return
+	}
+
+	mediaType := c.Request.Header.Get("Content-Type")
+	if mediaType != TenantMediaType {
+		reportProblem(c,
+			http.StatusUnsupportedMediaType,
+			fmt.Sprintf("the only supported tenant format is %s", TenantMediaType),
+		)
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("error reading body: %s", err))
+		return
+	}
+
+	var newTenant tenant.Tenant
+	if err := json.Unmarshal(payload, &newTenant); err != nil {
+		reportProblem(c, http.StatusBadRequest, fmt.Sprintf("bad tenant: %s", err))
+		return
+	}
+
+	for _, scheme := range newTenant.Schemes {
+		if !o.Manager.IsSchemeSupported(scheme) {
+			reportProblem(c,
+				http.StatusBadRequest,
+				fmt.Sprintf("unrecognised scheme %q", scheme),
+			)
+			return
+		}
+	}
+
+	created, err := o.Tenants.Create(c, &newTenant, auth.GetPrincipal(c))
+	if err != nil {
+		o.respondToGet(c, TenantMediaType, nil, err)
+		return
+	}
+
+	respBytes, err := json.Marshal(created)
+	if err != nil {
+		reportProblem(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data(http.StatusCreated, TenantMediaType, respBytes)
+}
+
+func (o Handler) GetTenants(c *gin.Context) {
+	offered := c.NegotiateFormat(TenantsMediaType)
+	if offered != TenantsMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				TenantsMediaType),
+		)
+		return
+	}
+
+	tenants, err := o.Tenants.List(c)
+	o.respondToGet(c, TenantsMediaType, tenants, err)
+}
+
+func (o Handler) GetTenant(c *gin.Context) {
+	offered := c.NegotiateFormat(TenantMediaType)
+	if offered != TenantMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				TenantMediaType),
+		)
+		return
+	}
+
+	ret, err := o.Tenants.Get(c, c.Param("id"))
+	o.respondToGet(c, TenantMediaType, ret, err)
+}
+
+func (o Handler) SuspendTenant(c *gin.Context) {
+	offered := c.NegotiateFormat(TenantMediaType)
+	if offered != TenantMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				TenantMediaType),
+		)
+		return
+	}
+
+	ret, err := o.Tenants.Suspend(c, c.Param("id"), auth.GetPrincipal(c))
+	o.respondToGet(c, TenantMediaType, ret, err)
+}
+
+func (o Handler) ResumeTenant(c *gin.Context) {
+	offered := c.NegotiateFormat(TenantMediaType)
+	if offered != TenantMediaType {
+		reportProblem(c,
+			http.StatusNotAcceptable,
+			fmt.Sprintf("the only supported output format is %s",
+				TenantMediaType),
+		)
+		return
+	}
+
+	ret, err := o.Tenants.Resume(c, c.Param("id"), auth.GetPrincipal(c))
+	o.respondToGet(c, TenantMediaType, ret, err)
+}
+
+func (o Handler) DeleteTenant(c *gin.Context) {
+	err := o.Tenants.Delete(c, c.Param("id"), auth.GetPrincipal(c))
+	o.respondSimple(c, err)
+}
+
 func (o Handler) GetManagementWellKnownInfo(c *gin.Context) {
 	offered := c.NegotiateFormat(capability.WellKnownMediaType)
 	if offered != capability.WellKnownMediaType && offered != gin.MIMEJSON {
@@ -277,8 +1020,24 @@ func (o Handler) GetManagementWellKnownInfo(c *gin.Context) {
 
 func (o Handler) respondToGet(c *gin.Context, mt string, ret interface{}, err error) {
 	if err != nil {
-		if errors.Is(err, policy.ErrNoPolicy) || errors.Is(err, policy.ErrNoActivePolicy) {
+		if errors.Is(err, policy.ErrNoPolicy) ||
+			errors.Is(err, policy.ErrNoActivePolicy) ||
+			errors.Is(err, policy.ErrNoPreviousPolicy) ||
+			errors.Is(err, policy.ErrNoPolicyData) ||
+			errors.Is(err, policy.ErrNoPolicyChain) ||
+			errors.Is(err, policy.ErrNoDecisions) ||
+			errors.Is(err, management.ErrNoDecisionLog) ||
+			errors.Is(err, management.ErrNoEndorsementStore) ||
+			errors.Is(err, management.ErrNoProvenanceStore) ||
+			errors.Is(err, management.ErrNoTenantStore) ||
+			errors.Is(err, tenant.ErrNoTenant) {
 			reportProblem(c, http.StatusNotFound, err.Error())
+		} else if errors.Is(err, management.ErrBadPolicyName) ||
+			errors.Is(err, management.ErrBadCursor) ||
+			errors.Is(err, tenant.ErrBadTenant) {
+			reportProblem(c, http.StatusBadRequest, err.Error())
+		} else if errors.Is(err, tenant.ErrTenantExists) {
+			reportProblem(c, http.StatusConflict, err.Error())
 		} else {
 			reportProblem(c, http.StatusInternalServerError, err.Error())
 		}
@@ -293,6 +1052,16 @@ func (o Handler) respondToGet(c *gin.Context, mt string, ret interface{}, err er
 	c.Data(http.StatusOK, mt, respBytes)
 }
 
+// requestTenantID returns the ID of the tenant the request acts on, as
+// established by the router (see bindTenant).
+func requestTenantID(c *gin.Context) string {
+	if id := c.GetString(tenantIDKey); id != "" {
+		return id
+	}
+
+	return defaultTenantID
+}
+
 func reportProblem(c *gin.Context, status int, details ...string) {
 	prob := problems.NewStatusProblem(status)
 
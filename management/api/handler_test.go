@@ -0,0 +1,69 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/management"
+	"github.com/veraison/services/policy"
+)
+
+func newTestPolicyManager(t *testing.T) *management.PolicyManager {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := policy.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	agent, err := policy.CreateAgent(viper.New(), log.Named("test"))
+	require.NoError(t, err)
+
+	return management.NewPolicyManager(agent, store, []string{"PSA_IOT"})
+}
+
+func TestHandler_CreatePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := newTestPolicyManager(t)
+	handler := NewHandler(manager, nil, nil, log.Named("test"))
+
+	router := gin.New()
+	router.POST("/policy/:scheme", handler.CreatePolicy)
+
+	for _, tc := range []struct {
+		name  string
+		rules string
+		code  int
+	}{
+		{"invalid policy", "package policy\n\nthis is not rego", http.StatusBadRequest},
+		{"valid policy", "package policy\n", http.StatusCreated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/policy/PSA_IOT",
+				strings.NewReader(tc.rules))
+			req.Header.Set("Content-Type", RulesMediaType)
+			req.Header.Set("Accept", PolicyMediaType)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code, w.Body.String())
+		})
+	}
+
+	// only the valid policy has been stored
+	policies, err := manager.Store.ListAllVersions()
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "package policy\n", policies[0].Rules)
+}
@@ -3,7 +3,10 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
 	"path"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/veraison/services/auth"
@@ -11,6 +14,10 @@ import (
 
 const (
 	managementPath = "/management/v1"
+
+	// tenantIDKey is the key under which the ID of the tenant a request
+	// acts on is recorded in the gin.Context (see bindTenant).
+	tenantIDKey = "management-tenant-id"
 )
 
 var publicApiMap = make(map[string]string)
@@ -24,26 +31,230 @@ func NewRouter(handler Handler, authorizer auth.IAuthorizer) *gin.Engine {
 	router.GET("/.well-known/veraison/management", handler.GetManagementWellKnownInfo)
 
 	manageGroup := router.Group(managementPath)
-	manageGroup.Use(authorizer.GetGinHandler(auth.ManagerRole))
 
-	manageGroup.POST("policy/:scheme", handler.CreatePolicy)
+	// permit returns the handler checking that the principal is permitted
+	// to perform the action on the scheme in the request path, for the
+	// tenant the request is for.
+	permit := func(action string) gin.HandlerFunc {
+		return bindTenant(
+			authorizer.GetPermissionHandler(action, getSchemeResource),
+			getTenantID,
+		)
+	}
+
+	// permitTenant returns the handler checking that the principal is
+	// permitted to perform the action on the tenant in the request path.
+	permitTenant := func(action string) gin.HandlerFunc {
+		return bindTenant(
+			authorizer.GetPermissionHandler(action, getTenantResource),
+			getPathTenantID,
+		)
+	}
+
+	manageGroup.POST("policy/:scheme",
+		permit(auth.CreatePoliciesAction), handler.CreatePolicy)
 	publicApiMap["createPolicy"] = path.Join(managementPath, "policy/:scheme")
 
-	manageGroup.POST("policy/:scheme/:uuid/activate", handler.Activate)
+	manageGroup.POST("policy/:scheme/:uuid/activate",
+		permit(auth.ActivatePoliciesAction), handler.Activate)
 	publicApiMap["activatePolicy"] = path.Join(managementPath, "policy/:scheme/:uuid/activate")
 
-	manageGroup.GET("policy/:scheme", handler.GetActivePolicy)
+	manageGroup.DELETE("policy/:scheme/:uuid/activate",
+		permit(auth.ActivatePoliciesAction), handler.CancelActivation)
+	publicApiMap["cancelPolicyActivation"] = path.Join(managementPath,
+		"policy/:scheme/:uuid/activate")
+
+	manageGroup.GET("policy/:scheme",
+		permit(auth.ViewPoliciesAction), handler.GetActivePolicy)
 	publicApiMap["getActivePolicy"] = path.Join(managementPath, "policy/:scheme")
 
-	manageGroup.GET("policy/:scheme/:uuid", handler.GetPolicy)
+	manageGroup.GET("policy/:scheme/:uuid",
+		permit(auth.ViewPoliciesAction), handler.GetPolicy)
 	publicApiMap["getPolicy"] = path.Join(managementPath, "policy/:scheme/:uuid")
 
-	manageGroup.POST("policies/:scheme/deactivate", handler.DeactivateAll)
+	manageGroup.DELETE("policy/:scheme/:uuid",
+		permit(auth.DeletePoliciesAction), handler.DeletePolicy)
+	publicApiMap["deletePolicy"] = path.Join(managementPath, "policy/:scheme/:uuid")
+
+	manageGroup.GET("policy/:scheme/:uuid/diff",
+		permit(auth.ViewPoliciesAction), handler.DiffPolicies)
+	publicApiMap["diffPolicies"] = path.Join(managementPath, "policy/:scheme/:uuid/diff")
+
+	manageGroup.POST("policies/:scheme/deactivate",
+		permit(auth.ActivatePoliciesAction), handler.DeactivateAll)
 	publicApiMap["deactivatePolicies"] = path.Join(managementPath,
 		"policies/:scheme/deactivate")
 
-	manageGroup.GET("policies/:scheme", handler.GetPolicies)
+	manageGroup.GET("policies/:scheme",
+		permit(auth.ViewPoliciesAction), handler.GetPolicies)
 	publicApiMap["getPolicies"] = path.Join(managementPath, "policies/:scheme")
 
+	manageGroup.GET("policies/:scheme/history",
+		permit(auth.ViewPoliciesAction), handler.GetHistory)
+	publicApiMap["getPolicyHistory"] = path.Join(managementPath, "policies/:scheme/history")
+
+	manageGroup.GET("policies/:scheme/pending",
+		permit(auth.ViewPoliciesAction), handler.GetPending)
+	publicApiMap["getPendingActivations"] = path.Join(managementPath, "policies/:scheme/pending")
+
+	manageGroup.POST("policies/:scheme/rollback",
+		permit(auth.ActivatePoliciesAction), handler.Rollback)
+	publicApiMap["rollbackPolicy"] = path.Join(managementPath, "policies/:scheme/rollback")
+
+	manageGroup.GET("policy-data/:scheme",
+		permit(auth.ViewPoliciesAction), handler.GetPolicyData)
+	publicApiMap["getPolicyData"] = path.Join(managementPath, "policy-data/:scheme")
+
+	manageGroup.PUT("policy-data/:scheme",
+		permit(auth.ConfigurePoliciesAction), handler.SetPolicyData)
+	publicApiMap["setPolicyData"] = path.Join(managementPath, "policy-data/:scheme")
+
+	manageGroup.DELETE("policy-data/:scheme",
+		permit(auth.ConfigurePoliciesAction), handler.DeletePolicyData)
+	publicApiMap["deletePolicyData"] = path.Join(managementPath, "policy-data/:scheme")
+
+	manageGroup.GET("policy-chain/:scheme",
+		permit(auth.ViewPoliciesAction), handler.GetPolicyChain)
+	publicApiMap["getPolicyChain"] = path.Join(managementPath, "policy-chain/:scheme")
+
+	manageGroup.PUT("policy-chain/:scheme",
+		permit(auth.ConfigurePoliciesAction), handler.SetPolicyChain)
+	publicApiMap["setPolicyChain"] = path.Join(managementPath, "policy-chain/:scheme")
+
+	manageGroup.DELETE("policy-chain/:scheme",
+		permit(auth.ConfigurePoliciesAction), handler.DeletePolicyChain)
+	publicApiMap["deletePolicyChain"] = path.Join(managementPath, "policy-chain/:scheme")
+
+	manageGroup.GET("endorsements/:scheme/trust-anchors",
+		permit(auth.ViewEndorsementsAction), handler.GetTrustAnchors)
+	publicApiMap["getTrustAnchors"] = path.Join(managementPath,
+		"endorsements/:scheme/trust-anchors")
+
+	manageGroup.GET("endorsements/:scheme/reference-values",
+		permit(auth.ViewEndorsementsAction), handler.GetReferenceValues)
+	publicApiMap["getReferenceValues"] = path.Join(managementPath,
+		"endorsements/:scheme/reference-values")
+
+	manageGroup.GET("endorsements/:scheme/trust-anchors/origins",
+		permit(auth.ViewEndorsementsAction), handler.GetTrustAnchorOrigins)
+	publicApiMap["getTrustAnchorOrigins"] = path.Join(managementPath,
+		"endorsements/:scheme/trust-anchors/origins")
+
+	manageGroup.GET("endorsements/:scheme/reference-values/origins",
+		permit(auth.ViewEndorsementsAction), handler.GetReferenceValueOrigins)
+	publicApiMap["getReferenceValueOrigins"] = path.Join(managementPath,
+		"endorsements/:scheme/reference-values/origins")
+
+	manageGroup.GET("policy-decisions/:session",
+		permit(auth.ViewDecisionsAction), handler.GetDecisions)
+	publicApiMap["getPolicyDecisions"] = path.Join(managementPath, "policy-decisions/:session")
+
+	manageGroup.POST("tenants",
+		permitTenant(auth.ManageTenantsAction), handler.CreateTenant)
+	publicApiMap["createTenant"] = path.Join(managementPath, "tenants")
+
+	manageGroup.GET("tenants",
+		permitTenant(auth.ViewTenantsAction), handler.GetTenants)
+	publicApiMap["getTenants"] = path.Join(managementPath, "tenants")
+
+	manageGroup.GET("tenant/:id",
+		permitTenant(auth.ViewTenantsAction), handler.GetTenant)
+	publicApiMap["getTenant"] = path.Join(managementPath, "tenant/:id")
+
+	manageGroup.POST("tenant/:id/suspend",
+		permitTenant(auth.ManageTenantsAction), handler.SuspendTenant)
+	publicApiMap["suspendTenant"] = path.Join(managementPath, "tenant/:id/suspend")
+
+	manageGroup.POST("tenant/:id/resume",
+		permitTenant(auth.ManageTenantsAction), handler.ResumeTenant)
+	publicApiMap["resumeTenant"] = path.Join(managementPath, "tenant/:id/resume")
+
+	manageGroup.DELETE("tenant/:id",
+		permitTenant(auth.ManageTenantsAction), handler.DeleteTenant)
+	publicApiMap["deleteTenant"] = path.Join(managementPath, "tenant/:id")
+
 	return router
 }
+
+// getSchemeResource returns the resource identified by the scheme in the
+// request path (if any) for the tenant the request is for.
+func getSchemeResource(c *gin.Context) auth.Resource {
+	id, _ := getTenantID(c)
+	return auth.Resource{Scheme: c.Param("scheme"), Tenant: id}
+}
+
+// getTenantResource returns the resource identified by the tenant ID in the
+// request path. This will be empty for requests on the collection of tenants.
+func getTenantResource(c *gin.Context) auth.Resource {
+	return auth.Resource{Tenant: c.Param("id")}
+}
+
+// getTenantID returns the ID of the tenant a request on policies, endorsements
+// or decisions is for. Principals that belong to a tenant (as established by
+// the authorizer) act on their own tenant. Other principals (e.g. the
+// deployment's administrators) may specify the tenant using the "tenant" query
+// parameter, and act on the default tenant otherwise. The returned bool is
+// false if a principal that belongs to a tenant requested a different one.
+func getTenantID(c *gin.Context) (string, bool) {
+	requested := c.Query("tenant")
+
+	if own := auth.GetTenant(c); own != "" {
+		return own, requested == "" || requested == own
+	}
+
+	if requested != "" {
+		return requested, true
+	}
+
+	return defaultTenantID, true
+}
+
+// getPathTenantID returns the tenant ID in the request path of requests on
+// tenants (this is empty for requests on the collection of tenants). The
+// returned bool is false if the principal belongs to a tenant other than the
+// one in the path; principals that belong to a tenant may not act on the
+// collection of tenants.
+func getPathTenantID(c *gin.Context) (string, bool) {
+	id := c.Param("id")
+
+	if own := auth.GetTenant(c); own != "" {
+		return id, id == own
+	}
+
+	return id, true
+}
+
+// bindTenant returns a gin.HandlerFunc that checks the request's permissions
+// using permit, and then records the ID of the tenant the request acts on,
+// as returned by getTenant, so that it can be retrieved by the handler (see
+// requestTenantID). Requests by principals that belong to a tenant are
+// rejected if they attempt to act on another tenant, irrespective of their
+// permissions.
+func bindTenant(
+	permit gin.HandlerFunc,
+	getTenant func(*gin.Context) (string, bool),
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		permit(c)
+		if c.IsAborted() {
+			return
+		}
+
+		id, ok := getTenant(c)
+		if !ok {
+			reportProblem(c, http.StatusForbidden,
+				fmt.Sprintf("principal may only act on tenant %q", auth.GetTenant(c)))
+			return
+		}
+
+		// tenant IDs form part of the keys under which the tenant's
+		// data is stored (see tenant.Tenant.Validate)
+		if strings.ContainsAny(id, ":/") {
+			reportProblem(c, http.StatusBadRequest,
+				fmt.Sprintf("invalid tenant %q", id))
+			return
+		}
+
+		c.Set(tenantIDKey, id)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/veraison/services/auth"
+)
+
+const testTenantHeader = "X-Test-Tenant"
+
+// newTestTenantRouter returns a router serving the tenant (as returned by
+// requestTenantID) that requests bound using bindTenant act on. The tenant of
+// the principal is taken from the testTenantHeader, and requests are denied
+// if the "deny" query parameter is set.
+func newTestTenantRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	permit := func(c *gin.Context) {
+		c.Set(auth.TenantKey, c.GetHeader(testTenantHeader))
+
+		if c.Query("deny") != "" {
+			reportProblem(c, http.StatusForbidden, "denied")
+		}
+	}
+
+	serve := func(c *gin.Context) {
+		c.String(http.StatusOK, requestTenantID(c))
+	}
+
+	router := gin.New()
+	router.GET("/schemes/:scheme", bindTenant(permit, getTenantID), serve)
+	router.GET("/tenants", bindTenant(permit, getPathTenantID), serve)
+	router.GET("/tenants/:id", bindTenant(permit, getPathTenantID), serve)
+
+	return router
+}
+
+func TestRouter_bindTenant(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		path     string
+		tenant   string
+		code     int
+		expected string
+	}{
+		{"default tenant", "/schemes/PSA_IOT", "", http.StatusOK, defaultTenantID},
+		{"requested tenant", "/schemes/PSA_IOT?tenant=acme", "", http.StatusOK, "acme"},
+		{"own tenant", "/schemes/PSA_IOT", "acme", http.StatusOK, "acme"},
+		{"own tenant requested", "/schemes/PSA_IOT?tenant=acme", "acme", http.StatusOK, "acme"},
+		{"other tenant requested", "/schemes/PSA_IOT?tenant=globex", "acme", http.StatusForbidden, ""},
+		{"invalid tenant", "/schemes/PSA_IOT?tenant=acme/eu", "", http.StatusBadRequest, ""},
+		{"not permitted", "/schemes/PSA_IOT?deny=1", "", http.StatusForbidden, ""},
+		{"tenant in path", "/tenants/acme", "", http.StatusOK, "acme"},
+		{"own tenant in path", "/tenants/acme", "acme", http.StatusOK, "acme"},
+		{"other tenant in path", "/tenants/globex", "acme", http.StatusForbidden, ""},
+		{"tenants collection", "/tenants", "", http.StatusOK, defaultTenantID},
+		{"tenants collection by tenant", "/tenants", "acme", http.StatusForbidden, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, http.NoBody)
+			if tc.tenant != "" {
+				req.Header.Set(testTenantHeader, tc.tenant)
+			}
+
+			newTestTenantRouter().ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code, w.Body.String())
+			if tc.code == http.StatusOK {
+				assert.Equal(t, tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_getSchemeResource(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var resource auth.Resource
+	router := gin.New()
+	router.GET("/schemes/:scheme", func(c *gin.Context) {
+		c.Set(auth.TenantKey, c.GetHeader(testTenantHeader))
+		resource = getSchemeResource(c)
+	})
+
+	for _, tc := range []struct {
+		path     string
+		tenant   string
+		expected auth.Resource
+	}{
+		{"/schemes/PSA_IOT", "", auth.Resource{Scheme: "PSA_IOT", Tenant: defaultTenantID}},
+		{"/schemes/PSA_IOT?tenant=acme", "", auth.Resource{Scheme: "PSA_IOT", Tenant: "acme"}},
+		{"/schemes/ARM_CCA", "acme", auth.Resource{Scheme: "ARM_CCA", Tenant: "acme"}},
+		// the permissions are checked for the principal's own tenant;
+		// the request is then rejected by bindTenant
+		{"/schemes/ARM_CCA?tenant=globex", "acme", auth.Resource{Scheme: "ARM_CCA", Tenant: "acme"}},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, tc.path, http.NoBody)
+		if tc.tenant != "" {
+			req.Header.Set(testTenantHeader, tc.tenant)
+		}
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, tc.expected, resource, tc.path)
+	}
+}
@@ -3,6 +3,8 @@
 package main
 
 import (
+	"net/http"
+
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/veraison/services/auth"
 	"github.com/veraison/services/config"
@@ -49,6 +51,29 @@ func main() {
 		log.Fatalf("could not init policy manager: %v", err)
 	}
 
+	log.Info("initializing endorsement manager")
+	em, err := management.CreateEndorsementManagerFromConfig(v, "endorsement")
+	if err != nil {
+		log.Fatalf("could not init endorsement manager: %v", err)
+	}
+	defer func() {
+		if err := em.Close(); err != nil {
+			log.Errorf("Could not close endorsement store: %v", err)
+		}
+	}()
+
+	log.Info("initializing tenant manager")
+	tm, err := management.CreateTenantManagerFromConfig(
+		v, "tenant", pm.Store, em, pm.SupportedSchemes)
+	if err != nil {
+		log.Fatalf("could not init tenant manager: %v", err)
+	}
+	defer func() {
+		if err := tm.Close(); err != nil {
+			log.Errorf("Could not close tenant store: %v", err)
+		}
+	}()
+
 	cfg := cfg{
 		ListenAddr: DefaultListenAddr,
 		Protocol:   "https",
@@ -72,7 +97,11 @@ func main() {
 		}
 	}()
 
-	handler := api.NewHandler(pm, log.Named("api"))
+	if cfg.Protocol != "https" && auth.GetTLSConfig(authorizer) != nil {
+		log.Fatal(`the auth backend requires protocol to be "https"`)
+	}
+
+	handler := api.NewHandler(pm, em, tm, log.Named("api"))
 
 	if cfg.Protocol == "https" {
 		apiServerTLS(handler, authorizer, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
@@ -88,10 +117,16 @@ func apiServer(apiHandler api.Handler, auth auth.IAuthorizer, listenAddr string)
 	}
 }
 
-func apiServerTLS(apiHandler api.Handler, auth auth.IAuthorizer, listenAddr, certFile, keyFile string) {
+func apiServerTLS(apiHandler api.Handler, authorizer auth.IAuthorizer, listenAddr, certFile, keyFile string) {
 	log.Infow("initializing management API HTTPS service", "address", listenAddr)
 
-	if err := api.NewRouter(apiHandler, auth).RunTLS(listenAddr, certFile, keyFile); err != nil {
+	server := &http.Server{
+		Addr:      listenAddr,
+		Handler:   api.NewRouter(apiHandler, authorizer),
+		TLSConfig: auth.GetTLSConfig(authorizer),
+	}
+
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
@@ -0,0 +1,185 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/veraison/services/kvstore"
+)
+
+const (
+	ArchiveFormat = 1
+	ManifestName  = "manifest.json"
+	DumpSuffix    = ".jsonl"
+)
+
+// Manifest is the first entry of an archive. It is followed by one entry per
+// store, named after the store with DumpSuffix appended, containing the
+// store's dump (see kvstore.Dump and EndorsementStore.Dump). The Summary of the
+// endorsement store counts its rows as keys.
+type Manifest struct {
+	Format  int                        `json:"format"`
+	Version string                     `json:"version"`
+	Created time.Time                  `json:"created"`
+	Stores  map[string]kvstore.Summary `json:"stores"`
+}
+
+// writeArchive writes a gzip'ed tar archive to path, containing the manifest
+// followed by the dumps in the specified files, indexed by store name.
+func writeArchive(path string, manifest Manifest, dumps map[string]*os.File) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeEntry(tw, ManifestName, manifest.Created, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	for _, name := range storeNames(manifest.Stores) {
+		dump := dumps[name]
+
+		info, err := dump.Stat()
+		if err != nil {
+			return err
+		}
+
+		if _, err := dump.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		if err := writeEntry(tw, name+DumpSuffix, manifest.Created, info.Size(), dump); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, modTime time.Time, size int64, r io.Reader) error {
+	hdr := tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: modTime,
+	}
+
+	if err := tw.WriteHeader(&hdr); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// archiveReader reads an archive written by writeArchive.
+type archiveReader struct {
+	Manifest Manifest
+
+	f  *os.File
+	gz *gzip.Reader
+	tr *tar.Reader
+}
+
+func openArchive(path string) (*archiveReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	o := &archiveReader{f: f, gz: gz, tr: tar.NewReader(gz)}
+
+	if err := o.readManifest(); err != nil {
+		o.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return o, nil
+}
+
+func (o *archiveReader) readManifest() error {
+	hdr, err := o.tr.Next()
+	if err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+
+	if hdr.Name != ManifestName {
+		return fmt.Errorf("expected %s as the first entry, found %s", ManifestName, hdr.Name)
+	}
+
+	if err := json.NewDecoder(o.tr).Decode(&o.Manifest); err != nil {
+		return fmt.Errorf("reading manifest: %w", err)
+	}
+
+	if o.Manifest.Format != ArchiveFormat {
+		return fmt.Errorf("unsupported archive format %d", o.Manifest.Format)
+	}
+
+	return nil
+}
+
+// Next returns the name of the store whose dump is the next entry in the
+// archive, along with a reader for the dump. The name is empty once all entries
+// have been read.
+func (o *archiveReader) Next() (string, io.Reader, error) {
+	hdr, err := o.tr.Next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", nil, nil
+		}
+
+		return "", nil, err
+	}
+
+	name, ok := strings.CutSuffix(hdr.Name, DumpSuffix)
+	if !ok {
+		return "", nil, fmt.Errorf("unexpected entry %s", hdr.Name)
+	}
+
+	if _, ok := o.Manifest.Stores[name]; !ok {
+		return "", nil, fmt.Errorf("entry %s is not in the manifest", hdr.Name)
+	}
+
+	return name, o.tr, nil
+}
+
+func (o *archiveReader) Close() {
+	_ = o.gz.Close()
+	_ = o.f.Close()
+}
@@ -0,0 +1,581 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	"github.com/veraison/services/kvstore"
+	"github.com/veraison/services/log"
+	vtsstore "github.com/veraison/services/vts/store"
+)
+
+// EndorsementStoreName identifies the endorsement store in archives.
+const EndorsementStoreName = "endorsement-store"
+
+// endorsementStoreConfigKey is the key of the configuration of the endorsement
+// store (see vts/store).
+const endorsementStoreConfigKey = "store"
+
+// sqlDrivers maps the DBMSs of the endorsement store that are supported by
+// veraison-store onto the database/sql drivers used to access them.
+var sqlDrivers = map[string]string{
+	"sqlite3":  "sqlite3",
+	"pg":       "pgx",
+	"postgres": "pgx",
+}
+
+var safeSQLNameRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// Row is a row of a table of the endorsement store, as written by
+// EndorsementStore.Dump and read by EndorsementStore.Load.
+type Row struct {
+	Table string `json:"table"`
+	// Columns are the values of the row's columns, indexed by column name.
+	// NULL values are nil.
+	Columns map[string]*Value `json:"columns"`
+}
+
+// Value is the value of a column, encoded so that it may be inserted into a
+// column of the same type of any of the supported DBMSs.
+type Value struct {
+	// Type is one of "int", "float", "bool", "bytes" (with base64-encoded
+	// Data), "string", or "time" (with RFC 3339 Data).
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+func newValue(v any) (*Value, error) {
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return &Value{"int", strconv.FormatInt(t, 10)}, nil
+	case float64:
+		return &Value{"float", strconv.FormatFloat(t, 'g', -1, 64)}, nil
+	case bool:
+		return &Value{"bool", strconv.FormatBool(t)}, nil
+	case []byte:
+		return &Value{"bytes", base64.StdEncoding.EncodeToString(t)}, nil
+	case string:
+		return &Value{"string", t}, nil
+	case time.Time:
+		return &Value{"time", t.UTC().Format(time.RFC3339Nano)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// Decode returns the value to insert into a column of the specified database
+// type (as returned by sql.ColumnType.DatabaseTypeName).
+func (o *Value) Decode(columnType string) (any, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	switch o.Type {
+	case "int":
+		i, err := strconv.ParseInt(o.Data, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// SQLite has no boolean type, and stores booleans as integers
+		if isBoolColumn(columnType) {
+			return i != 0, nil
+		}
+
+		return i, nil
+	case "float":
+		return strconv.ParseFloat(o.Data, 64)
+	case "bool":
+		b, err := strconv.ParseBool(o.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isBoolColumn(columnType) {
+			if b {
+				return int64(1), nil
+			}
+
+			return int64(0), nil
+		}
+
+		return b, nil
+	case "bytes":
+		return base64.StdEncoding.DecodeString(o.Data)
+	case "string":
+		return o.Data, nil
+	case "time":
+		return time.Parse(time.RFC3339Nano, o.Data)
+	default:
+		return nil, fmt.Errorf("unsupported type %q", o.Type)
+	}
+}
+
+// canonical returns the encoding of the value that is included in the summary
+// of the store. It is the same for equal values stored in any of the supported
+// DBMSs.
+func (o *Value) canonical() string {
+	if o == nil {
+		return "null"
+	}
+
+	switch o.Type {
+	case "bool":
+		if o.Data == "true" {
+			return "int:1"
+		}
+
+		return "int:0"
+	case "time":
+		// Postgres timestamps have a resolution of a microsecond
+		t, err := time.Parse(time.RFC3339Nano, o.Data)
+		if err == nil {
+			return "time:" + t.Truncate(time.Microsecond).Format(time.RFC3339Nano)
+		}
+	}
+
+	return o.Type + ":" + o.Data
+}
+
+func isBoolColumn(columnType string) bool {
+	columnType = strings.ToUpper(columnType)
+	return columnType == "BOOL" || columnType == "BOOLEAN"
+}
+
+func summarizeRow(sum *kvstore.Summarizer, row Row) {
+	vals := make([]string, 0, len(row.Columns))
+	for name, val := range row.Columns {
+		vals = append(vals, fmt.Sprintf("%d:%s%s", len(name), name, val.canonical()))
+	}
+
+	sum.Add(row.Table, vals)
+}
+
+// EndorsementStore provides access to the database of the endorsement store.
+// The store is managed by corim-store, which defines its schema, so its
+// contents (the CoRIMs, along with their labels and activation state, and the
+// triples extracted from them) are archived table by table, and row by row, as
+// they are, without interpreting them.
+type EndorsementStore struct {
+	DB   *sql.DB
+	DBMS string
+
+	driver      string
+	placeholder sq.PlaceholderFormat
+}
+
+// newEndorsementStore opens the database of the endorsement store configured
+// under endorsementStoreConfigKey.
+func newEndorsementStore(v *viper.Viper) (*EndorsementStore, error) {
+	storeCfg := v.Sub(endorsementStoreConfigKey)
+	if storeCfg == nil {
+		return nil, fmt.Errorf("not configured (%s)", endorsementStoreConfigKey)
+	}
+
+	var cfg vtsstore.Config
+
+	if err := config.NewLoader(&cfg).LoadFromViper(storeCfg); err != nil {
+		return nil, err
+	}
+
+	return openEndorsementStore(cfg.DBMS, cfg.DSN)
+}
+
+func openEndorsementStore(dbms, dsn string) (*EndorsementStore, error) {
+	driver, ok := sqlDrivers[dbms]
+	if !ok {
+		return nil, fmt.Errorf("unsupported DBMS %q", dbms)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &EndorsementStore{DB: db, DBMS: dbms, driver: driver, placeholder: sq.Question}
+	if driver == "pgx" {
+		o.placeholder = sq.Dollar
+	}
+
+	return o, nil
+}
+
+// setupEndorsementStore creates the schema of the endorsement store configured
+// under endorsementStoreConfigKey.
+func setupEndorsementStore(v *viper.Viper) error {
+	store, err := vtsstore.New(v.Sub(endorsementStoreConfigKey), log.Named(EndorsementStoreName))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return store.Init()
+}
+
+func (o *EndorsementStore) Close() error {
+	return o.DB.Close()
+}
+
+// Tables returns the names of the tables of the endorsement store, ordered so
+// that tables are preceded by those their foreign keys refer to.
+func (o *EndorsementStore) Tables() ([]string, error) {
+	var query string
+
+	switch o.driver {
+	case "pgx":
+		query = "SELECT tablename FROM pg_tables WHERE schemaname = current_schema()"
+	default:
+		query = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+	}
+
+	names, err := o.queryNames(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	refs := make(map[string][]string)
+	for _, name := range names {
+		if !safeSQLNameRe.MatchString(name) {
+			return nil, fmt.Errorf("unsafe table name: %q", name)
+		}
+
+		if refs[name], err = o.referencedTables(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	var (
+		ordered []string
+		visited = make(map[string]bool)
+		visit   func(name string)
+	)
+
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		for _, ref := range refs[name] {
+			if _, ok := refs[ref]; ok {
+				visit(ref)
+			}
+		}
+
+		ordered = append(ordered, name)
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return ordered, nil
+}
+
+func (o *EndorsementStore) referencedTables(table string) ([]string, error) {
+	var query string
+
+	switch o.driver {
+	case "pgx":
+		query = "SELECT DISTINCT confrelid::regclass::text FROM pg_constraint " +
+			"WHERE contype = 'f' AND conrelid = $1::regclass"
+	default:
+		query = `SELECT DISTINCT "table" FROM pragma_foreign_key_list(?)`
+	}
+
+	return o.queryNames(query, table)
+}
+
+func (o *EndorsementStore) queryNames(query string, args ...any) ([]string, error) {
+	rows, err := o.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// Dump writes all rows of all tables of the endorsement store to w as a stream
+// of JSON-encoded Rows, one per line. It returns the Summary of the dumped
+// contents, in which each row counts as a key.
+func (o *EndorsementStore) Dump(w io.Writer) (kvstore.Summary, error) {
+	enc := json.NewEncoder(w)
+
+	return o.walk(func(row Row) error {
+		return enc.Encode(row)
+	})
+}
+
+// Summarize returns the Summary of the contents of the endorsement store.
+func (o *EndorsementStore) Summarize() (kvstore.Summary, error) {
+	return o.walk(func(Row) error { return nil })
+}
+
+func (o *EndorsementStore) walk(fn func(Row) error) (kvstore.Summary, error) {
+	var sum kvstore.Summarizer
+
+	tables, err := o.Tables()
+	if err != nil {
+		return kvstore.Summary{}, err
+	}
+
+	for _, table := range tables {
+		if err := o.walkTable(table, func(row Row) error {
+			if err := fn(row); err != nil {
+				return err
+			}
+
+			summarizeRow(&sum, row)
+
+			return nil
+		}); err != nil {
+			return kvstore.Summary{}, fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	return sum.Summary(), nil
+}
+
+func (o *EndorsementStore) walkTable(table string, fn func(Row) error) error {
+	rows, err := o.DB.Query(fmt.Sprintf(`SELECT * FROM "%s"`, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	vals := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+
+		row := Row{Table: table, Columns: make(map[string]*Value, len(columns))}
+		for i, column := range columns {
+			if row.Columns[column], err = newValue(vals[i]); err != nil {
+				return fmt.Errorf("%s: %w", column, err)
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Load adds the Rows read from r (as written by Dump) to the endorsement store,
+// whose tables must already exist. The rows are added in a single transaction,
+// so that the store is left unchanged if any of them cannot be added (e.g.
+// because it is already in the store). It returns the Summary of the loaded
+// contents.
+func (o *EndorsementStore) Load(r io.Reader) (kvstore.Summary, error) {
+	var sum kvstore.Summarizer
+
+	tx, err := o.DB.Begin()
+	if err != nil {
+		return kvstore.Summary{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	columnTypes := make(map[string]map[string]string)
+	loaded := 0
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var row Row
+
+		if err := dec.Decode(&row); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return kvstore.Summary{}, fmt.Errorf("row %d: %w", loaded+1, err)
+		}
+
+		types, ok := columnTypes[row.Table]
+		if !ok {
+			if types, err = o.columnTypes(tx, row.Table); err != nil {
+				return kvstore.Summary{}, fmt.Errorf("row %d: %s: %w", loaded+1, row.Table, err)
+			}
+
+			columnTypes[row.Table] = types
+		}
+
+		if err := o.insert(tx, row, types); err != nil {
+			return kvstore.Summary{}, fmt.Errorf("row %d: %s: %w", loaded+1, row.Table, err)
+		}
+
+		summarizeRow(&sum, row)
+		loaded++
+	}
+
+	if o.driver == "pgx" {
+		for table := range columnTypes {
+			if err := o.resetSequences(tx, table); err != nil {
+				return kvstore.Summary{}, fmt.Errorf("%s: %w", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return kvstore.Summary{}, err
+	}
+
+	return sum.Summary(), nil
+}
+
+// columnTypes returns the database types of the columns of the table, indexed
+// by column name.
+func (o *EndorsementStore) columnTypes(tx *sql.Tx, table string) (map[string]string, error) {
+	if !safeSQLNameRe.MatchString(table) {
+		return nil, errors.New("unsafe table name")
+	}
+
+	rows, err := tx.Query(fmt.Sprintf(`SELECT * FROM "%s" WHERE 1 = 0`, table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]string, len(cts))
+	for _, ct := range cts {
+		types[ct.Name()] = ct.DatabaseTypeName()
+	}
+
+	return types, rows.Err()
+}
+
+func (o *EndorsementStore) insert(tx *sql.Tx, row Row, types map[string]string) error {
+	names := make([]string, 0, len(row.Columns))
+	for name := range row.Columns {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	columns := make([]string, len(names))
+	vals := make([]any, len(names))
+
+	for i, name := range names {
+		if !safeSQLNameRe.MatchString(name) {
+			return fmt.Errorf("unsafe column name: %q", name)
+		}
+
+		columnType, ok := types[name]
+		if !ok {
+			return fmt.Errorf("no column %q", name)
+		}
+
+		val, err := row.Columns[name].Decode(columnType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		columns[i] = fmt.Sprintf(`"%s"`, name)
+		vals[i] = val
+	}
+
+	_, err := sq.Insert(fmt.Sprintf(`"%s"`, row.Table)).
+		Columns(columns...).
+		Values(vals...).
+		PlaceholderFormat(o.placeholder).
+		RunWith(tx).
+		Exec()
+
+	return err
+}
+
+// resetSequences advances the sequences that generate the values of the
+// table's columns (e.g. auto-incremented IDs) past the values that were
+// loaded, so that they do not generate them again.
+func (o *EndorsementStore) resetSequences(tx *sql.Tx, table string) error {
+	rows, err := tx.Query(
+		"SELECT column_name FROM information_schema.columns "+
+			"WHERE table_schema = current_schema() AND table_name = $1 "+
+			"AND (column_default LIKE 'nextval(%' OR is_identity = 'YES')",
+		table,
+	)
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return err
+		}
+
+		columns = append(columns, column)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if !safeSQLNameRe.MatchString(column) {
+			return fmt.Errorf("unsafe column name: %q", column)
+		}
+
+		query := fmt.Sprintf(
+			`SELECT setval(pg_get_serial_sequence($1, $2), COALESCE(MAX("%s"), 0) + 1, false) FROM "%s"`,
+			column, table,
+		)
+
+		if _, err := tx.Exec(query, table, column); err != nil {
+			return fmt.Errorf("%s: %w", column, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,460 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	"github.com/veraison/services/kvstore"
+	"github.com/veraison/services/log"
+)
+
+// Store identifies a kvstore handled by veraison-store.
+type Store struct {
+	// Name identifies the store in archives.
+	Name string
+	// ConfigKey is the key of the store's kvstore configuration.
+	ConfigKey string
+}
+
+// Stores are the kvstores that are set up and exported, if they are
+// configured. The endorsement store, which is not a kvstore, is handled
+// separately (see EndorsementStore).
+var Stores = []Store{
+	{Name: "po-store", ConfigKey: "po-store"},
+	{Name: "tenant-store", ConfigKey: "tenant-store"},
+	{Name: "decision-log", ConfigKey: "po-agent.decision-log.kvstore"},
+	{Name: "provenance-store", ConfigKey: "provenance-store"},
+}
+
+var setup = flag.Bool("setup", false, "set up the stores before importing into them")
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] (setup | (export|import|verify) <archive>)\n\n",
+		filepath.Base(os.Args[0]))
+	fmt.Fprintln(os.Stderr, "  setup   set up the configured stores (e.g. create their SQL tables)")
+	fmt.Fprintln(os.Stderr, "  export  write the contents of the configured stores to a new archive")
+	fmt.Fprintln(os.Stderr, "  import  add the contents of an archive to the configured stores, and verify them")
+	fmt.Fprintln(os.Stderr, "  verify  check that the contents of the configured stores match an archive")
+	fmt.Fprintf(os.Stderr, "\nFlags:\n%s", flag.CommandLine.FlagUsages())
+}
+
+func main() {
+	flag.Usage = usage
+	config.CmdLine()
+
+	command := flag.Arg(0)
+
+	expectedArgs := 2
+	if command == "setup" {
+		expectedArgs = 1
+	}
+
+	if flag.NArg() != expectedArgs {
+		usage()
+		os.Exit(2)
+	}
+
+	archivePath := flag.Arg(1)
+
+	v, err := config.ReadRawConfig(*config.File, false)
+	if err != nil {
+		log.Fatalf("Could not read config: %v", err)
+	}
+
+	subs, err := config.GetSubs(v, "*logging")
+	if err != nil {
+		log.Fatalf("Could not parse config: %v", err)
+	}
+
+	classifiers := map[string]interface{}{"service": "veraison-store"}
+	if err := log.Init(subs["logging"], classifiers); err != nil {
+		log.Fatalf("could not configure logging: %v", err)
+	}
+
+	switch command {
+	case "setup":
+		err = setupStores(v)
+	case "export":
+		err = exportStores(v, archivePath)
+	case "import":
+		err = importStores(v, archivePath, *setup)
+	case "verify":
+		err = verifyStores(v, archivePath)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("%s failed: %v", command, err)
+	}
+}
+
+func setupStores(v *viper.Viper) error {
+	configured := 0
+
+	for _, s := range Stores {
+		if v.Sub(s.ConfigKey) == nil {
+			continue
+		}
+
+		store, err := newStore(v, s.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+		defer store.Close()
+
+		if err := store.Setup(); err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+
+		log.Infow("store set up", "store", s.Name)
+		configured++
+	}
+
+	if v.Sub(endorsementStoreConfigKey) != nil {
+		if err := setupEndorsementStore(v); err != nil {
+			return fmt.Errorf("%s: %w", EndorsementStoreName, err)
+		}
+
+		log.Infow("store set up", "store", EndorsementStoreName)
+		configured++
+	}
+
+	if configured == 0 {
+		return fmt.Errorf("none of the stores are configured (%s)", strings.Join(storeConfigKeys(), ", "))
+	}
+
+	return nil
+}
+
+func exportStores(v *viper.Viper, archivePath string) error {
+	manifest := Manifest{
+		Format:  ArchiveFormat,
+		Version: config.Version,
+		Created: time.Now().UTC(),
+		Stores:  make(map[string]kvstore.Summary),
+	}
+
+	dir, err := os.MkdirTemp("", "veraison-store-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	dumps := make(map[string]*os.File)
+
+	for _, name := range configuredStoreNames(v) {
+		f, err := os.Create(filepath.Join(dir, name+DumpSuffix))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		summary, err := exportStore(v, name, f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		manifest.Stores[name] = summary
+		dumps[name] = f
+	}
+
+	if len(dumps) == 0 {
+		return fmt.Errorf("none of the stores are configured (%s)", strings.Join(storeConfigKeys(), ", "))
+	}
+
+	if err := writeArchive(archivePath, manifest, dumps); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+
+	log.Infow("archive written", "path", archivePath)
+
+	return nil
+}
+
+func exportStore(v *viper.Viper, name string, f *os.File) (kvstore.Summary, error) {
+	w := bufio.NewWriter(f)
+
+	var (
+		summary kvstore.Summary
+		err     error
+	)
+
+	if name == EndorsementStoreName {
+		summary, err = exportEndorsementStore(v, w)
+	} else {
+		summary, err = exportKVStore(v, name, w)
+	}
+
+	if err != nil {
+		return kvstore.Summary{}, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return kvstore.Summary{}, err
+	}
+
+	log.Infow("store exported", "store", name, "keys", summary.Keys)
+
+	return summary, nil
+}
+
+func exportKVStore(v *viper.Viper, name string, w io.Writer) (kvstore.Summary, error) {
+	store, err := newStore(v, name)
+	if err != nil {
+		return kvstore.Summary{}, err
+	}
+	defer store.Close()
+
+	return kvstore.Dump(store, w)
+}
+
+func exportEndorsementStore(v *viper.Viper, w io.Writer) (kvstore.Summary, error) {
+	store, err := newEndorsementStore(v)
+	if err != nil {
+		return kvstore.Summary{}, err
+	}
+	defer store.Close()
+
+	return store.Dump(w)
+}
+
+func importStores(v *viper.Viper, archivePath string, setup bool) error {
+	archive, err := openArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	imported := make(map[string]bool)
+
+	for {
+		name, r, err := archive.Next()
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+
+		if name == "" {
+			break
+		}
+
+		if name == EndorsementStoreName {
+			err = importEndorsementStore(v, r, archive.Manifest.Stores[name], setup)
+		} else {
+			err = importKVStore(v, name, r, archive.Manifest.Stores[name], setup)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		imported[name] = true
+	}
+
+	for _, name := range storeNames(archive.Manifest.Stores) {
+		if !imported[name] {
+			return fmt.Errorf("%s: missing from the archive", name)
+		}
+	}
+
+	return nil
+}
+
+func importKVStore(v *viper.Viper, name string, r io.Reader, expected kvstore.Summary, setup bool) error {
+	store, err := newStore(v, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer store.Close()
+
+	if setup {
+		if err := store.Setup(); err != nil {
+			return fmt.Errorf("%s: setup: %w", name, err)
+		}
+	}
+
+	loaded, err := kvstore.Load(store, r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if loaded != expected {
+		return fmt.Errorf("%s: the archived contents do not match the manifest (the archive may be corrupt)", name)
+	}
+
+	log.Infow("store imported", "store", name, "keys", loaded.Keys)
+
+	return verifyStore(name, kvSummarizable{store}, expected)
+}
+
+func importEndorsementStore(v *viper.Viper, r io.Reader, expected kvstore.Summary, setup bool) error {
+	name := EndorsementStoreName
+
+	if setup {
+		if err := setupEndorsementStore(v); err != nil {
+			return fmt.Errorf("%s: setup: %w", name, err)
+		}
+	}
+
+	store, err := newEndorsementStore(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer store.Close()
+
+	loaded, err := store.Load(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if loaded != expected {
+		return fmt.Errorf("%s: the archived contents do not match the manifest (the archive may be corrupt)", name)
+	}
+
+	log.Infow("store imported", "store", name, "rows", loaded.Keys)
+
+	return verifyStore(name, store, expected)
+}
+
+func verifyStores(v *viper.Viper, archivePath string) error {
+	archive, err := openArchive(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	var failed []string
+
+	for _, name := range storeNames(archive.Manifest.Stores) {
+		var store summarizable
+
+		if name == EndorsementStoreName {
+			s, err := newEndorsementStore(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			defer s.Close()
+
+			store = s
+		} else {
+			s, err := newStore(v, name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			defer s.Close()
+
+			store = kvSummarizable{s}
+		}
+
+		if err := verifyStore(name, store, archive.Manifest.Stores[name]); err != nil {
+			log.Error(err)
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("inconsistent stores: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// summarizable is a store whose contents may be checked against an archive.
+type summarizable interface {
+	Summarize() (kvstore.Summary, error)
+}
+
+type kvSummarizable struct {
+	kvstore.IKVStore
+}
+
+func (o kvSummarizable) Summarize() (kvstore.Summary, error) {
+	return kvstore.Summarize(o.IKVStore)
+}
+
+func verifyStore(name string, store summarizable, expected kvstore.Summary) error {
+	actual, err := store.Summarize()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if actual != expected {
+		return fmt.Errorf(
+			"%s: the contents do not match the archive: expected %d keys (digest %s), found %d keys (digest %s)",
+			name, expected.Keys, expected.Digest, actual.Keys, actual.Digest,
+		)
+	}
+
+	log.Infow("store verified", "store", name, "keys", actual.Keys, "digest", actual.Digest)
+
+	return nil
+}
+
+// newStore returns the kvstore with the specified name.
+var newStore = func(v *viper.Viper, name string) (kvstore.IKVStore, error) {
+	for _, s := range Stores {
+		if s.Name != name {
+			continue
+		}
+
+		storeCfg := v.Sub(s.ConfigKey)
+		if storeCfg == nil {
+			return nil, fmt.Errorf("not configured (%s)", s.ConfigKey)
+		}
+
+		return kvstore.New(storeCfg, log.Named(name))
+	}
+
+	return nil, errors.New("unknown store")
+}
+
+// configuredStoreNames returns the names of the configured stores, including
+// the endorsement store.
+func configuredStoreNames(v *viper.Viper) []string {
+	var names []string
+	for _, s := range Stores {
+		if v.Sub(s.ConfigKey) != nil {
+			names = append(names, s.Name)
+		}
+	}
+
+	if v.Sub(endorsementStoreConfigKey) != nil {
+		names = append(names, EndorsementStoreName)
+	}
+
+	return names
+}
+
+func storeConfigKeys() []string {
+	keys := make([]string, len(Stores), len(Stores)+1)
+	for i, s := range Stores {
+		keys[i] = s.ConfigKey
+	}
+
+	return append(keys, endorsementStoreConfigKey)
+}
+
+func storeNames(stores map[string]kvstore.Summary) []string {
+	names := make([]string, 0, len(stores))
+	for name := range stores {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
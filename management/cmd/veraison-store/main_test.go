@@ -0,0 +1,248 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/kvstore"
+	"github.com/veraison/services/log"
+)
+
+// testEndorsementSchema mimics that of the endorsement store: environments
+// refer to the manifests they are described by, so the manifests must be
+// loaded first.
+var testEndorsementSchema = []string{
+	`CREATE TABLE manifests (
+		id INTEGER PRIMARY KEY,
+		label TEXT NOT NULL,
+		active BOOLEAN NOT NULL,
+		data BLOB NOT NULL,
+		weight REAL,
+		added TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE environments (
+		id INTEGER PRIMARY KEY,
+		manifest_id INTEGER NOT NULL REFERENCES manifests(id),
+		vendor TEXT
+	)`,
+}
+
+// newTestConfig returns the configuration of a deployment whose stores are
+// in dir: the kvstores use the specified backend, and the endorsement store
+// uses sqlite3.
+func newTestConfig(t *testing.T, dir, backend string) *viper.Viper {
+	v := viper.New()
+
+	for _, s := range Stores {
+		v.Set(s.ConfigKey+".backend", backend)
+
+		if backend == "sql" {
+			v.Set(s.ConfigKey+".sql.driver", "sqlite3")
+			v.Set(s.ConfigKey+".sql.datasource", filepath.Join(dir, s.Name+".sql"))
+		}
+	}
+
+	v.Set(endorsementStoreConfigKey+".dbms", "sqlite3")
+	v.Set(endorsementStoreConfigKey+".dsn",
+		"file:"+filepath.Join(dir, "endorsements.sql")+"?_foreign_keys=1")
+
+	return v
+}
+
+// newTestStores sets up the stores configured in v, using persistent memory
+// stores if the kvstores use the memory backend, and creating the tables of
+// the endorsement store.
+func newTestStores(t *testing.T, v *viper.Viper) map[string]kvstore.IKVStore {
+	stores := make(map[string]kvstore.IKVStore)
+
+	for _, s := range Stores {
+		store, err := kvstore.New(v.Sub(s.ConfigKey), log.Named("test"))
+		require.NoError(t, err)
+		require.NoError(t, store.Setup())
+
+		stores[s.Name] = store
+	}
+
+	if v.GetString(Stores[0].ConfigKey+".backend") == "memory" {
+		old := newStore
+		newStore = func(_ *viper.Viper, name string) (kvstore.IKVStore, error) {
+			store, ok := stores[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown store")
+			}
+
+			return store, nil
+		}
+		t.Cleanup(func() { newStore = old })
+	} else {
+		for _, store := range stores {
+			require.NoError(t, store.Close())
+		}
+	}
+
+	es, err := newEndorsementStore(v)
+	require.NoError(t, err)
+	defer es.Close()
+
+	for _, stmt := range testEndorsementSchema {
+		_, err := es.DB.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	return stores
+}
+
+func populateTestStores(t *testing.T, v *viper.Viper) {
+	for _, s := range Stores {
+		store, err := newStore(v, s.Name)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, store.Add(fmt.Sprintf("0:%s:%d", s.Name, i), `{"n": 1}`))
+		}
+
+		require.NoError(t, store.Close())
+	}
+
+	es, err := newEndorsementStore(v)
+	require.NoError(t, err)
+	defer es.Close()
+
+	added := time.Date(2026, 10, 18, 12, 30, 15, 123456789, time.UTC)
+
+	for _, stmt := range []struct {
+		query string
+		args  []any
+	}{
+		{
+			"INSERT INTO manifests VALUES (?, ?, ?, ?, ?, ?)",
+			[]any{1, "0/PSA_IOT", true, []byte{0xd9, 0x01, 0xf5}, 0.5, added},
+		},
+		{
+			"INSERT INTO manifests VALUES (?, ?, ?, ?, ?, ?)",
+			[]any{2, "acme/PSA_IOT", false, []byte{0xd9, 0x01, 0xf4}, nil, added},
+		},
+		{"INSERT INTO environments VALUES (?, ?, ?)", []any{1, 2, "ACME"}},
+		{"INSERT INTO environments VALUES (?, ?, ?)", []any{2, 1, nil}},
+	} {
+		_, err := es.DB.Exec(stmt.query, stmt.args...)
+		require.NoError(t, err)
+	}
+}
+
+func testRoundTrip(t *testing.T, backend string) {
+	src := newTestConfig(t, t.TempDir(), backend)
+	newTestStores(t, src)
+	populateTestStores(t, src)
+
+	archivePath := filepath.Join(t.TempDir(), "stores.tar.gz")
+	require.NoError(t, exportStores(src, archivePath))
+
+	archive, err := openArchive(archivePath)
+	require.NoError(t, err)
+	manifest := archive.Manifest
+	archive.Close()
+
+	assert.Len(t, manifest.Stores, len(Stores)+1)
+	assert.Equal(t, 3, manifest.Stores["po-store"].Keys)
+	assert.Equal(t, 4, manifest.Stores[EndorsementStoreName].Keys)
+
+	dst := newTestConfig(t, t.TempDir(), backend)
+	dstStores := newTestStores(t, dst)
+
+	require.NoError(t, importStores(dst, archivePath, false))
+	require.NoError(t, verifyStores(dst, archivePath))
+
+	es, err := newEndorsementStore(dst)
+	require.NoError(t, err)
+	defer es.Close()
+
+	tables, err := es.Tables()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manifests", "environments"}, tables)
+
+	var (
+		label  string
+		active bool
+		data   []byte
+		added  time.Time
+	)
+
+	require.NoError(t, es.DB.QueryRow(
+		"SELECT label, active, data, added FROM manifests WHERE id = 2",
+	).Scan(&label, &active, &data, &added))
+	assert.Equal(t, "acme/PSA_IOT", label)
+	assert.False(t, active)
+	assert.Equal(t, []byte{0xd9, 0x01, 0xf4}, data)
+	assert.Equal(t, time.Date(2026, 10, 18, 12, 30, 15, 123456789, time.UTC), added.UTC())
+
+	// changes to either kind of store are detected
+	_, err = es.DB.Exec("UPDATE manifests SET active = 1 WHERE id = 2")
+	require.NoError(t, err)
+
+	err = verifyStores(dst, archivePath)
+	assert.EqualError(t, err, "inconsistent stores: "+EndorsementStoreName)
+
+	_, err = es.DB.Exec("UPDATE manifests SET active = 0 WHERE id = 2")
+	require.NoError(t, err)
+
+	store := dstStores["tenant-store"]
+	if backend != "memory" {
+		store, err = newStore(dst, "tenant-store")
+		require.NoError(t, err)
+		defer store.Close()
+	}
+	require.NoError(t, store.Add("0:extra", "{}"))
+
+	err = verifyStores(dst, archivePath)
+	assert.EqualError(t, err, "inconsistent stores: tenant-store")
+}
+
+func TestStores_round_trip_memory(t *testing.T) {
+	testRoundTrip(t, "memory")
+}
+
+func TestStores_round_trip_sql(t *testing.T) {
+	testRoundTrip(t, "sql")
+}
+
+func TestEndorsementStore_Load_already_in_store(t *testing.T) {
+	src := newTestConfig(t, t.TempDir(), "sql")
+	newTestStores(t, src)
+	populateTestStores(t, src)
+
+	srcStore, err := newEndorsementStore(src)
+	require.NoError(t, err)
+	defer srcStore.Close()
+
+	var buf bytes.Buffer
+	_, err = srcStore.Dump(&buf)
+	require.NoError(t, err)
+
+	dst := newTestConfig(t, t.TempDir(), "sql")
+	newTestStores(t, dst)
+
+	dstStore, err := newEndorsementStore(dst)
+	require.NoError(t, err)
+	defer dstStore.Close()
+
+	_, err = dstStore.DB.Exec(
+		"INSERT INTO manifests VALUES (2, 'acme/PSA_IOT', 1, x'00', NULL, '2026-10-18 12:00:00')")
+	require.NoError(t, err)
+
+	_, err = dstStore.Load(&buf)
+	assert.ErrorContains(t, err, "row 2: manifests")
+
+	// none of the rows were added
+	summary, err := dstStore.Summarize()
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Keys)
+}
@@ -0,0 +1,361 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	corimstore "github.com/veraison/corim-store/pkg/store"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/services/handler"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/provenance"
+	"github.com/veraison/services/vts/store"
+)
+
+var ErrNoEndorsementStore = errors.New("endorsement store is not configured")
+var ErrNoProvenanceStore = errors.New("provenance store is not configured")
+var ErrBadEnvironment = errors.New("bad environment filter")
+
+// IEndorsementStore is the subset of the corim-store API used to browse the
+// provisioned endorsements.
+type IEndorsementStore interface {
+	GetActiveKeyTriples(env *comid.Environment, label string, exact bool) ([]*comid.KeyTriple, error)
+	GetActiveValueTriples(env *comid.Environment, label string, exact bool) ([]*comid.ValueTriple, error)
+	Close() error
+}
+
+// EndorsementManager provides read-only access to the trust anchors and
+// reference values the VTS matches evidence against.
+type EndorsementManager struct {
+	Store IEndorsementStore
+
+	// Provenance is used to look up the CoRIMs the endorsements originated
+	// from. It is nil if the provenance store is not used.
+	Provenance *provenance.Store
+}
+
+// TrustAnchorOrigin associates a provisioned trust anchor with the CoRIM it
+// originated from.
+type TrustAnchorOrigin struct {
+	// ID identifies the trust anchor (see handler.VerificationKeyIDs).
+	ID string `json:"id"`
+	// TrustAnchor is a key triple containing only the trust anchor's key.
+	TrustAnchor *comid.KeyTriple `json:"trust-anchor"`
+	// CoRIM describes the CoRIM the trust anchor was last provisioned
+	// from. It is nil if this is not known (e.g. because the trust anchor
+	// was provisioned before the provenance store was configured).
+	CoRIM *provenance.Record `json:"corim"`
+}
+
+// ReferenceValueOrigin associates a provisioned reference value with the CoRIM
+// it originated from.
+type ReferenceValueOrigin struct {
+	// ID identifies the reference value (see handler.EndorsementIDs).
+	ID string `json:"id"`
+	// ReferenceValue is a value triple containing only the reference
+	// value's measurement.
+	ReferenceValue *comid.ValueTriple `json:"reference-value"`
+	// CoRIM describes the CoRIM the reference value was last provisioned
+	// from. It is nil if this is not known.
+	CoRIM *provenance.Record `json:"corim"`
+}
+
+// CreateEndorsementManagerFromConfig creates a new EndorsementManager using
+// the endorsement store configuration under the "store" directive of the
+// specified Viper (this is the same configuration used by the VTS). If the
+// directive is absent, the returned manager will report ErrNoEndorsementStore.
+// If the "provenance-store" directive is present, the provenance store it
+// configures is used to report the CoRIMs the endorsements originated from.
+func CreateEndorsementManagerFromConfig(v *viper.Viper, name string) (*EndorsementManager, error) {
+	var manager *EndorsementManager
+
+	storeCfg := v.Sub("store")
+	if storeCfg == nil {
+		manager = NewEndorsementManager(nil)
+	} else {
+		enStore, err := store.New(storeCfg, log.Named(name+"-store"))
+		if err != nil {
+			return nil, err
+		}
+
+		manager = NewEndorsementManager(enStore)
+	}
+
+	if provenanceCfg := v.Sub("provenance-store"); provenanceCfg != nil {
+		provenanceStore, err := provenance.NewStore(provenanceCfg, log.Named(name+"-provenance-store"))
+		if err != nil {
+			manager.Close()
+			return nil, err
+		}
+
+		manager.Provenance = provenanceStore
+	}
+
+	return manager, nil
+}
+
+func NewEndorsementManager(store IEndorsementStore) *EndorsementManager {
+	return &EndorsementManager{Store: store}
+}
+
+// GetTrustAnchors returns the active trust anchors provisioned for the
+// specified tenant and scheme whose environments match env. A nil env matches
+// all trust anchors.
+func (o *EndorsementManager) GetTrustAnchors(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	env *comid.Environment,
+) ([]*comid.KeyTriple, error) {
+	if o.Store == nil {
+		return nil, ErrNoEndorsementStore
+	}
+
+	triples, err := o.Store.GetActiveKeyTriples(
+		normalizeEnvironment(env), endorsementLabel(tenantID, scheme), false)
+	if err != nil && !errors.Is(err, corimstore.ErrNoMatch) {
+		return nil, err
+	}
+
+	if triples == nil {
+		triples = []*comid.KeyTriple{}
+	}
+
+	return triples, nil
+}
+
+// GetReferenceValues returns the active reference values provisioned for the
+// specified tenant and scheme whose environments match env. A nil env matches
+// all reference values.
+func (o *EndorsementManager) GetReferenceValues(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	env *comid.Environment,
+) ([]*comid.ValueTriple, error) {
+	if o.Store == nil {
+		return nil, ErrNoEndorsementStore
+	}
+
+	triples, err := o.Store.GetActiveValueTriples(
+		normalizeEnvironment(env), endorsementLabel(tenantID, scheme), false)
+	if err != nil && !errors.Is(err, corimstore.ErrNoMatch) {
+		return nil, err
+	}
+
+	if triples == nil {
+		triples = []*comid.ValueTriple{}
+	}
+
+	return triples, nil
+}
+
+// GetTrustAnchorOrigins returns the active trust anchors provisioned for the
+// specified tenant and scheme whose environments match env (as per
+// GetTrustAnchors), along with the CoRIMs they originated from. Each key of a
+// key triple is reported separately.
+func (o *EndorsementManager) GetTrustAnchorOrigins(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	env *comid.Environment,
+) ([]TrustAnchorOrigin, error) {
+	if o.Provenance == nil {
+		return nil, ErrNoProvenanceStore
+	}
+
+	triples, err := o.GetTrustAnchors(ctx, tenantID, scheme, env)
+	if err != nil {
+		return nil, err
+	}
+
+	label := endorsementLabel(tenantID, scheme)
+	ret := []TrustAnchorOrigin{}
+
+	for _, triple := range triples {
+		ids, err := handler.VerificationKeyIDs(triple)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, id := range ids {
+			rec, err := o.getProvenance(label, id)
+			if err != nil {
+				return nil, err
+			}
+
+			ret = append(ret, TrustAnchorOrigin{
+				ID: id,
+				TrustAnchor: &comid.KeyTriple{
+					Environment: triple.Environment,
+					VerifKeys:   comid.CryptoKeys{triple.VerifKeys[i]},
+					Conditions:  triple.Conditions,
+				},
+				CoRIM: rec,
+			})
+		}
+	}
+
+	return ret, nil
+}
+
+// GetReferenceValueOrigins returns the active reference values provisioned for
+// the specified tenant and scheme whose environments match env (as per
+// GetReferenceValues), along with the CoRIMs they originated from. Each
+// measurement of a value triple is reported separately.
+func (o *EndorsementManager) GetReferenceValueOrigins(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	env *comid.Environment,
+) ([]ReferenceValueOrigin, error) {
+	if o.Provenance == nil {
+		return nil, ErrNoProvenanceStore
+	}
+
+	triples, err := o.GetReferenceValues(ctx, tenantID, scheme, env)
+	if err != nil {
+		return nil, err
+	}
+
+	label := endorsementLabel(tenantID, scheme)
+	ret := []ReferenceValueOrigin{}
+
+	for _, triple := range triples {
+		ids, err := handler.EndorsementIDs(triple)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, id := range ids {
+			rec, err := o.getProvenance(label, id)
+			if err != nil {
+				return nil, err
+			}
+
+			single := comid.ValueTriple{Environment: triple.Environment}
+			single.Measurements.Add(&triple.Measurements.Values[i])
+
+			ret = append(ret, ReferenceValueOrigin{
+				ID:             id,
+				ReferenceValue: &single,
+				CoRIM:          rec,
+			})
+		}
+	}
+
+	return ret, nil
+}
+
+func (o *EndorsementManager) Close() error {
+	if o.Provenance != nil {
+		if err := o.Provenance.Close(); err != nil {
+			return err
+		}
+	}
+
+	if o.Store == nil {
+		return nil
+	}
+
+	return o.Store.Close()
+}
+
+// getProvenance returns the provenance record for the endorsement with the
+// specified ID, or nil if there isn't one.
+func (o *EndorsementManager) getProvenance(label, id string) (*provenance.Record, error) {
+	rec, err := o.Provenance.Get(label, id)
+	if err != nil {
+		if errors.Is(err, provenance.ErrNoRecord) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// NewEnvironmentFilter returns the environment used to filter endorsements
+// based on the specified class ID, instance ID, vendor and model, any of which
+// may be empty. Class and instance IDs are specified as "<type>:<value>", where
+// the value is in the same form as in CoRIM JSON templates, e.g.
+// "uuid:31fb5abf-023e-4992-aa4e-95f9c1503bfa", or "ueid:<base64>". nil is
+// returned if none of the components are specified.
+func NewEnvironmentFilter(classID, instanceID, vendor, model string) (*comid.Environment, error) {
+	var env comid.Environment
+
+	if classID != "" || vendor != "" || model != "" {
+		env.Class = &comid.Class{}
+	}
+
+	if classID != "" {
+		var id comid.ClassID
+		if err := unmarshalTypeChoice(classID, &id); err != nil {
+			return nil, fmt.Errorf("%w: class-id: %v", ErrBadEnvironment, err)
+		}
+		env.Class.ClassID = &id
+	}
+
+	if vendor != "" {
+		env.Class.SetVendor(vendor)
+	}
+
+	if model != "" {
+		env.Class.SetModel(model)
+	}
+
+	if instanceID != "" {
+		var inst comid.Instance
+		if err := unmarshalTypeChoice(instanceID, &inst); err != nil {
+			return nil, fmt.Errorf("%w: instance-id: %v", ErrBadEnvironment, err)
+		}
+		env.Instance = &inst
+	}
+
+	if env.Class == nil && env.Instance == nil {
+		return nil, nil
+	}
+
+	return &env, nil
+}
+
+// unmarshalTypeChoice unmarshals a "<type>:<value>" string into dest via its
+// JSON representation ({"type": "<type>", "value": "<value>"}).
+func unmarshalTypeChoice(text string, dest json.Unmarshaler) error {
+	typ, val, ok := strings.Cut(text, ":")
+	if !ok || typ == "" || val == "" {
+		return fmt.Errorf("%q is not of the form <type>:<value>", text)
+	}
+
+	data, err := json.Marshal(map[string]string{"type": typ, "value": val})
+	if err != nil {
+		return err
+	}
+
+	return dest.UnmarshalJSON(data)
+}
+
+// normalizeEnvironment returns the environment used to query the store; with
+// inexact matching, an empty environment matches all triples under a label.
+func normalizeEnvironment(env *comid.Environment) *comid.Environment {
+	if env == nil {
+		return &comid.Environment{}
+	}
+
+	return env
+}
+
+// endorsementLabel returns the label under which the endorsements for the
+// specified tenant and scheme are kept in the store (see
+// appraisal.Context.StoreLabel).
+func endorsementLabel(tenantID, scheme string) string {
+	return fmt.Sprintf("%s/%s", tenantID, scheme)
+}
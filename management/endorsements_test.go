@@ -0,0 +1,320 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package management
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corimstore "github.com/veraison/corim-store/pkg/store"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/services/handler"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/provenance"
+	"github.com/veraison/swid"
+)
+
+type fakeEndorsementStore struct {
+	keyTriples   []*comid.KeyTriple
+	valueTriples []*comid.ValueTriple
+	err          error
+
+	env   *comid.Environment
+	label string
+	exact bool
+}
+
+func (o *fakeEndorsementStore) GetActiveKeyTriples(
+	env *comid.Environment,
+	label string,
+	exact bool,
+) ([]*comid.KeyTriple, error) {
+	o.env, o.label, o.exact = env, label, exact
+	return o.keyTriples, o.err
+}
+
+func (o *fakeEndorsementStore) GetActiveValueTriples(
+	env *comid.Environment,
+	label string,
+	exact bool,
+) ([]*comid.ValueTriple, error) {
+	o.env, o.label, o.exact = env, label, exact
+	return o.valueTriples, o.err
+}
+
+func (o *fakeEndorsementStore) Close() error {
+	return nil
+}
+
+func newTestEnvironment() comid.Environment {
+	return comid.Environment{
+		Class: comid.NewClassUUID(comid.TestUUID).
+			SetVendor("ACME").
+			SetModel("RoadRunner"),
+	}
+}
+
+func newTestValueTriple(names ...string) *comid.ValueTriple {
+	triple := comid.ValueTriple{Environment: newTestEnvironment()}
+
+	for i, name := range names {
+		m := comid.Measurement{}
+		m.Val.Name = &name
+		m.AddDigest(int(swid.Sha256), append([]byte{byte(i + 1)}, make([]byte, 31)...))
+		triple.Measurements.Add(&m)
+	}
+
+	return &triple
+}
+
+func newTestKeyTriple(t *testing.T) *comid.KeyTriple {
+	key, err := comid.NewPKIXBase64Key(comid.TestECPubKey)
+	require.NoError(t, err)
+
+	return &comid.KeyTriple{
+		Environment: newTestEnvironment(),
+		VerifKeys:   comid.CryptoKeys{key},
+	}
+}
+
+func newTestProvenanceStore(t *testing.T) *provenance.Store {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := provenance.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestEndorsementManager_no_store(t *testing.T) {
+	manager := NewEndorsementManager(nil)
+
+	_, err := manager.GetTrustAnchors(context.Background(), "0", "PSA_IOT", nil)
+	assert.ErrorIs(t, err, ErrNoEndorsementStore)
+
+	_, err = manager.GetReferenceValues(context.Background(), "0", "PSA_IOT", nil)
+	assert.ErrorIs(t, err, ErrNoEndorsementStore)
+
+	assert.NoError(t, manager.Close())
+}
+
+func TestEndorsementManager_GetTrustAnchors(t *testing.T) {
+	triple := newTestKeyTriple(t)
+	store := &fakeEndorsementStore{keyTriples: []*comid.KeyTriple{triple}}
+	manager := NewEndorsementManager(store)
+
+	ret, err := manager.GetTrustAnchors(context.Background(), "7", "PSA_IOT", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*comid.KeyTriple{triple}, ret)
+	assert.Equal(t, "7/PSA_IOT", store.label)
+	assert.Equal(t, &comid.Environment{}, store.env)
+	assert.False(t, store.exact)
+
+	env := newTestEnvironment()
+	_, err = manager.GetTrustAnchors(context.Background(), "7", "PSA_IOT", &env)
+	require.NoError(t, err)
+	assert.Same(t, &env, store.env)
+}
+
+func TestEndorsementManager_GetReferenceValues(t *testing.T) {
+	triple := newTestValueTriple("BL")
+	store := &fakeEndorsementStore{valueTriples: []*comid.ValueTriple{triple}}
+	manager := NewEndorsementManager(store)
+
+	ret, err := manager.GetReferenceValues(context.Background(), "0", "ARM_CCA", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*comid.ValueTriple{triple}, ret)
+	assert.Equal(t, "0/ARM_CCA", store.label)
+	assert.False(t, store.exact)
+}
+
+func TestEndorsementManager_no_match(t *testing.T) {
+	for _, storeErr := range []error{nil, corimstore.ErrNoMatch} {
+		manager := NewEndorsementManager(&fakeEndorsementStore{err: storeErr})
+
+		keys, err := manager.GetTrustAnchors(context.Background(), "0", "PSA_IOT", nil)
+		require.NoError(t, err)
+		assert.NotNil(t, keys)
+		assert.Empty(t, keys)
+
+		vals, err := manager.GetReferenceValues(context.Background(), "0", "PSA_IOT", nil)
+		require.NoError(t, err)
+		assert.NotNil(t, vals)
+		assert.Empty(t, vals)
+	}
+}
+
+func TestEndorsementManager_store_error(t *testing.T) {
+	storeErr := errors.New("boom")
+	manager := NewEndorsementManager(&fakeEndorsementStore{err: storeErr})
+
+	_, err := manager.GetTrustAnchors(context.Background(), "0", "PSA_IOT", nil)
+	assert.ErrorIs(t, err, storeErr)
+
+	_, err = manager.GetReferenceValues(context.Background(), "0", "PSA_IOT", nil)
+	assert.ErrorIs(t, err, storeErr)
+}
+
+func TestEndorsementManager_origins_no_provenance_store(t *testing.T) {
+	manager := NewEndorsementManager(&fakeEndorsementStore{})
+
+	_, err := manager.GetTrustAnchorOrigins(context.Background(), "0", "PSA_IOT", nil)
+	assert.ErrorIs(t, err, ErrNoProvenanceStore)
+
+	_, err = manager.GetReferenceValueOrigins(context.Background(), "0", "PSA_IOT", nil)
+	assert.ErrorIs(t, err, ErrNoProvenanceStore)
+}
+
+func TestEndorsementManager_GetReferenceValueOrigins(t *testing.T) {
+	provisioned := newTestValueTriple("BL")
+	// the store merges measurements for the same environment provisioned
+	// by different CoRIMs into a single triple
+	active := newTestValueTriple("BL", "PRoT")
+
+	c := comid.Comid{}
+	c.SetTagIdentity("43BBE37F-2E61-4B33-AED3-53CFF1428B16", 0)
+	c.AddReferenceValue(provisioned)
+	uc := corim.NewUnsignedCorim().SetID("corim-1").AddComid(&c)
+	require.NotNil(t, uc)
+
+	rec := provenance.Record{
+		TagID:       "corim-1",
+		Submitter:   "alice",
+		SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	provenanceStore := newTestProvenanceStore(t)
+	require.NoError(t, provenanceStore.Add("0/PSA_IOT", uc, rec))
+
+	manager := NewEndorsementManager(
+		&fakeEndorsementStore{valueTriples: []*comid.ValueTriple{active}})
+	manager.Provenance = provenanceStore
+
+	ret, err := manager.GetReferenceValueOrigins(context.Background(), "0", "PSA_IOT", nil)
+	require.NoError(t, err)
+	require.Len(t, ret, 2)
+
+	ids, err := handler.EndorsementIDs(active)
+	require.NoError(t, err)
+
+	assert.Equal(t, ids[0], ret[0].ID)
+	assert.Equal(t, &rec, ret[0].CoRIM)
+	require.Len(t, ret[0].ReferenceValue.Measurements.Values, 1)
+	assert.Equal(t, "BL", *ret[0].ReferenceValue.Measurements.Values[0].Val.Name)
+	assert.Equal(t, active.Environment, ret[0].ReferenceValue.Environment)
+
+	// the ID of a single-measurement triple is that of the measurement
+	singleIDs, err := handler.EndorsementIDs(ret[0].ReferenceValue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ids[0]}, singleIDs)
+
+	assert.Equal(t, ids[1], ret[1].ID)
+	assert.Nil(t, ret[1].CoRIM)
+	assert.Equal(t, "PRoT", *ret[1].ReferenceValue.Measurements.Values[0].Val.Name)
+
+	ret, err = manager.GetReferenceValueOrigins(context.Background(), "1", "PSA_IOT", nil)
+	require.NoError(t, err)
+	require.Len(t, ret, 2)
+	assert.Nil(t, ret[0].CoRIM)
+}
+
+func TestEndorsementManager_GetTrustAnchorOrigins(t *testing.T) {
+	triple := newTestKeyTriple(t)
+	other, err := comid.NewCryptoKeyTaggedBytes([]byte{0xde, 0xad, 0xbe, 0xef})
+	require.NoError(t, err)
+
+	active := *triple
+	active.VerifKeys = comid.CryptoKeys{triple.VerifKeys[0], other}
+
+	c := comid.Comid{}
+	c.SetTagIdentity("43BBE37F-2E61-4B33-AED3-53CFF1428B16", 0)
+	c.AddAttestVerifKey(triple)
+	uc := corim.NewUnsignedCorim().SetID("corim-1").AddComid(&c)
+	require.NotNil(t, uc)
+
+	rec := provenance.Record{
+		TagID:       "corim-1",
+		SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Signer:      "CN=ACME Signer",
+	}
+
+	provenanceStore := newTestProvenanceStore(t)
+	require.NoError(t, provenanceStore.Add("0/PSA_IOT", uc, rec))
+
+	manager := NewEndorsementManager(
+		&fakeEndorsementStore{keyTriples: []*comid.KeyTriple{&active}})
+	manager.Provenance = provenanceStore
+
+	ret, err := manager.GetTrustAnchorOrigins(context.Background(), "0", "PSA_IOT", nil)
+	require.NoError(t, err)
+	require.Len(t, ret, 2)
+
+	ids, err := handler.VerificationKeyIDs(&active)
+	require.NoError(t, err)
+
+	assert.Equal(t, ids[0], ret[0].ID)
+	assert.Equal(t, &rec, ret[0].CoRIM)
+	assert.Equal(t, comid.CryptoKeys{triple.VerifKeys[0]}, ret[0].TrustAnchor.VerifKeys)
+
+	assert.Equal(t, ids[1], ret[1].ID)
+	assert.Nil(t, ret[1].CoRIM)
+	assert.Equal(t, comid.CryptoKeys{other}, ret[1].TrustAnchor.VerifKeys)
+}
+
+func TestNewEnvironmentFilter(t *testing.T) {
+	env, err := NewEnvironmentFilter("", "", "", "")
+	require.NoError(t, err)
+	assert.Nil(t, env)
+
+	env, err = NewEnvironmentFilter("", "", "ACME", "")
+	require.NoError(t, err)
+	require.NotNil(t, env.Class)
+	assert.Equal(t, "ACME", *env.Class.Vendor)
+	assert.Nil(t, env.Class.Model)
+	assert.Nil(t, env.Class.ClassID)
+	assert.Nil(t, env.Instance)
+
+	env, err = NewEnvironmentFilter(
+		"uuid:31fb5abf-023e-4992-aa4e-95f9c1503bfa", "", "ACME", "RoadRunner")
+	require.NoError(t, err)
+	require.NotNil(t, env.Class.ClassID)
+	assert.Equal(t, "31fb5abf-023e-4992-aa4e-95f9c1503bfa", env.Class.ClassID.String())
+	assert.Equal(t, "ACME", *env.Class.Vendor)
+	assert.Equal(t, "RoadRunner", *env.Class.Model)
+
+	env, err = NewEnvironmentFilter("oid:2.16.840.1.113741.1.2.3", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2.16.840.1.113741.1.2.3", env.Class.ClassID.String())
+
+	env, err = NewEnvironmentFilter("", "ueid:AQECAwQFBgcICQoLDA0ODxA=", "", "")
+	require.NoError(t, err)
+	assert.Nil(t, env.Class)
+	require.NotNil(t, env.Instance)
+	assert.Equal(t, "ueid", env.Instance.Type())
+}
+
+func TestNewEnvironmentFilter_bad(t *testing.T) {
+	for _, tc := range []struct {
+		classID    string
+		instanceID string
+		expected   string
+	}{
+		{"31fb5abf-023e-4992-aa4e-95f9c1503bfa", "", "class-id"},
+		{"uuid:", "", "class-id"},
+		{"uuid:not-a-uuid", "", "class-id"},
+		{"foo:bar", "", "class-id"},
+		{"", "ueid", "instance-id"},
+		{"", "ueid:!!!", "instance-id"},
+	} {
+		_, err := NewEnvironmentFilter(tc.classID, tc.instanceID, "", "")
+		assert.ErrorIs(t, err, ErrBadEnvironment, tc)
+		assert.ErrorContains(t, err, tc.expected, tc)
+	}
+}
@@ -5,7 +5,11 @@ package management
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
@@ -17,6 +21,10 @@ import (
 	"github.com/veraison/services/policy"
 )
 
+var ErrBadPolicyName = errors.New("bad policy name")
+var ErrBadCursor = errors.New("bad cursor")
+var ErrNoDecisionLog = errors.New("policy decision logging is not enabled")
+
 type PolicyManager struct {
 	Agent            policy.IAgent
 	Store            *policy.Store
@@ -83,31 +91,39 @@ func (o *PolicyManager) IsSchemeSupported(scheme string) bool {
 	return false
 }
 
-func (o *PolicyManager) Validate(ctx context.Context, policyRules string) error {
-	return o.Agent.Validate(ctx, policyRules)
+func (o *PolicyManager) Validate(ctx context.Context, policyType string, policyRules string) error {
+	return o.Agent.Validate(ctx, policyType, policyRules)
 }
 
 func (o *PolicyManager) Update(
 	ctx context.Context,
 	tenantID string,
 	scheme string,
+	policyName string,
 	name string,
+	policyType string,
 	rules string,
+	user string,
 ) (*policy.Policy, error) {
-	key, err := o.resolvePolicyKey(tenantID, scheme)
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
 	if err != nil {
 		return nil, err
 	}
 
-	return o.Store.Update(key, name, o.Agent.GetBackendName(), rules)
+	if policyType == "" {
+		policyType = o.Agent.GetBackendName()
+	}
+
+	return o.Store.Update(key, name, policyType, rules, user)
 }
 
 func (o *PolicyManager) GetActive(
 	ctx context.Context,
 	tenantID string,
 	scheme string,
+	policyName string,
 ) (*policy.Policy, error) {
-	key, err := o.resolvePolicyKey(tenantID, scheme)
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
 	if err != nil {
 		return nil, err
 	}
@@ -119,9 +135,10 @@ func (o *PolicyManager) GetPolicy(
 	ctx context.Context,
 	tenantID string,
 	scheme string,
+	policyName string,
 	policyID uuid.UUID,
 ) (*policy.Policy, error) {
-	key, err := o.resolvePolicyKey(tenantID, scheme)
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
 	if err != nil {
 		return nil, err
 	}
@@ -133,9 +150,10 @@ func (o *PolicyManager) GetPolicies(
 	ctx context.Context,
 	tenantID string,
 	scheme string,
+	policyName string,
 	name string,
 ) ([]*policy.Policy, error) {
-	key, err := o.resolvePolicyKey(tenantID, scheme)
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
 	if err != nil {
 		return nil, err
 	}
@@ -160,26 +178,173 @@ func (o *PolicyManager) GetPolicies(
 	return ret, nil
 }
 
+// ListPolicies returns all versions of a page of up to limit of the tenant's
+// policies for the scheme (with any policy name), following the specified
+// cursor, along with the cursor for the next page (empty, if there are no
+// further policies). If limit is not positive, all remaining policies are
+// returned. If name is specified, only versions with that name are returned.
+// Cursors are opaque to callers.
+func (o *PolicyManager) ListPolicies(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	name string,
+	cursor string,
+	limit int,
+) ([]*policy.Policy, string, error) {
+	// resolve the key of the default policy to validate the tenant and scheme
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	key.Name = ""
+	prefix := key.String()
+
+	storeCursor, err := decodeCursor(cursor, prefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	policies, next, err := o.Store.ListVersionsPage(prefix, storeCursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if name != "" {
+		ret := make([]*policy.Policy, 0, len(policies))
+		for _, pol := range policies {
+			if pol.Name == name {
+				ret = append(ret, pol)
+			}
+		}
+		policies = ret
+	}
+
+	if policies == nil {
+		policies = make([]*policy.Policy, 0)
+	}
+
+	return policies, encodeCursor(next), nil
+}
+
 func (o *PolicyManager) Activate(
 	ctx context.Context,
 	tenantID string,
 	scheme string,
+	policyName string,
+	policyID uuid.UUID,
+	at time.Time,
+	user string,
+) error {
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
+	if err != nil {
+		return err
+	}
+
+	if at.IsZero() {
+		return o.Store.Activate(key, policyID, user)
+	}
+
+	return o.Store.ScheduleActivation(key, policyID, at, user)
+}
+
+func (o *PolicyManager) GetPending(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policyName string,
+) ([]*policy.Policy, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.GetPending(key)
+}
+
+func (o *PolicyManager) CancelActivation(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policyName string,
 	policyID uuid.UUID,
 ) error {
-	key, err := o.resolvePolicyKey(tenantID, scheme)
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
 	if err != nil {
 		return err
 	}
 
-	return o.Store.Activate(key, policyID)
+	return o.Store.CancelActivation(key, policyID)
+}
+
+func (o *PolicyManager) Rollback(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policyName string,
+	user string,
+) (*policy.Policy, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.Rollback(key, user)
+}
+
+func (o *PolicyManager) DeletePolicy(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policyName string,
+	policyID uuid.UUID,
+) error {
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
+	if err != nil {
+		return err
+	}
+
+	return o.Store.DelPolicy(key, policyID)
+}
+
+func (o *PolicyManager) GetHistory(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policyName string,
+) ([]*policy.Policy, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.History(key)
+}
+
+func (o *PolicyManager) Diff(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policyName string,
+	fromID uuid.UUID,
+	toID uuid.UUID,
+) (string, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
+	if err != nil {
+		return "", err
+	}
+
+	return o.Store.Diff(key, fromID, toID)
 }
 
 func (o *PolicyManager) DeactivateAll(
 	ctx context.Context,
 	tenantID string,
 	scheme string,
+	policyName string,
 ) error {
-	key, err := o.resolvePolicyKey(tenantID, scheme)
+	key, err := o.resolvePolicyKey(tenantID, scheme, policyName)
 	if err != nil {
 		return err
 	}
@@ -187,9 +352,110 @@ func (o *PolicyManager) DeactivateAll(
 	return o.Store.DeactivateAll(key)
 }
 
+func (o *PolicyManager) GetData(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+) (*policy.PolicyData, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.GetData(key.TenantId, key.Scheme)
+}
+
+func (o *PolicyManager) SetData(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	data map[string]any,
+	user string,
+) (*policy.PolicyData, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.SetData(key.TenantId, key.Scheme, data, user)
+}
+
+func (o *PolicyManager) DeleteData(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+) error {
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return err
+	}
+
+	return o.Store.DelData(key.TenantId, key.Scheme)
+}
+
+func (o *PolicyManager) GetChain(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+) (*policy.PolicyChain, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.GetChain(key.TenantId, key.Scheme)
+}
+
+func (o *PolicyManager) SetChain(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+	policies []string,
+	user string,
+) (*policy.PolicyChain, error) {
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return o.Store.SetChain(key.TenantId, key.Scheme, policies, user)
+}
+
+func (o *PolicyManager) DeleteChain(
+	ctx context.Context,
+	tenantID string,
+	scheme string,
+) error {
+	key, err := o.resolvePolicyKey(tenantID, scheme, "")
+	if err != nil {
+		return err
+	}
+
+	return o.Store.DelChain(key.TenantId, key.Scheme)
+}
+
+// GetDecisions returns the decisions recorded by the policy agent while
+// appraising evidence submitted in the specified verification session.
+func (o *PolicyManager) GetDecisions(
+	ctx context.Context,
+	tenantID string,
+	sessionID string,
+) ([]*policy.Decision, error) {
+	decisionLog := o.Agent.GetDecisionLog()
+	if decisionLog == nil {
+		return nil, ErrNoDecisionLog
+	}
+
+	return decisionLog.GetForSession(tenantID, sessionID)
+}
+
+// resolvePolicyKey returns the key of the policy with the specified name for
+// the specified tenant and scheme. If the name is empty, the key of the default
+// policy (named after the agent's backend) is returned.
 func (o *PolicyManager) resolvePolicyKey(
 	tenantID string,
 	scheme string,
+	policyName string,
 ) (policy.PolicyKey, error) {
 	schemeFound := false
 	for _, supportedScheme := range o.SupportedSchemes {
@@ -203,9 +469,45 @@ func (o *PolicyManager) resolvePolicyKey(
 		return policy.PolicyKey{}, fmt.Errorf("Unsupported attestation scheme: %q", scheme)
 	}
 
-	return policy.PolicyKey{
+	if policyName == "" {
+		policyName = o.Agent.GetBackendName()
+	} else if policy.IsReservedName(policyName) || strings.Contains(policyName, ":") {
+		return policy.PolicyKey{}, fmt.Errorf("%w: %q", ErrBadPolicyName, policyName)
+	}
+
+	key := policy.PolicyKey{
 		TenantId: tenantID,
 		Scheme:   scheme,
-		Name:     o.Agent.GetBackendName(),
-	}, nil
+		Name:     policyName,
+	}
+
+	if err := key.Validate(); err != nil {
+		return policy.PolicyKey{}, fmt.Errorf("%w: %v", ErrBadPolicyName, err)
+	}
+
+	return key, nil
+}
+
+// encodeCursor returns the opaque form of the specified store cursor.
+func encodeCursor(storeCursor string) string {
+	if storeCursor == "" {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(storeCursor))
+}
+
+// decodeCursor returns the store cursor from the specified opaque cursor,
+// making sure that it is within the specified prefix.
+func decodeCursor(cursor, prefix string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	storeCursor, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(storeCursor), prefix) {
+		return "", fmt.Errorf("%w: %q", ErrBadCursor, cursor)
+	}
+
+	return string(storeCursor), nil
 }
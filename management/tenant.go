@@ -0,0 +1,205 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+
+package management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/policy"
+	"github.com/veraison/services/provenance"
+	"github.com/veraison/services/tenant"
+)
+
+var ErrNoTenantStore = errors.New("tenant store is not configured")
+
+// IEndorsementPurger is implemented by endorsement stores that support
+// removing all of the CoRIMs stored under a label.
+type IEndorsementPurger interface {
+	DeleteByLabel(label string) error
+}
+
+// TenantManager administers the tenant registry.
+type TenantManager struct {
+	Store    *tenant.Store
+	Policies *policy.Store
+
+	// Endorsements is the store from which a tenant's CoRIMs are purged
+	// when it is deleted. It is nil if the endorsement store is not
+	// configured, or does not support purging.
+	Endorsements IEndorsementPurger
+
+	// Provenance is the store from which the provenance records of a
+	// tenant's endorsements are purged when it is deleted. It is nil if
+	// the provenance store is not configured.
+	Provenance *provenance.Store
+
+	// Schemes are the attestation schemes supported by the deployment;
+	// a tenant's CoRIMs are stored under a label for each of them.
+	Schemes []string
+}
+
+// CreateTenantManagerFromConfig creates a new TenantManager using the tenant
+// store configuration under the "tenant-store" directive of the specified
+// Viper. policies is the store from which a tenant's policies are purged when
+// it is deleted. Likewise, the tenant's CoRIMs and their provenance records
+// are purged from the stores of endorsements for each of the specified
+// schemes; endorsements may be nil. If the endorsement store does not support
+// purging (see IEndorsementPurger), an error is logged, and the CoRIMs of
+// deleted tenants are left in place. If the directive is absent, the returned
+// manager will report ErrNoTenantStore.
+func CreateTenantManagerFromConfig(
+	v *viper.Viper,
+	name string,
+	policies *policy.Store,
+	endorsements *EndorsementManager,
+	schemes []string,
+) (*TenantManager, error) {
+	var purger IEndorsementPurger
+	var provenanceStore *provenance.Store
+
+	if endorsements != nil {
+		if endorsements.Store != nil {
+			var ok bool
+			if purger, ok = endorsements.Store.(IEndorsementPurger); !ok {
+				log.Named(name).Errorf(
+					"the endorsement store does not support purging CoRIMs (%T); "+
+						"the CoRIMs of deleted tenants must be removed using "+
+						"the corim-store tooling", endorsements.Store)
+			}
+		}
+
+		provenanceStore = endorsements.Provenance
+	}
+
+	storeCfg := v.Sub("tenant-store")
+	if storeCfg == nil {
+		return NewTenantManager(nil, policies, purger, provenanceStore, schemes), nil
+	}
+
+	store, err := tenant.NewStore(storeCfg, log.Named(name+"-store"))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTenantManager(store, policies, purger, provenanceStore, schemes), nil
+}
+
+func NewTenantManager(
+	store *tenant.Store,
+	policies *policy.Store,
+	endorsements IEndorsementPurger,
+	provenanceStore *provenance.Store,
+	schemes []string,
+) *TenantManager {
+	return &TenantManager{
+		Store:        store,
+		Policies:     policies,
+		Endorsements: endorsements,
+		Provenance:   provenanceStore,
+		Schemes:      schemes,
+	}
+}
+
+func (o *TenantManager) Create(
+	ctx context.Context,
+	newTenant *tenant.Tenant,
+	user string,
+) (*tenant.Tenant, error) {
+	if o.Store == nil {
+		return nil, ErrNoTenantStore
+	}
+
+	return o.Store.Add(newTenant, user)
+}
+
+func (o *TenantManager) Get(ctx context.Context, id string) (*tenant.Tenant, error) {
+	if o.Store == nil {
+		return nil, ErrNoTenantStore
+	}
+
+	return o.Store.Get(id)
+}
+
+func (o *TenantManager) List(ctx context.Context) ([]*tenant.Tenant, error) {
+	if o.Store == nil {
+		return nil, ErrNoTenantStore
+	}
+
+	return o.Store.List()
+}
+
+func (o *TenantManager) Suspend(ctx context.Context, id, user string) (*tenant.Tenant, error) {
+	if o.Store == nil {
+		return nil, ErrNoTenantStore
+	}
+
+	return o.Store.SetState(id, tenant.StateSuspended, user)
+}
+
+func (o *TenantManager) Resume(ctx context.Context, id, user string) (*tenant.Tenant, error) {
+	if o.Store == nil {
+		return nil, ErrNoTenantStore
+	}
+
+	return o.Store.SetState(id, tenant.StateActive, user)
+}
+
+// Delete purges the policies (along with policy data and chains), the CoRIMs
+// and the provenance records of the tenant with the specified ID, and removes
+// it from the registry. The tenant is suspended first, so that the services
+// stop accepting requests for it while it is being purged. If purging fails,
+// the tenant is left suspended, and deleting it may be retried.
+//
+// If Endorsements is nil, the tenant's CoRIMs are not purged, and must be
+// removed using the corim-store tooling.
+//
+// Verification sessions are held by the verification service, in a store that
+// is not accessible to the management service. Once the tenant has been
+// removed from the registry, the verification service rejects requests for it,
+// and purges its sessions when it first does so (see
+// tenant.Checker.OnUnknownTenant). If a tenant with the same ID is created
+// before then, the sessions are retained until they expire.
+func (o *TenantManager) Delete(ctx context.Context, id, user string) error {
+	if o.Store == nil {
+		return ErrNoTenantStore
+	}
+
+	if _, err := o.Store.SetState(id, tenant.StateSuspended, user); err != nil {
+		return err
+	}
+
+	if err := o.Policies.DelTenant(id); err != nil {
+		return err
+	}
+
+	for _, scheme := range o.Schemes {
+		label := endorsementLabel(id, scheme)
+
+		if o.Endorsements != nil {
+			if err := o.Endorsements.DeleteByLabel(label); err != nil {
+				return fmt.Errorf("purging CoRIMs under %q: %w", label, err)
+			}
+		}
+
+		if o.Provenance != nil {
+			if err := o.Provenance.DelLabel(label); err != nil {
+				return fmt.Errorf("purging provenance records under %q: %w", label, err)
+			}
+		}
+	}
+
+	return o.Store.Del(id)
+}
+
+func (o *TenantManager) Close() error {
+	if o.Store == nil {
+		return nil
+	}
+
+	return o.Store.Close()
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package management
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/policy"
+	"github.com/veraison/services/tenant"
+)
+
+type fakeEndorsementPurger struct {
+	fakeEndorsementStore
+
+	deleted []string
+	err     error
+}
+
+func (o *fakeEndorsementPurger) DeleteByLabel(label string) error {
+	if o.err != nil {
+		return o.err
+	}
+
+	o.deleted = append(o.deleted, label)
+	return nil
+}
+
+func newTestTenantManager(t *testing.T, endorsements *EndorsementManager) *TenantManager {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	policies, err := policy.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	t.Cleanup(func() { policies.Close() })
+
+	cfg := viper.New()
+	cfg.Set("tenant-store", map[string]any{"backend": "memory"})
+
+	manager, err := CreateTenantManagerFromConfig(
+		cfg, "test", policies, endorsements, []string{"PSA_IOT", "ARM_CCA"})
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Close() })
+
+	return manager
+}
+
+func TestTenantManager_Delete(t *testing.T) {
+	purger := &fakeEndorsementPurger{}
+	endorsements := NewEndorsementManager(purger)
+	endorsements.Provenance = newTestProvenanceStore(t)
+	manager := newTestTenantManager(t, endorsements)
+	require.NotNil(t, manager.Endorsements)
+	require.NotNil(t, manager.Provenance)
+
+	ctx := context.Background()
+
+	_, err := manager.Create(ctx, &tenant.Tenant{ID: "acme"}, "admin")
+	require.NoError(t, err)
+
+	key := policy.PolicyKey{TenantId: "acme", Scheme: "PSA_IOT", Name: "opa"}
+	_, err = manager.Policies.Add(key, "default", "opa", "package policy", "admin")
+	require.NoError(t, err)
+
+	kvs := endorsements.Provenance.KVStore
+	require.NoError(t, kvs.Add("acme/PSA_IOT:some-id", `{"tag-id": "corim-1"}`))
+	require.NoError(t, kvs.Add("globex/PSA_IOT:some-id", `{"tag-id": "corim-1"}`))
+
+	require.NoError(t, manager.Delete(ctx, "acme", "admin"))
+
+	assert.Equal(t, []string{"acme/PSA_IOT", "acme/ARM_CCA"}, purger.deleted)
+
+	// only the provenance records of the tenant are purged
+	keys, err := kvs.GetKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"globex/PSA_IOT:some-id"}, keys)
+
+	_, err = manager.Policies.Get(key)
+	assert.ErrorIs(t, err, policy.ErrNoPolicy)
+
+	_, err = manager.Get(ctx, "acme")
+	assert.ErrorIs(t, err, tenant.ErrNoTenant)
+}
+
+func TestTenantManager_Delete_purge_failure(t *testing.T) {
+	purgeErr := errors.New("boom")
+	manager := newTestTenantManager(t,
+		NewEndorsementManager(&fakeEndorsementPurger{err: purgeErr}))
+
+	ctx := context.Background()
+
+	_, err := manager.Create(ctx, &tenant.Tenant{ID: "acme"}, "admin")
+	require.NoError(t, err)
+
+	err = manager.Delete(ctx, "acme", "admin")
+	assert.ErrorIs(t, err, purgeErr)
+
+	// the tenant is left suspended, so that deleting it may be retried
+	ret, err := manager.Get(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.StateSuspended, ret.State)
+}
+
+func TestTenantManager_Delete_no_purger(t *testing.T) {
+	// the store does not support purging
+	manager := newTestTenantManager(t, NewEndorsementManager(&fakeEndorsementStore{}))
+	assert.Nil(t, manager.Endorsements)
+
+	ctx := context.Background()
+
+	_, err := manager.Create(ctx, &tenant.Tenant{ID: "acme"}, "admin")
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Delete(ctx, "acme", "admin"))
+
+	_, err = manager.Get(ctx, "acme")
+	assert.ErrorIs(t, err, tenant.ErrNoTenant)
+}
+
+func TestTenantManager_no_store(t *testing.T) {
+	manager := NewTenantManager(nil, nil, nil, nil, nil)
+
+	_, err := manager.List(context.Background())
+	assert.ErrorIs(t, err, ErrNoTenantStore)
+
+	assert.ErrorIs(t, manager.Delete(context.Background(), "acme", "admin"), ErrNoTenantStore)
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/veraison/corim/comid"
@@ -20,7 +22,8 @@ var ErrNoStatus = "backend returned outcome with no status field: %v"
 var ErrNoTV = "backend returned no trust-vector field, or its not a map[string]interface{}: %v"
 
 type cfg struct {
-	Backend string
+	Backend     string
+	DecisionLog map[string]any `mapstructure:"decision-log" config:"zerodefault"`
 }
 
 func (o cfg) Validate() error {
@@ -33,7 +36,9 @@ func (o cfg) Validate() error {
 
 // CreateAgent creates a new PolicyAgent using the backend specified in the
 // config with "policy.backend" directive. If this directive is absent, the
-// default backend, "opa",  will be used.
+// default backend, "opa",  will be used. If the "policy.decision-log"
+// directive is present, the agent records its decisions to the log it
+// specifies (see NewDecisionLog).
 func CreateAgent(v *viper.Viper, logger *zap.SugaredLogger) (IAgent, error) {
 	cfg := cfg{Backend: DefaultBackend}
 
@@ -42,13 +47,38 @@ func CreateAgent(v *viper.Viper, logger *zap.SugaredLogger) (IAgent, error) {
 		return nil, err
 	}
 
-	return &Agent{Backend: backends[cfg.Backend], logger: logger}, nil
+	agent := &Agent{Backend: backends[cfg.Backend](), logger: logger}
+	if err := agent.Init(v); err != nil {
+		return nil, fmt.Errorf("backend %q: %w", cfg.Backend, err)
+	}
+
+	if cfg.DecisionLog != nil {
+		decisionLog, err := NewDecisionLog(v.Sub("decision-log"), logger)
+		if err != nil {
+			return nil, fmt.Errorf("decision log: %w", err)
+		}
+
+		agent.DecisionLog = decisionLog
+	}
+
+	return agent, nil
 }
 
 type Agent struct {
 	Backend IBackend
 
+	// DecisionLog, if set, is used to record a Decision for every policy
+	// evaluation.
+	DecisionLog IDecisionLog
+
 	logger *zap.SugaredLogger
+
+	// config is used to initialize the backends for policy types other
+	// than that of Backend. These are created on first use, and held in
+	// typeBackends, keyed by policy type.
+	config       *viper.Viper
+	typeBackends map[string]IBackend
+	mu           sync.Mutex
 }
 
 func (o *Agent) Init(v *viper.Viper) error {
@@ -56,6 +86,8 @@ func (o *Agent) Init(v *viper.Viper) error {
 		return err
 	}
 
+	o.config = v
+
 	return nil
 }
 
@@ -65,32 +97,75 @@ func (o *Agent) GetBackendName() string {
 	return o.Backend.GetName()
 }
 
+// GetDecisionLog returns the log used to record the agent's decisions, or nil
+// if they are not being recorded.
+func (o *Agent) GetDecisionLog() IDecisionLog {
+	return o.DecisionLog
+}
+
 // Evaluate the provided policy w.r.t. to the specified evidence and
 // endorsements, and return an updated AttestationResult. The policy may
 // overwrite the result status or any of the values in the result trust vector.
+// data contains the policy data documents available to the policy; it may be
+// nil. If the agent has a DecisionLog, a Decision describing
+// the evaluation is recorded to it.
 func (o *Agent) Evaluate(
 	ctx context.Context,
 	sessionContext map[string]any,
 	appraisalContext *appraisal.Context,
 	policy *Policy,
+	data *PolicyData,
 	submod string,
 	appraisal *ear.Appraisal,
 	endorsements []*comid.ValueTriple,
 ) (*ear.Appraisal, error) {
 
+	backend, err := o.getBackend(policy.Type)
+	if err != nil {
+		return nil, err
+	}
+
 	endorsementMaps, err := endorsementsToMaps(endorsements)
 	if err != nil {
 		return nil, err
 	}
 
+	var dataRevision string
+	if data != nil {
+		dataRevision = data.Revision.String()
+	}
+
 	resultMap := appraisal.AsMap()
 	appraisalUpdated := false
 
-	updatedByPolicy, err := o.Backend.Evaluate(
+	var inputHash string
+	var recorder *ruleRecorder
+
+	if o.DecisionLog != nil {
+		inputHash, err = hashInput(map[string]any{
+			"scheme":       appraisalContext.Scheme,
+			"session":      sessionContext,
+			"result":       resultMap,
+			"evidence":     appraisalContext.Claims,
+			"endorsements": endorsementMaps,
+			"data":         data.GetData(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		recorder = &ruleRecorder{}
+		ctx = withRuleRecorder(ctx, recorder)
+	}
+
+	updatedByPolicy, err := backend.Evaluate(
 		ctx,
 		sessionContext,
 		appraisalContext.Scheme,
+		policy.UUID.String(),
 		policy.Rules,
+		data.GetData(),
+		dataRevision,
 		resultMap,
 		appraisalContext.Claims,
 		endorsementMaps,
@@ -129,27 +204,83 @@ func (o *Agent) Evaluate(
 		resultMap["ear.veraison.policy-claims"] = *updatedAddedClaims
 	}
 
+	// if the policy did not update anything, return the original appraisal
+	evaluatedAppraisal := appraisal
+
 	if appraisalUpdated {
-		evaluatedAppraisal, err := ear.ToAppraisal(resultMap)
+		evaluatedAppraisal, err = ear.ToAppraisal(resultMap)
 		if err != nil {
 			return nil, fmt.Errorf("bad appraisal data from policy: %w", err)
 		}
 		evaluatedAppraisal.AppraisalPolicyID = appraisal.AppraisalPolicyID
+	}
 
-		return evaluatedAppraisal, nil
-	} else {
-		// policy did not update anything, so return the original appraisal
-		return appraisal, nil
+	if o.DecisionLog != nil {
+		o.recordDecision(appraisalContext, policy, submod, inputHash,
+			appraisalChanges(appraisal, evaluatedAppraisal), recorder.rules)
 	}
+
+	return evaluatedAppraisal, nil
 }
 
 // Validate performs basic validation of the provided policy rules, returning
 // an error if it fails. the nature of the validation performed is
 // backend-specific, however it would typically amount to a syntax check.
 // Successful validation does not guarantee that the policy will execute
-// correctly againt actual inputs.
-func (o *Agent) Validate(ctx context.Context, policyRules string) error {
-	return o.Backend.Validate(ctx, policyRules)
+// correctly againt actual inputs. policyType identifies the backend the rules
+// are written for; if empty, the agent's backend is assumed.
+func (o *Agent) Validate(ctx context.Context, policyType string, policyRules string) error {
+	backend, err := o.getBackend(policyType)
+	if err != nil {
+		return err
+	}
+
+	return backend.Validate(ctx, policyRules)
+}
+
+// Invalidate discards any state cached by the backend for the policy with the
+// specified ID. This should be called when a policy stops being active.
+func (o *Agent) Invalidate(policyID string) {
+	o.Backend.Invalidate(policyID)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, backend := range o.typeBackends {
+		backend.Invalidate(policyID)
+	}
+}
+
+// recordDecision records a Decision to the agent's DecisionLog. Failure to do
+// so is logged, but does not fail the evaluation.
+func (o *Agent) recordDecision(
+	appraisalContext *appraisal.Context,
+	policy *Policy,
+	submod string,
+	inputHash string,
+	changes []ClaimChange,
+	rulesFired []string,
+) {
+	decision := &Decision{
+		TenantID:   policy.StoreKey.TenantId,
+		Scheme:     appraisalContext.Scheme,
+		Submod:     submod,
+		PolicyKey:  policy.StoreKey.String(),
+		PolicyID:   policy.UUID.String(),
+		Time:       time.Now(),
+		InputHash:  inputHash,
+		Changes:    changes,
+		RulesFired: rulesFired,
+	}
+
+	if appraisalContext.Evidence != nil {
+		decision.SessionID = appraisalContext.Evidence.SessionID
+	}
+
+	if err := o.DecisionLog.Record(decision); err != nil {
+		o.logger.Errorw("could not record policy decision",
+			"policy-id", policy.StoreKey, "error", err)
+	}
 }
 
 func (o *Agent) GetBackend() IBackend {
@@ -158,6 +289,59 @@ func (o *Agent) GetBackend() IBackend {
 
 func (o *Agent) Close() {
 	o.Backend.Close()
+
+	o.mu.Lock()
+	for _, backend := range o.typeBackends {
+		backend.Close()
+	}
+	o.typeBackends = nil
+	o.mu.Unlock()
+
+	if o.DecisionLog != nil {
+		if err := o.DecisionLog.Close(); err != nil {
+			o.logger.Errorw("could not close decision log", "error", err)
+		}
+	}
+}
+
+// getBackend returns the backend used to evaluate policies of the specified
+// type. The agent's own backend is used for policies with no type. Policies of
+// other types are evaluated by an instance of the corresponding supported
+// backend, which is created and initialized with the agent's config the first
+// time it is needed.
+func (o *Agent) getBackend(policyType string) (IBackend, error) {
+	if policyType == "" || policyType == o.Backend.GetName() {
+		return o.Backend, nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if backend, ok := o.typeBackends[policyType]; ok {
+		return backend, nil
+	}
+
+	newBackend, ok := policyTypes[policyType]
+	if !ok {
+		return nil, fmt.Errorf("policy type %q is not supported", policyType)
+	}
+
+	v := o.config
+	if v == nil {
+		v = viper.New()
+	}
+
+	backend := newBackend()
+	if err := backend.Init(v); err != nil {
+		return nil, fmt.Errorf("backend for policy type %q: %w", policyType, err)
+	}
+
+	if o.typeBackends == nil {
+		o.typeBackends = make(map[string]IBackend)
+	}
+	o.typeBackends[policyType] = backend
+
+	return backend, nil
 }
 
 func endorsementsToMaps(endorsemetTriples []*comid.ValueTriple) ([]map[string]any, error) {
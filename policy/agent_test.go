@@ -32,6 +32,15 @@ func Test_CreateAgent(t *testing.T) {
 	agent, err = CreateAgent(v, log.Named("test"))
 	assert.Nil(t, agent)
 	assert.EqualError(t, err, `backend "nope" is not supported`)
+
+	// policy types that are not agent backends
+	v.Set("backend", OPABundleType)
+
+	agent, err = CreateAgent(v, log.Named("test"))
+	assert.Nil(t, agent)
+	assert.EqualError(t, err, `backend "opa-bundle" is not supported`)
+
+	assert.ElementsMatch(t, []string{"opa", "cel"}, GetSupportedAgentBackends())
 }
 
 type AgentEvaluateTestVector struct {
@@ -165,9 +174,12 @@ func Test_Agent_Evaluate(t *testing.T) {
 		backend := mock_deps.NewMockIBackend(ctrl)
 		backend.EXPECT().
 			Evaluate(gomock.Eq(ctx),
+				gomock.Any(),
 				gomock.Any(),
 				gomock.Any(),
 				gomock.Eq(policy.Rules),
+				gomock.Nil(),
+				gomock.Eq(""),
 				gomock.Any(),
 				gomock.Any(),
 				gomock.Eq([]map[string]any{})).
@@ -180,6 +192,7 @@ func Test_Agent_Evaluate(t *testing.T) {
 			map[string]any{},
 			appraisalContext,
 			policy,
+			nil,
 			"test",
 			appraisal,
 			endorsements,
@@ -215,3 +228,65 @@ func Test_Agent_Evaluate(t *testing.T) {
 		}
 	}
 }
+
+func Test_Agent_getBackend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	backend := mock_deps.NewMockIBackend(ctrl)
+	backend.EXPECT().GetName().AnyTimes().Return("opa")
+
+	agent := &Agent{Backend: backend, logger: log.Named("test")}
+
+	ret, err := agent.getBackend("")
+	require.NoError(t, err)
+	assert.Equal(t, backend, ret)
+
+	ret, err = agent.getBackend("opa")
+	require.NoError(t, err)
+	assert.Equal(t, backend, ret)
+
+	ret, err = agent.getBackend("cel")
+	require.NoError(t, err)
+	assert.Equal(t, "cel", ret.GetName())
+
+	bundleRet, err := agent.getBackend(OPABundleType)
+	require.NoError(t, err)
+	assert.Equal(t, OPABundleType, bundleRet.GetName())
+
+	again, err := agent.getBackend("cel")
+	require.NoError(t, err)
+	assert.Same(t, ret, again)
+
+	other := &Agent{Backend: backend, logger: log.Named("test")}
+	otherRet, err := other.getBackend("cel")
+	require.NoError(t, err)
+	assert.NotSame(t, ret, otherRet)
+
+	_, err = agent.getBackend("nope")
+	assert.EqualError(t, err, `policy type "nope" is not supported`)
+
+	backend.EXPECT().Invalidate("policy-id")
+	agent.Invalidate("policy-id")
+
+	backend.EXPECT().Close()
+	agent.Close()
+	assert.Nil(t, agent.typeBackends)
+}
+
+func Test_CreateAgent_decision_log(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "opa")
+	v.Set("decision-log.sink", "kvstore")
+	v.Set("decision-log.kvstore.backend", "memory")
+
+	agent, err := CreateAgent(v, log.Named("test"))
+	require.NoError(t, err)
+	assert.IsType(t, &KVStoreDecisionLog{}, agent.GetDecisionLog())
+	agent.Close()
+
+	v.Set("decision-log.sink", "file")
+
+	_, err = CreateAgent(v, log.Named("test"))
+	assert.ErrorContains(t, err, "decision log")
+}
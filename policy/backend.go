@@ -1,12 +1,38 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package policy
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/veraison/ear"
+)
+
+// ErrBadResultUpdate is returned when a policy produces an outcome that cannot
+// be applied to the attestation result.
+var ErrBadResultUpdate = errors.New("bad result update from policy")
+
 // DefaultBackend will be used if backend is not explicitly specfied
 var DefaultBackend = "opa"
 
-var backends = map[string]IBackend{
-	"opa": &OPA{},
+// backends maps the names of the backends an agent may be configured with
+// onto functions returning new instances of them. Each agent creates its own
+// instances, so that cached state is not shared between agents.
+var backends = map[string]func() IBackend{
+	OPAType: func() IBackend { return &OPA{} },
+	CELType: func() IBackend { return &CEL{} },
+}
+
+// policyTypes maps the types of the policies agents are able to evaluate onto
+// functions returning new instances of the backends that evaluate them. In
+// addition to the policies written for an agent backend, this includes other
+// formats supported by those backends (e.g. OPA bundles).
+var policyTypes = map[string]func() IBackend{
+	OPAType:       func() IBackend { return &OPA{} },
+	OPAV1Type:     func() IBackend { return &OPA{Type: OPAV1Type} },
+	OPABundleType: func() IBackend { return &OPA{Type: OPABundleType} },
+	CELType:       func() IBackend { return &CEL{} },
 }
 
 // IsValidAgentBackend returns True iff the specified string names a valid backend.
@@ -25,3 +51,69 @@ func GetSupportedAgentBackends() []string {
 
 	return names
 }
+
+func processUpdateValue(value interface{}) (map[string]interface{}, error) {
+	rawUpdate, ok := value.(map[string]interface{})
+	if !ok {
+		err := fmt.Errorf(
+			"%w: expected map[string]interface{}, but got %T",
+			ErrBadResultUpdate, value)
+		return nil, err
+	}
+
+	updateTv := map[string]interface{}{
+		"instance-identity": 0,
+		"configuration":     0,
+		"executables":       0,
+		"file-system":       0,
+		"hardware":          0,
+		"runtime-opaque":    0,
+		"storage-opaque":    0,
+		"sourced-data":      0,
+	}
+
+	updatedStatus, err := ear.ToTrustTier(rawUpdate["status"])
+	if err != nil {
+		return nil, err
+	}
+
+	rawTv, ok := rawUpdate["trust-vector"].(map[string]interface{})
+	if !ok {
+		err := fmt.Errorf(
+			"%w: \"trust-vector\" value should be map[string]interface{}, but got %T",
+			ErrBadResultUpdate, value)
+		return nil, err
+	}
+
+	for claim, rawValue := range rawTv {
+		if _, ok := updateTv[claim]; !ok {
+			err := fmt.Errorf("%w: unexpected claim %q ", ErrBadResultUpdate, claim)
+			return nil, err
+		}
+
+		value, err := ear.ToTrustClaim(rawValue)
+		if err != nil {
+			err := fmt.Errorf("%w: bad value %q for %q: %v",
+				ErrBadResultUpdate, rawValue, claim, err)
+			return nil, err
+		}
+
+		updateTv[claim] = *value
+	}
+
+	addedClaims, ok := rawUpdate["added-claims"].(map[string]interface{})
+	if !ok {
+		err := fmt.Errorf(
+			`%w: "added-claims" value should be map[string]interface{}, but got %T`,
+			ErrBadResultUpdate, value)
+		return nil, err
+	}
+
+	update := map[string]interface{}{
+		"ear.status":                 updatedStatus,
+		"ear.trustworthiness-vector": updateTv,
+		"ear.veraison.policy-claims": &addedClaims,
+	}
+
+	return update, nil
+}
@@ -0,0 +1,349 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/spf13/viper"
+)
+
+// CELType identifies policies consisting of a single CEL expression.
+const CELType = "cel"
+
+// celConstants mirror the trust claim and tier values defined by the OPA
+// preamble (see opa.rego), so that policies for either engine can refer to
+// them by the same names.
+var celConstants = map[string]int64{
+	"NO_CLAIM":           0,
+	"UNEXECTED_EVIDENCE": 1,
+
+	"AFFIRMING":       2,
+	"WARNING":         32,
+	"CONTRAINDICATED": 96,
+
+	"RECOGNIZED_INSTANCE":    2,
+	"UNTRUSTWORTHY_INSTANCE": 96,
+	"UNRECOGNIZED_INSTANCE":  97,
+
+	"APPROVED_CONFIG":         2,
+	"SAFE_CONFIG":             3,
+	"UNSAFE_CONFIG":           32,
+	"UNSUPPORTABLE_CONFIG":    96,
+	"APPROVED_RT":             2,
+	"APPROVED_BOOT":           3,
+	"UNSAFE_RT":               32,
+	"UNRECOGNIZED_RT":         33,
+	"CONTRAINDICATED_RT":      96,
+	"APPROVED_FS":             2,
+	"UNRECOGNIZED_FS":         32,
+	"CONTRAINDICATED_FS":      96,
+	"GENUINE_HW":              2,
+	"UNSAFE_HW":               32,
+	"CONTRAINDICATED_HW":      96,
+	"UNRECOGNIZED_HW":         97,
+	"ENCRYPTED_RT":            2,
+	"ISOLATED_RT":             32,
+	"VISIBLE_RT":              96,
+	"HW_ENCRYPTED_SECRETS":    2,
+	"SW_ENCRYPTED_SECRETS":    32,
+	"UNENCRYPTED_SECRETES":    96,
+	"TRUSTED_SOURCES":         2,
+	"UNTRUSTED_SOURCES":       32,
+	"CONTRAINDICATED_SOURCES": 96,
+}
+
+// CEL is a policy backend that evaluates policies written in the Common
+// Expression Language (https://cel.dev). A CEL policy is a single expression
+// that evaluates to a map with (a subset of) the same fields as the outcome
+// of an OPA policy: "status", "trust-vector" and "added-claims".
+type CEL struct {
+	env *cel.Env
+
+	// cache holds compiled programs, keyed by the UUID of the policy they
+	// were compiled from.
+	cache map[string]cel.Program
+	mu    sync.RWMutex
+}
+
+func NewCEL(v *viper.Viper) (*CEL, error) {
+	var o CEL
+	if err := o.Init(v); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (o *CEL) Init(v *viper.Viper) error {
+	_, err := o.getEnv()
+	return err
+}
+
+func (o *CEL) GetName() string {
+	return CELType
+}
+
+func (o *CEL) Evaluate(
+	ctx context.Context,
+	sessionContext map[string]any,
+	scheme string,
+	policyID string,
+	policy string,
+	data map[string]any,
+	dataRevision string,
+	result map[string]any,
+	evidence map[string]any,
+	endorsements []map[string]any,
+) (map[string]any, error) {
+	input, err := celInput(map[string]any{
+		"scheme":       scheme,
+		"session":      sessionContext,
+		"data":         data,
+		"result":       result,
+		"evidence":     evidence,
+		"endorsements": endorsements,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not convert policy input: %w", err)
+	}
+
+	prg, err := o.getProgram(policyID, policy)
+	if err != nil {
+		return nil, fmt.Errorf("could not Eval policy: %w", err)
+	}
+
+	val, _, err := prg.ContextEval(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("could not Eval policy: %w", err)
+	}
+
+	rawOutcome, err := celValueToNative(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadResultUpdate, err)
+	}
+
+	outcomeMap, ok := rawOutcome.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected map, but got %T", ErrBadResultUpdate, rawOutcome)
+	}
+
+	outcome := map[string]any{
+		"status":       int64(0),
+		"trust-vector": map[string]any{},
+		"added-claims": map[string]any{},
+	}
+
+	for k, v := range outcomeMap {
+		if _, ok := outcome[k]; !ok {
+			return nil, fmt.Errorf("%w: unexpected field %q", ErrBadResultUpdate, k)
+		}
+		outcome[k] = v
+	}
+
+	resultUpdate, err := processUpdateValue(outcome)
+	if err != nil {
+		return nil, fmt.Errorf("policy returned bad update: %w", err)
+	}
+
+	return resultUpdate, nil
+}
+
+func (o *CEL) Validate(ctx context.Context, policy string) error {
+	_, err := o.compile(policy)
+	return err
+}
+
+// Invalidate discards the cached compiled program for the policy with the
+// specified ID, if there is one.
+func (o *CEL) Invalidate(policyID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.cache, policyID)
+}
+
+func (o *CEL) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.cache = nil
+}
+
+func (o *CEL) getProgram(policyID, policy string) (cel.Program, error) {
+	if policyID == "" {
+		return o.compile(policy)
+	}
+
+	o.mu.RLock()
+	prg, ok := o.cache[policyID]
+	o.mu.RUnlock()
+
+	if ok {
+		return prg, nil
+	}
+
+	prg, err := o.compile(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cache == nil {
+		o.cache = make(map[string]cel.Program)
+	}
+	o.cache[policyID] = prg
+
+	return prg, nil
+}
+
+func (o *CEL) compile(policy string) (cel.Program, error) {
+	env, err := o.getEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(policy)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	outType := ast.OutputType()
+	if !outType.IsExactType(cel.DynType) && outType.Kind() != types.MapKind {
+		return nil, fmt.Errorf("policy must evaluate to a map, but evaluates to %s",
+			outType.String())
+	}
+
+	return env.Program(ast)
+}
+
+func (o *CEL) getEnv() (*cel.Env, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.env != nil {
+		return o.env, nil
+	}
+
+	opts := []cel.EnvOption{
+		cel.Variable("scheme", cel.StringType),
+		cel.Variable("session", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("data", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("result", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("evidence", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("endorsements", cel.ListType(cel.DynType)),
+	}
+
+	for name, val := range celConstants {
+		opts = append(opts, cel.Constant(name, cel.IntType, types.Int(val)))
+	}
+
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	o.env = env
+
+	return env, nil
+}
+
+// celInput converts the policy input into plain JSON types (maps, slices,
+// strings, float64s, bools and nils), which is what OPA sees as its input.
+func celInput(input map[string]any) (map[string]any, error) {
+	inputBytes, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret map[string]any
+	if err := json.Unmarshal(inputBytes, &ret); err != nil {
+		return nil, err
+	}
+
+	if ret["session"] == nil {
+		ret["session"] = map[string]any{}
+	}
+
+	if ret["data"] == nil {
+		ret["data"] = map[string]any{}
+	}
+
+	if ret["endorsements"] == nil {
+		ret["endorsements"] = []any{}
+	}
+
+	return ret, nil
+}
+
+// celValueToNative converts a value returned by a CEL program into native Go
+// types. Doubles with no fractional part are converted to int64, so that
+// values copied from the (JSON) input may be used as trust claims.
+func celValueToNative(val ref.Val) (any, error) {
+	switch t := val.(type) {
+	case traits.Mapper:
+		ret := make(map[string]any)
+
+		it := t.Iterator()
+		for it.HasNext() == types.True {
+			key := it.Next()
+
+			keyString, ok := key.(types.String)
+			if !ok {
+				return nil, fmt.Errorf("map key %v is not a string", key.Value())
+			}
+
+			elt, err := celValueToNative(t.Get(key))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", keyString, err)
+			}
+
+			ret[string(keyString)] = elt
+		}
+
+		return ret, nil
+	case traits.Lister:
+		ret := make([]any, 0)
+
+		it := t.Iterator()
+		for it.HasNext() == types.True {
+			elt, err := celValueToNative(it.Next())
+			if err != nil {
+				return nil, err
+			}
+
+			ret = append(ret, elt)
+		}
+
+		return ret, nil
+	case types.Double:
+		if float64(t) == math.Trunc(float64(t)) {
+			return int64(t), nil
+		}
+		return float64(t), nil
+	case types.Int:
+		return int64(t), nil
+	case types.Uint:
+		return uint64(t), nil
+	case types.String:
+		return string(t), nil
+	case types.Bool:
+		return bool(t), nil
+	case types.Null:
+		return nil, nil
+	case *types.Err:
+		return nil, t
+	default:
+		return nil, errors.New("unsupported value type " + val.Type().TypeName())
+	}
+}
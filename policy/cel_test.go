@@ -0,0 +1,144 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/ear"
+)
+
+func Test_CEL_GetName(t *testing.T) {
+	pc, err := NewCEL(nil)
+	require.NoError(t, err)
+	defer pc.Close()
+
+	assert.Equal(t, "cel", pc.GetName())
+}
+
+func Test_CEL_Evaluate(t *testing.T) {
+	ctx := context.Background()
+
+	pc, err := NewCEL(nil)
+	require.NoError(t, err)
+	defer pc.Close()
+
+	resultMap, err := jsonFileToResultMap("test/inputs/psa-result.json")
+	require.NoError(t, err)
+
+	evidenceMap, err := jsonFileToMap("test/inputs/psa-evidence.json")
+	require.NoError(t, err)
+	evidence := evidenceMap["evidence"].(map[string]any)
+	data := map[string]any{"allowlist": []any{"3.4.1", "3.4.2"}}
+
+	vectors := []struct {
+		Title    string
+		Policy   string
+		Expected map[string]any
+		Error    string
+	}{
+		{
+			Title:  "empty outcome",
+			Policy: "{}",
+			Expected: map[string]any{
+				"instance-identity": 0,
+				"executables":       0,
+			},
+		},
+		{
+			Title: "conditional update",
+			Policy: `{
+				"trust-vector": {
+					"executables": evidence["psa-software-components"].exists(c,
+						c["measurement-type"] == "BL" && c["version"] == "3.4.2")
+						? APPROVED_RT : CONTRAINDICATED_RT,
+				},
+			}`,
+			Expected: map[string]any{
+				"instance-identity": 0,
+				"executables":       ear.ApprovedRuntimeClaim,
+			},
+		},
+		{
+			Title: "claim copied from input",
+			Policy: `{
+				"trust-vector": {
+					"hardware": result["submods"]["test"]["ear.trustworthiness-vector"]["hardware"],
+				},
+			}`,
+			Expected: map[string]any{
+				"hardware":    ear.GenuineHardwareClaim,
+				"executables": 0,
+			},
+		},
+		{
+			Title: "policy data",
+			Policy: `{
+				"trust-vector": {
+					"executables": evidence["psa-software-components"].exists(c,
+						c["measurement-type"] == "BL" &&
+						c["version"] in data["allowlist"])
+						? APPROVED_RT : CONTRAINDICATED_RT,
+				},
+			}`,
+			Expected: map[string]any{
+				"executables": ear.ApprovedRuntimeClaim,
+			},
+		},
+		{
+			Title:  "unexpected field",
+			Policy: `{"verdict": AFFIRMING}`,
+			Error:  `unexpected field "verdict"`,
+		},
+		{
+			Title:  "unexpected claim",
+			Policy: `{"trust-vector": {"vibes": AFFIRMING}}`,
+			Error:  `unexpected claim "vibes"`,
+		},
+		{
+			Title:  "bad claim value",
+			Policy: `{"trust-vector": {"executables": "SURE"}}`,
+			Error:  `bad value "SURE" for "executables"`,
+		},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Title, func(t *testing.T) {
+			res, err := pc.Evaluate(ctx, nil, "PSA_IOT", "", v.Policy, data, "",
+				resultMap, evidence, nil)
+			if v.Error != "" {
+				assert.ErrorContains(t, err, v.Error)
+				return
+			}
+			require.NoError(t, err)
+
+			tv := res["ear.trustworthiness-vector"].(map[string]any)
+			for claim, expected := range v.Expected {
+				assert.Equal(t, expected, tv[claim], claim)
+			}
+		})
+	}
+}
+
+func Test_CEL_Validate(t *testing.T) {
+	ctx := context.Background()
+
+	pc, err := NewCEL(nil)
+	require.NoError(t, err)
+	defer pc.Close()
+
+	assert.NoError(t, pc.Validate(ctx, `{"status": AFFIRMING}`))
+	assert.NoError(t, pc.Validate(ctx, `scheme == "PSA_IOT" ? {"status": AFFIRMING} : {}`))
+
+	err = pc.Validate(ctx, `{"status": `)
+	assert.ErrorContains(t, err, "Syntax error")
+
+	err = pc.Validate(ctx, `AFFIRMING`)
+	assert.EqualError(t, err, "policy must evaluate to a map, but evaluates to int")
+
+	err = pc.Validate(ctx, `{"status": ok}`)
+	assert.ErrorContains(t, err, "undeclared reference to 'ok'")
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/veraison/services/kvstore"
+)
+
+var ErrNoPolicyChain = errors.New("no policy chain found")
+var ErrBadPolicyChain = errors.New("bad policy chain")
+
+// ReservedNamePrefix prefixes the names under which the policy store keeps
+// entries other than policies. Policy names may not start with it.
+const ReservedNamePrefix = "@"
+
+// chainName is the reserved name used in the store key of a PolicyChain.
+const chainName = ReservedNamePrefix + "chain"
+
+// PolicyChain is the ordered list of names of the policies that are evaluated,
+// in turn, for a tenant and scheme. This allows, e.g., a vendor baseline
+// policy to be followed by an organization overlay, followed by an
+// application-specific one, each owned by a different party.
+type PolicyChain struct {
+	// TenantId is the ID of the tenant that owns this chain.
+	TenantId string `json:"tenant_id"`
+
+	// Scheme is the name of the scheme to which the chain applies.
+	Scheme string `json:"scheme"`
+
+	// Policies are the names of the policies in the chain (i.e. the Name
+	// parts of their PolicyKeys), in the order they are evaluated.
+	Policies []string `json:"policies"`
+
+	// MTime is the time the chain was last updated.
+	MTime time.Time `json:"mtime"`
+
+	// UpdatedBy identifies the principal (as reported by the authorizer)
+	// that last updated the chain.
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+// NewPolicyChain creates a new PolicyChain for the specified tenant and scheme
+// from the specified policy names. user identifies the principal creating the
+// chain; it may be empty if unknown.
+func NewPolicyChain(tenantID, scheme string, policies []string, user string) (*PolicyChain, error) {
+	ret := &PolicyChain{
+		TenantId:  tenantID,
+		Scheme:    scheme,
+		Policies:  policies,
+		MTime:     time.Now(),
+		UpdatedBy: user,
+	}
+
+	return ret, ret.Validate()
+}
+
+// Validate returns an error wrapping ErrBadPolicyChain if the chain is
+// invalid.
+func (o *PolicyChain) Validate() error {
+	if len(o.Policies) == 0 {
+		return fmt.Errorf("%w: must contain at least one policy", ErrBadPolicyChain)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range o.Policies {
+		key := PolicyKey{TenantId: o.TenantId, Scheme: o.Scheme, Name: name}
+		if err := key.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadPolicyChain, err)
+		}
+
+		if name == "" || IsReservedName(name) || strings.Contains(name, ":") {
+			return fmt.Errorf("%w: bad policy name %q", ErrBadPolicyChain, name)
+		}
+
+		if seen[name] {
+			return fmt.Errorf("%w: policy %q appears more than once",
+				ErrBadPolicyChain, name)
+		}
+		seen[name] = true
+	}
+
+	return nil
+}
+
+// Keys returns the PolicyKeys of the policies in the chain, in order.
+func (o *PolicyChain) Keys() []PolicyKey {
+	ret := make([]PolicyKey, len(o.Policies))
+
+	for i, name := range o.Policies {
+		ret[i] = PolicyKey{TenantId: o.TenantId, Scheme: o.Scheme, Name: name}
+	}
+
+	return ret
+}
+
+// IsReservedName returns true if the specified policy name is reserved for
+// store entries other than policies, and so may not be used to name a policy.
+func IsReservedName(name string) bool {
+	return strings.HasPrefix(name, ReservedNamePrefix)
+}
+
+func policyChainKey(tenantID, scheme string) string {
+	return PolicyKey{TenantId: tenantID, Scheme: scheme, Name: chainName}.String()
+}
+
+// SetChain sets the chain of policies evaluated for the specified tenant and
+// scheme. user identifies the principal performing the update.
+func (o *Store) SetChain(tenantID, scheme string, policies []string, user string) (*PolicyChain, error) {
+	chain, err := NewPolicyChain(tenantID, scheme, policies, user)
+	if err != nil {
+		return nil, err
+	}
+
+	chainBytes, err := json.Marshal(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	return chain, o.KVStore.Set(policyChainKey(tenantID, scheme), string(chainBytes))
+}
+
+// GetChain returns the chain of policies evaluated for the specified tenant and
+// scheme, or an error wrapping ErrNoPolicyChain if one has not been set.
+func (o *Store) GetChain(tenantID, scheme string) (*PolicyChain, error) {
+	key := policyChainKey(tenantID, scheme)
+
+	vals, err := o.KVStore.Get(key)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrNoPolicyChain, key)
+		}
+		return nil, err
+	}
+
+	if len(vals) != 1 {
+		return nil, fmt.Errorf("found %d values for policy chain key %q; expected 1",
+			len(vals), key)
+	}
+
+	var chain PolicyChain
+	if err := json.Unmarshal([]byte(vals[0]), &chain); err != nil {
+		return nil, fmt.Errorf("bad policy chain under key %q: %w", key, err)
+	}
+
+	return &chain, nil
+}
+
+// DelChain removes the chain of policies for the specified tenant and scheme.
+func (o *Store) DelChain(tenantID, scheme string) error {
+	key := policyChainKey(tenantID, scheme)
+
+	if err := o.KVStore.Del(key); err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return fmt.Errorf("%w: %q", ErrNoPolicyChain, key)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func Test_Store_Chain(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "baseline"}
+	_, err = store.Add(key, "test", "test", "rules", "")
+	require.NoError(t, err)
+
+	_, err = store.GetChain("1", "scheme")
+	assert.ErrorIs(t, err, ErrNoPolicyChain)
+
+	_, err = store.SetChain("1", "scheme", []string{"baseline", "overlay"}, "alice")
+	require.NoError(t, err)
+
+	chain, err := store.GetChain("1", "scheme")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baseline", "overlay"}, chain.Policies)
+	assert.Equal(t, "alice", chain.UpdatedBy)
+	assert.Equal(t, []PolicyKey{
+		{"1", "scheme", "baseline"},
+		{"1", "scheme", "overlay"},
+	}, chain.Keys())
+
+	// the chain is not reported as a policy key
+	keys, err := store.GetPolicyKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyKey{key}, keys)
+
+	require.NoError(t, store.DelChain("1", "scheme"))
+
+	_, err = store.GetChain("1", "scheme")
+	assert.ErrorIs(t, err, ErrNoPolicyChain)
+
+	err = store.DelChain("1", "scheme")
+	assert.ErrorIs(t, err, ErrNoPolicyChain)
+}
+
+func Test_PolicyChain_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		policies []string
+		err      string
+	}{
+		{nil, "must contain at least one policy"},
+		{[]string{"a", ""}, `bad policy name ""`},
+		{[]string{"@chain"}, `bad policy name "@chain"`},
+		{[]string{"a:b"}, `bad policy name "a:b"`},
+		{[]string{"a", "b", "a"}, `policy "a" appears more than once`},
+	} {
+		_, err := NewPolicyChain("1", "scheme", tc.policies, "")
+		assert.ErrorIs(t, err, ErrBadPolicyChain)
+		assert.ErrorContains(t, err, tc.err)
+	}
+}
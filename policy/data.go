@@ -0,0 +1,167 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/veraison/services/kvstore"
+)
+
+var ErrNoPolicyData = errors.New("no policy data found")
+
+// PolicyData is a JSON document containing data (e.g. allow-lists of firmware
+// versions, or deny-lists of device IDs) that is made available to all
+// policies for a tenant and scheme. Unlike the policies themselves, data is not
+// versioned: updating it takes effect on the next evaluation, without the need
+// to create or activate a new policy version.
+type PolicyData struct {
+	// TenantId is the ID of the tenant that owns this data.
+	TenantId string `json:"tenant_id"`
+
+	// Scheme is the name of the scheme whose policies may access this
+	// data.
+	Scheme string `json:"scheme"`
+
+	// Revision uniquely identifies this instance of the data. It changes
+	// every time the data is updated.
+	Revision uuid.UUID `json:"revision"`
+
+	// MTime is the time the data was last updated.
+	MTime time.Time `json:"mtime"`
+
+	// UpdatedBy identifies the principal (as reported by the authorizer)
+	// that last updated the data.
+	UpdatedBy string `json:"updated_by,omitempty"`
+
+	// Data is the document itself. Its top-level entries are exposed to
+	// the policies (e.g. as data.<entry> in Rego).
+	Data map[string]any `json:"data"`
+}
+
+// NewPolicyData creates new PolicyData for the specified tenant and scheme.
+// user identifies the principal creating the data; it may be empty if unknown.
+func NewPolicyData(tenantID, scheme string, data map[string]any, user string) (*PolicyData, error) {
+	rev, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	ret := &PolicyData{
+		TenantId:  tenantID,
+		Scheme:    scheme,
+		Revision:  rev,
+		MTime:     time.Now(),
+		UpdatedBy: user,
+		Data:      data,
+	}
+
+	return ret, ret.Validate()
+}
+
+// GetData returns the data documents, or nil if o is nil.
+func (o *PolicyData) GetData() map[string]any {
+	if o == nil {
+		return nil
+	}
+
+	return o.Data
+}
+
+// Validate returns an error if the data cannot be stored.
+func (o *PolicyData) Validate() error {
+	if url.PathEscape(o.TenantId) != o.TenantId || strings.Contains(o.TenantId, ":") {
+		return fmt.Errorf("bad TenantId %q: must be a valid URI path segment without ':'",
+			o.TenantId)
+	}
+
+	if url.PathEscape(o.Scheme) != o.Scheme || strings.Contains(o.Scheme, ":") {
+		return fmt.Errorf("bad Scheme %q: must be a valid URI path segment without ':'",
+			o.Scheme)
+	}
+
+	return nil
+}
+
+// Key returns the key under which the data is stored in the policy store.
+func (o *PolicyData) Key() string {
+	return policyDataKey(o.TenantId, o.Scheme)
+}
+
+// policyDataKey returns the policy store key for the data associated with the
+// specified tenant and scheme. Data keys consist of two :-separated parts,
+// which distinguishes them from PolicyKeys (which have three).
+func policyDataKey(tenantID, scheme string) string {
+	return fmt.Sprintf("%s:%s", tenantID, scheme)
+}
+
+func isPolicyDataKey(key string) bool {
+	return len(strings.Split(key, ":")) == 2
+}
+
+// SetData replaces the data associated with the specified tenant and scheme
+// with the provided document. user identifies the principal performing the
+// update.
+func (o *Store) SetData(tenantID, scheme string, data map[string]any, user string) (*PolicyData, error) {
+	pd, err := NewPolicyData(tenantID, scheme, data, user)
+	if err != nil {
+		return nil, err
+	}
+
+	pdBytes, err := json.Marshal(pd)
+	if err != nil {
+		return nil, err
+	}
+
+	return pd, o.KVStore.Set(pd.Key(), string(pdBytes))
+}
+
+// GetData returns the data associated with the specified tenant and scheme,
+// or an error wrapping ErrNoPolicyData if there isn't any.
+func (o *Store) GetData(tenantID, scheme string) (*PolicyData, error) {
+	key := policyDataKey(tenantID, scheme)
+
+	vals, err := o.KVStore.Get(key)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrNoPolicyData, key)
+		}
+		return nil, err
+	}
+
+	if len(vals) != 1 {
+		return nil, fmt.Errorf("found %d values for policy data key %q; expected 1",
+			len(vals), key)
+	}
+
+	var pd PolicyData
+	if err := json.Unmarshal([]byte(vals[0]), &pd); err != nil {
+		return nil, fmt.Errorf("bad policy data under key %q: %w", key, err)
+	}
+
+	return &pd, nil
+}
+
+// DelData removes the data associated with the specified tenant and scheme.
+func (o *Store) DelData(tenantID, scheme string) error {
+	key := policyDataKey(tenantID, scheme)
+
+	if err := o.KVStore.Del(key); err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return fmt.Errorf("%w: %q", ErrNoPolicyData, key)
+		}
+		return err
+	}
+
+	return nil
+}
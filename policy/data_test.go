@@ -0,0 +1,66 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func Test_Store_Data(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "policy"}
+	pol, err := store.Add(key, "test", "test", "rules", "")
+	require.NoError(t, err)
+	require.NoError(t, store.Activate(key, pol.UUID, ""))
+
+	_, err = store.GetData("1", "scheme")
+	assert.ErrorIs(t, err, ErrNoPolicyData)
+
+	first, err := store.SetData("1", "scheme",
+		map[string]any{"allowlist": []any{"3.4.1"}}, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "1:scheme", first.Key())
+
+	second, err := store.SetData("1", "scheme",
+		map[string]any{"allowlist": []any{"3.4.1", "3.4.2"}}, "bob")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Revision, second.Revision)
+
+	pd, err := store.GetData("1", "scheme")
+	require.NoError(t, err)
+	assert.Equal(t, second.Revision, pd.Revision)
+	assert.Equal(t, "bob", pd.UpdatedBy)
+	assert.Equal(t, []any{"3.4.1", "3.4.2"}, pd.Data["allowlist"])
+
+	// updating data does not create new policy versions
+	versions, err := store.Get(key)
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	// data keys are not reported as policy keys
+	keys, err := store.GetPolicyKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyKey{key}, keys)
+
+	require.NoError(t, store.DelData("1", "scheme"))
+
+	_, err = store.GetData("1", "scheme")
+	assert.ErrorIs(t, err, ErrNoPolicyData)
+
+	err = store.DelData("1", "scheme")
+	assert.ErrorIs(t, err, ErrNoPolicyData)
+
+	_, err = store.SetData("1", "bad:scheme", nil, "")
+	assert.ErrorContains(t, err, "bad Scheme")
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/veraison/ear"
+)
+
+var ErrNoDecisions = errors.New("no policy decisions found")
+
+// Decision records how the evaluation of a policy affected an appraisal, so
+// that it is possible to establish, after the fact, why an attestation result
+// contains the claims it does.
+type Decision struct {
+	// SessionID identifies the verification session in which the evidence
+	// was appraised. It may be empty if the evidence was not submitted via
+	// a session.
+	SessionID string `json:"session-id,omitempty"`
+
+	// TenantID is the ID of the tenant that owns the policy.
+	TenantID string `json:"tenant-id"`
+
+	// Scheme is the name of the scheme that created the appraisal.
+	Scheme string `json:"scheme"`
+
+	// Submod is the name of the submod whose appraisal was evaluated.
+	Submod string `json:"submod"`
+
+	// PolicyKey is the policy store key of the evaluated policy.
+	PolicyKey string `json:"policy-key"`
+
+	// PolicyID is the individual policy ID (i.e. the UUID of the specific
+	// version) of the evaluated policy.
+	PolicyID string `json:"policy-id"`
+
+	// Time is the time the policy was evaluated.
+	Time time.Time `json:"time"`
+
+	// InputHash is the SHA-256 hash of the JSON encoding of the inputs
+	// to the policy (the session context, evidence claims, endorsements,
+	// appraisal and policy data), in the form "sha-256:<hex>".
+	InputHash string `json:"input-hash"`
+
+	// Changes lists the appraisal claims updated by the policy.
+	Changes []ClaimChange `json:"changes,omitempty"`
+
+	// RulesFired lists the policy rules that succeeded during the
+	// evaluation, in the order they first did so. Not all backends are able
+	// to report these.
+	RulesFired []string `json:"rules-fired,omitempty"`
+}
+
+// ClaimChange records the update of a single appraisal claim by a policy.
+type ClaimChange struct {
+	// Claim is the name of the claim, e.g. "ear.status",
+	// "ear.trustworthiness-vector.hardware", or
+	// "ear.veraison.policy-claims.<name>".
+	Claim string `json:"claim"`
+
+	// Before is the value of the claim prior to the policy being
+	// evaluated; it is omitted if the claim was not present.
+	Before any `json:"before,omitempty"`
+
+	// After is the value of the claim set by the policy.
+	After any `json:"after"`
+}
+
+// hashInput returns the InputHash for the specified policy input.
+func hashInput(input map[string]any) (string, error) {
+	// encoding/json sorts map keys, so the encoding is stable for the
+	// same input.
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("could not encode policy input: %w", err)
+	}
+
+	digest := sha256.Sum256(encoded)
+
+	return "sha-256:" + hex.EncodeToString(digest[:]), nil
+}
+
+// appraisalChanges returns the claims that differ between the before and
+// after appraisals.
+func appraisalChanges(before, after *ear.Appraisal) []ClaimChange {
+	var ret []ClaimChange
+
+	if !reflect.DeepEqual(before.Status, after.Status) && after.Status != nil {
+		change := ClaimChange{Claim: "ear.status", After: *after.Status}
+		if before.Status != nil {
+			change.Before = *before.Status
+		}
+		ret = append(ret, change)
+	}
+
+	beforeTV := ear.TrustVector{}
+	if before.TrustVector != nil {
+		beforeTV = *before.TrustVector
+	}
+
+	afterTV := ear.TrustVector{}
+	if after.TrustVector != nil {
+		afterTV = *after.TrustVector
+	}
+
+	ret = append(ret, mapChanges(
+		"ear.trustworthiness-vector.",
+		trustVectorToMap(beforeTV),
+		trustVectorToMap(afterTV),
+	)...)
+
+	var beforeClaims, afterClaims map[string]any
+	if before.VeraisonPolicyClaims != nil {
+		beforeClaims = *before.VeraisonPolicyClaims
+	}
+	if after.VeraisonPolicyClaims != nil {
+		afterClaims = *after.VeraisonPolicyClaims
+	}
+
+	return append(ret, mapChanges("ear.veraison.policy-claims.", beforeClaims, afterClaims)...)
+}
+
+func trustVectorToMap(tv ear.TrustVector) map[string]any {
+	ret := make(map[string]any)
+
+	for k, v := range tv.AsMap() {
+		if v != ear.NoClaim {
+			ret[k] = v
+		}
+	}
+
+	return ret
+}
+
+// mapChanges returns the entries of after that differ from those in before.
+// Claim names are formed by adding prefix to the keys. Changes are returned
+// sorted by claim name.
+func mapChanges(prefix string, before, after map[string]any) []ClaimChange {
+	var ret []ClaimChange
+
+	for k, afterVal := range after {
+		beforeVal, ok := before[k]
+		if ok && reflect.DeepEqual(beforeVal, afterVal) {
+			continue
+		}
+
+		ret = append(ret, ClaimChange{Claim: prefix + k, Before: beforeVal, After: afterVal})
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Claim < ret[j].Claim })
+
+	return ret
+}
+
+// ruleRecorder collects the names of the policy rules that fired during an
+// evaluation. Backends that are able to do so add the rules to the recorder
+// found in the evaluation context (see recordRuleFired).
+type ruleRecorder struct {
+	rules []string
+	seen  map[string]bool
+}
+
+func (o *ruleRecorder) add(rule string) {
+	if o.seen == nil {
+		o.seen = make(map[string]bool)
+	}
+
+	if o.seen[rule] {
+		return
+	}
+
+	o.seen[rule] = true
+	o.rules = append(o.rules, rule)
+}
+
+type ruleRecorderKey struct{}
+
+func withRuleRecorder(ctx context.Context, recorder *ruleRecorder) context.Context {
+	return context.WithValue(ctx, ruleRecorderKey{}, recorder)
+}
+
+// getRuleRecorder returns the ruleRecorder associated with the context, or
+// nil, if the fired rules do not need to be recorded.
+func getRuleRecorder(ctx context.Context) *ruleRecorder {
+	recorder, _ := ctx.Value(ruleRecorderKey{}).(*ruleRecorder)
+	return recorder
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/ear"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/vts/appraisal"
+)
+
+func Test_appraisalChanges(t *testing.T) {
+	affirming := ear.TrustTierAffirming
+	contraindicated := ear.TrustTierContraindicated
+
+	before := &ear.Appraisal{
+		Status: &affirming,
+		TrustVector: &ear.TrustVector{
+			Executables: ear.ApprovedRuntimeClaim,
+			Hardware:    ear.GenuineHardwareClaim,
+		},
+		AppraisalExtensions: ear.AppraisalExtensions{
+			VeraisonPolicyClaims: &map[string]any{"kept": "x", "changed": "y"},
+		},
+	}
+
+	after := &ear.Appraisal{
+		Status: &contraindicated,
+		TrustVector: &ear.TrustVector{
+			Executables: ear.ContraindicatedRuntimeClaim,
+			Hardware:    ear.GenuineHardwareClaim,
+		},
+		AppraisalExtensions: ear.AppraisalExtensions{
+			VeraisonPolicyClaims: &map[string]any{"kept": "x", "changed": "z", "new": 1},
+		},
+	}
+
+	assert.Equal(t, []ClaimChange{
+		{Claim: "ear.status", Before: affirming, After: contraindicated},
+		{
+			Claim:  "ear.trustworthiness-vector.executables",
+			Before: ear.ApprovedRuntimeClaim,
+			After:  ear.ContraindicatedRuntimeClaim,
+		},
+		{Claim: "ear.veraison.policy-claims.changed", Before: "y", After: "z"},
+		{Claim: "ear.veraison.policy-claims.new", After: 1},
+	}, appraisalChanges(before, after))
+
+	assert.Nil(t, appraisalChanges(before, before))
+}
+
+func Test_hashInput(t *testing.T) {
+	first, err := hashInput(map[string]any{"a": 1, "b": []any{"x"}})
+	require.NoError(t, err)
+	assert.Regexp(t, "^sha-256:[0-9a-f]{64}$", first)
+
+	second, err := hashInput(map[string]any{"b": []any{"x"}, "a": 1})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	third, err := hashInput(map[string]any{"a": 2, "b": []any{"x"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+}
+
+func Test_Agent_Evaluate_records_decision(t *testing.T) {
+	decisionLog := &FileDecisionLog{Path: filepath.Join(t.TempDir(), "decisions.jsonl")}
+
+	agent := &Agent{
+		Backend:     &OPA{},
+		DecisionLog: decisionLog,
+		logger:      log.Named("test"),
+	}
+	defer agent.Close()
+
+	evidenceMap, err := jsonFileToMap("test/inputs/psa-evidence.json")
+	require.NoError(t, err)
+
+	rules, err := os.ReadFile("test/policies/data-allowlist.rego")
+	require.NoError(t, err)
+
+	pol := &Policy{
+		StoreKey: PolicyKey{"0", "PSA_IOT", "opa"},
+		UUID:     uuid.MustParse("6b1e3c1a-5c5e-4a3f-9d0a-7c2f1e4b8a90"),
+		Rules:    string(rules),
+	}
+
+	appraisalContext := &appraisal.Context{
+		Scheme:   "PSA_IOT",
+		Evidence: &appraisal.Evidence{TenantID: "0", SessionID: "session-1"},
+		Claims:   evidenceMap["evidence"].(map[string]any),
+	}
+
+	affirming := ear.TrustTierAffirming
+	before := &ear.Appraisal{
+		Status: &affirming,
+		TrustVector: &ear.TrustVector{
+			Executables: ear.ApprovedRuntimeClaim,
+		},
+	}
+
+	denied, err := NewPolicyData("0", "PSA_IOT", map[string]any{
+		"allowlist": map[string]any{"bl_versions": []any{"3.5.1"}},
+	}, "")
+	require.NoError(t, err)
+
+	_, err = agent.Evaluate(context.Background(), map[string]any{}, appraisalContext,
+		pol, denied, "PSA_IOT", before, nil)
+	require.NoError(t, err)
+
+	decisions, err := decisionLog.GetForSession("0", "session-1")
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+
+	decision := decisions[0]
+	assert.Equal(t, "session-1", decision.SessionID)
+	assert.Equal(t, "0", decision.TenantID)
+	assert.Equal(t, "PSA_IOT", decision.Scheme)
+	assert.Equal(t, "PSA_IOT", decision.Submod)
+	assert.Equal(t, "0:PSA_IOT:opa", decision.PolicyKey)
+	assert.Equal(t, pol.UUID.String(), decision.PolicyID)
+	assert.Regexp(t, "^sha-256:", decision.InputHash)
+	// the else branch of the rule is the one that fired
+	assert.Equal(t, []string{"executables (policy.rego:7)"}, decision.RulesFired)
+
+	// values are read back from JSON
+	require.Len(t, decision.Changes, 2)
+	assert.Equal(t, "ear.status", decision.Changes[0].Claim)
+	assert.Equal(t, "affirming", decision.Changes[0].Before)
+	assert.Equal(t, "none", decision.Changes[0].After)
+	assert.Equal(t, "ear.trustworthiness-vector.executables", decision.Changes[1].Claim)
+	assert.EqualValues(t, ear.ApprovedRuntimeClaim, decision.Changes[1].Before)
+	assert.EqualValues(t, ear.ContraindicatedRuntimeClaim, decision.Changes[1].After)
+
+	_, err = decisionLog.GetForSession("0", "session-2")
+	assert.ErrorIs(t, err, ErrNoDecisions)
+}
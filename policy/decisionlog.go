@@ -0,0 +1,199 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+	"github.com/veraison/services/kvstore"
+	"go.uber.org/zap"
+)
+
+type decisionLogCfg struct {
+	Sink    string         `mapstructure:"sink" valid:"in(file|kvstore)"`
+	Path    string         `mapstructure:"path" config:"zerodefault"`
+	KVStore map[string]any `mapstructure:"kvstore" config:"zerodefault"`
+}
+
+func (o decisionLogCfg) Validate() error {
+	switch o.Sink {
+	case "file":
+		if o.Path == "" {
+			return errors.New(`"path" must be specified for the "file" sink`)
+		}
+	case "kvstore":
+		if o.KVStore == nil {
+			return errors.New(`"kvstore" must be specified for the "kvstore" sink`)
+		}
+	}
+
+	return nil
+}
+
+// NewDecisionLog returns a new decision log, writing to the sink specified by
+// the "sink" directive of the config: either "file" (in which case "path"
+// specifies the file Decisions are appended to), or "kvstore" (in which case
+// "kvstore" contains the config for kvstore.New()).
+func NewDecisionLog(v *viper.Viper, logger *zap.SugaredLogger) (IDecisionLog, error) {
+	var cfg decisionLogCfg
+
+	loader := config.NewLoader(&cfg)
+	if err := loader.LoadFromViper(v); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Sink {
+	case "file":
+		return &FileDecisionLog{Path: cfg.Path}, nil
+	case "kvstore":
+		kvStore, err := kvstore.New(v.Sub("kvstore"), logger)
+		if err != nil {
+			return nil, err
+		}
+
+		return &KVStoreDecisionLog{KVStore: kvStore}, nil
+	default:
+		return nil, fmt.Errorf("decision log sink %q is not supported", cfg.Sink)
+	}
+}
+
+// FileDecisionLog appends Decisions to a file, one JSON object per line.
+type FileDecisionLog struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (o *FileDecisionLog) Record(decision *Decision) error {
+	line, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// The file is re-opened for every decision, so that it may be rotated
+	// externally.
+	f, err := os.OpenFile(o.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// GetForSession scans the whole file for the decisions recorded for the
+// session, so the time it takes grows with the size of the file. The file sink
+// is therefore only suitable if the file is rotated regularly, or the
+// decisions are rarely queried; otherwise, the kvstore sink should be used.
+func (o *FileDecisionLog) GetForSession(tenantID, sessionID string) ([]*Decision, error) {
+	f, err := os.Open(o.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: session %q", ErrNoDecisions, sessionID)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var ret []*Decision
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		var decision Decision
+		if err := json.Unmarshal(scanner.Bytes(), &decision); err != nil {
+			return nil, fmt.Errorf("bad decision at %s:%d: %w", o.Path, lineNo, err)
+		}
+
+		if decision.TenantID == tenantID && decision.SessionID == sessionID {
+			ret = append(ret, &decision)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("%w: session %q", ErrNoDecisions, sessionID)
+	}
+
+	return ret, nil
+}
+
+func (o *FileDecisionLog) Close() error {
+	return nil
+}
+
+// KVStoreDecisionLog stores Decisions in a kvstore, under keys consisting of
+// the tenant ID and the session ID delimited by a colon. Decisions without a
+// session ID cannot be retrieved, and so are not stored.
+type KVStoreDecisionLog struct {
+	KVStore kvstore.IKVStore
+}
+
+// Setup the underlying kvstore. This is a one-time setup that only needs to be
+// performed once for a deployment.
+func (o *KVStoreDecisionLog) Setup() error {
+	return o.KVStore.Setup()
+}
+
+func (o *KVStoreDecisionLog) Record(decision *Decision) error {
+	if decision.SessionID == "" {
+		return nil
+	}
+
+	val, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+
+	return o.KVStore.Add(decisionKey(decision.TenantID, decision.SessionID), string(val))
+}
+
+func (o *KVStoreDecisionLog) GetForSession(tenantID, sessionID string) ([]*Decision, error) {
+	key := decisionKey(tenantID, sessionID)
+
+	vals, err := o.KVStore.Get(key)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: session %q", ErrNoDecisions, sessionID)
+		}
+		return nil, err
+	}
+
+	ret := make([]*Decision, len(vals))
+	for i, val := range vals {
+		var decision Decision
+		if err := json.Unmarshal([]byte(val), &decision); err != nil {
+			return nil, fmt.Errorf("bad decision under key %q: %w", key, err)
+		}
+
+		ret[i] = &decision
+	}
+
+	return ret, nil
+}
+
+func (o *KVStoreDecisionLog) Close() error {
+	return o.KVStore.Close()
+}
+
+func decisionKey(tenantID, sessionID string) string {
+	return fmt.Sprintf("%s:%s", tenantID, sessionID)
+}
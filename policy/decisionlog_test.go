@@ -0,0 +1,81 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func Test_NewDecisionLog(t *testing.T) {
+	v := viper.New()
+	v.Set("sink", "file")
+	v.Set("path", filepath.Join(t.TempDir(), "decisions.jsonl"))
+
+	decisionLog, err := NewDecisionLog(v, log.Named("test"))
+	require.NoError(t, err)
+	assert.IsType(t, &FileDecisionLog{}, decisionLog)
+
+	v = viper.New()
+	v.Set("sink", "kvstore")
+	v.Set("kvstore.backend", "memory")
+
+	decisionLog, err = NewDecisionLog(v, log.Named("test"))
+	require.NoError(t, err)
+	assert.IsType(t, &KVStoreDecisionLog{}, decisionLog)
+	require.NoError(t, decisionLog.Close())
+
+	v = viper.New()
+	v.Set("sink", "file")
+
+	_, err = NewDecisionLog(v, log.Named("test"))
+	assert.ErrorContains(t, err, `"path" must be specified`)
+
+	v = viper.New()
+	v.Set("sink", "syslog")
+
+	_, err = NewDecisionLog(v, log.Named("test"))
+	assert.ErrorContains(t, err, "syslog")
+}
+
+func Test_KVStoreDecisionLog(t *testing.T) {
+	v := viper.New()
+	v.Set("sink", "kvstore")
+	v.Set("kvstore.backend", "memory")
+
+	decisionLog, err := NewDecisionLog(v, log.Named("test"))
+	require.NoError(t, err)
+	defer decisionLog.Close()
+
+	_, err = decisionLog.GetForSession("0", "session-1")
+	assert.ErrorIs(t, err, ErrNoDecisions)
+
+	require.NoError(t, decisionLog.Record(&Decision{
+		TenantID: "0", SessionID: "session-1", PolicyKey: "0:PSA_IOT:baseline",
+	}))
+	require.NoError(t, decisionLog.Record(&Decision{
+		TenantID: "0", SessionID: "session-1", PolicyKey: "0:PSA_IOT:overlay",
+	}))
+	require.NoError(t, decisionLog.Record(&Decision{
+		TenantID: "1", SessionID: "session-1", PolicyKey: "1:PSA_IOT:opa",
+	}))
+
+	decisions, err := decisionLog.GetForSession("0", "session-1")
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, "0:PSA_IOT:baseline", decisions[0].PolicyKey)
+	assert.Equal(t, "0:PSA_IOT:overlay", decisions[1].PolicyKey)
+
+	// decisions without a session are not stored
+	require.NoError(t, decisionLog.Record(&Decision{
+		TenantID: "0", PolicyKey: "0:PSA_IOT:baseline",
+	}))
+
+	_, err = decisionLog.GetForSession("0", "")
+	assert.ErrorIs(t, err, ErrNoDecisions)
+}
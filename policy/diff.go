@@ -0,0 +1,29 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DiffPolicies returns a unified diff between the rules of the two specified
+// policies. The diff headers identify each policy by its UUID. An empty string
+// is returned if the rules are identical.
+func DiffPolicies(from, to *Policy) (string, error) {
+	if from == nil || to == nil {
+		return "", errors.New("nil policy")
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Rules),
+		B:        difflib.SplitLines(to.Rules),
+		FromFile: from.UUID.String(),
+		FromDate: from.CTime.UTC().Format(time.RFC3339),
+		ToFile:   to.UUID.String(),
+		ToDate:   to.CTime.UTC().Format(time.RFC3339),
+		Context:  3,
+	})
+}
@@ -14,14 +14,17 @@ import (
 type IAgent interface {
 	Init(v *viper.Viper) error
 	GetBackendName() string
+	GetDecisionLog() IDecisionLog
 	Evaluate(ctx context.Context,
 		sessionContext map[string]any,
 		appraisalContext *appraisal.Context,
 		policy *Policy,
+		data *PolicyData,
 		submod string,
 		appraisal *ear.Appraisal,
 		endorsements []*comid.ValueTriple,
 	) (*ear.Appraisal, error)
-	Validate(ctx context.Context, policyRules string) error
+	Validate(ctx context.Context, policyType string, policyRules string) error
+	Invalidate(policyID string)
 	Close()
 }
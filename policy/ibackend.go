@@ -11,15 +11,27 @@ import (
 type IBackend interface {
 	Init(v *viper.Viper) error
 	GetName() string
+	// Evaluate the policy against the provided inputs. data contains
+	// additional documents (see PolicyData) exposed to the policy; it may
+	// be nil. dataRevision identifies the revision of the data. Backends
+	// that cache state derived from the data must key it on the revision
+	// as well as the policy ID, as the data may change while the policy
+	// remains active.
 	Evaluate(
 		ctx context.Context,
 		sessionContext map[string]any,
 		scheme string,
+		policyID string,
 		policy string,
+		data map[string]any,
+		dataRevision string,
 		result map[string]any,
 		evidence map[string]any,
 		endorsements []map[string]any,
 	) (map[string]any, error)
 	Validate(ctx context.Context, policy string) error
+	// Invalidate discards any state (e.g. compiled policy) the backend has
+	// cached for the policy with the specified ID.
+	Invalidate(policyID string)
 	Close()
 }
@@ -0,0 +1,15 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+// IDecisionLog is the interface to a sink for the Decisions made by the policy
+// agent.
+type IDecisionLog interface {
+	// Record adds the specified decision to the log.
+	Record(decision *Decision) error
+	// GetForSession returns the decisions recorded for the specified
+	// tenant's verification session, in the order they were made. If there
+	// are none, an error wrapping ErrNoDecisions is returned.
+	GetForSession(tenantID, sessionID string) ([]*Decision, error)
+	Close() error
+}
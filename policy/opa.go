@@ -3,24 +3,65 @@
 package policy
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/bundle"
 	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/topdown"
 	"github.com/spf13/viper"
-	"github.com/veraison/ear"
 	"github.com/veraison/services/log"
 )
 
-var ErrBadOPAResult = errors.New("bad result update from policy")
+// ErrBadOPAResult is returned when an OPA policy produces an outcome that
+// cannot be applied to the attestation result.
+var ErrBadOPAResult = ErrBadResultUpdate
 
 //go:embed opa.rego
 var preambleText string
 
+// preambleFile is the name of the preamble module.
+const preambleFile = "opa.rego"
+
+const (
+	// OPAType identifies policies consisting of a single Rego module
+	// written using Rego v0 syntax.
+	OPAType = "opa"
+	// OPAV1Type identifies policies consisting of a single Rego module
+	// written using Rego v1 syntax.
+	OPAV1Type = "opa-v1"
+	// OPABundleType identifies policies provided as an OPA bundle (a
+	// gzipped tarball containing Rego modules and, optionally, data
+	// documents). The bundle is stored base64-encoded in the policy rules.
+	// Modules are assumed to be written in Rego v1 syntax, unless the
+	// bundle manifest specifies otherwise.
+	OPABundleType = "opa-bundle"
+)
+
 type OPA struct {
+	// Type is the type of policies handled by this backend. It must be one
+	// of OPAType (the default if empty), OPAV1Type, or OPABundleType.
+	Type string
+
+	// cache holds prepared (compiled) queries, keyed by the UUID of the
+	// policy they were prepared from. As the policy data is compiled into
+	// the query, each entry records the revision of the data it was
+	// prepared with, and is only used for evaluations with the same
+	// revision.
+	cache map[string]opaCacheEntry
+	mu    sync.RWMutex
+}
+
+type opaCacheEntry struct {
+	dataRevision string
+	query        rego.PreparedEvalQuery
 }
 
 func NewOPA(v *viper.Viper) (*OPA, error) {
@@ -36,14 +77,21 @@ func (o *OPA) Init(v *viper.Viper) error {
 }
 
 func (o *OPA) GetName() string {
-	return "opa"
+	if o.Type == "" {
+		return OPAType
+	}
+
+	return o.Type
 }
 
 func (o *OPA) Evaluate(
 	ctx context.Context,
 	sessionContext map[string]any,
 	scheme string,
+	policyID string,
 	policy string,
+	data map[string]any,
+	dataRevision string,
 	result map[string]any,
 	evidence map[string]any,
 	endorsements []map[string]any,
@@ -57,19 +105,17 @@ func (o *OPA) Evaluate(
 		"endorsements": endorsements,
 	}
 
-	rego := rego.New(
-		// Policies accepted by this API use the Rego v0 syntax.  Keep that
-		// contract while using the OPA v1 Go API.
-		rego.SetRegoVersion(ast.RegoV0),
-		rego.Package("policy"),
-		rego.Module("opa.rego", preambleText),
-		rego.Module("policy.rego", policy),
-		rego.Input(input),
-		rego.Query("outcome"),
-		rego.Dump(log.NamedWriter("opa", log.DebugLevel)),
-	)
+	query, err := o.getPreparedQuery(ctx, policyID, policy, data, dataRevision)
+	if err != nil {
+		return nil, fmt.Errorf("could not Eval policy: %w", err)
+	}
+
+	evalOpts := []rego.EvalOption{rego.EvalInput(input)}
+	if recorder := getRuleRecorder(ctx); recorder != nil {
+		evalOpts = append(evalOpts, rego.EvalQueryTracer(&ruleTracer{recorder}))
+	}
 
-	resultSet, err := rego.Eval(ctx)
+	resultSet, err := query.Eval(ctx, evalOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("could not Eval policy: %w", err)
 	}
@@ -86,84 +132,254 @@ func (o *OPA) Evaluate(
 }
 
 func (o *OPA) Validate(ctx context.Context, policy string) error {
-	rego := rego.New(
-		rego.SetRegoVersion(ast.RegoV0),
-		rego.Package("policy"),
-		rego.Module("opa.rego", preambleText),
-		rego.Module("policy.rego", policy),
-		rego.Query("outcome"),
-		rego.Dump(log.NamedWriter("opa", log.DebugLevel)),
-	)
+	opts, err := o.regoOptions(policy, nil)
+	if err != nil {
+		return err
+	}
 
-	_, err := rego.Compile(ctx)
+	_, err = rego.New(opts...).Compile(ctx)
 	return err
 }
 
 func (o *OPA) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.cache = nil
 }
 
-func processUpdateValue(value interface{}) (map[string]interface{}, error) {
-	rawUpdate, ok := value.(map[string]interface{})
-	if !ok {
-		err := fmt.Errorf(
-			"%w: expected map[string]interface{}, but got %T",
-			ErrBadOPAResult, value)
-		return nil, err
+// Invalidate discards the cached prepared query for the policy with the
+// specified ID, if there is one.
+func (o *OPA) Invalidate(policyID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.cache, policyID)
+}
+
+// getPreparedQuery returns the prepared query for the specified policy and
+// data, compiling it and caching the result if necessary. Policies without an
+// ID are never cached. Only one query is cached per policy, so preparing the
+// query for a new revision of the data replaces the one for the previous
+// revision.
+func (o *OPA) getPreparedQuery(
+	ctx context.Context,
+	policyID string,
+	policy string,
+	data map[string]any,
+	dataRevision string,
+) (rego.PreparedEvalQuery, error) {
+	if policyID == "" {
+		return o.prepareQuery(ctx, policy, data)
 	}
 
-	updateTv := map[string]interface{}{
-		"instance-identity": 0,
-		"configuration":     0,
-		"executables":       0,
-		"file-system":       0,
-		"hardware":          0,
-		"runtime-opaque":    0,
-		"storage-opaque":    0,
-		"sourced-data":      0,
+	o.mu.RLock()
+	entry, ok := o.cache[policyID]
+	o.mu.RUnlock()
+
+	if ok && entry.dataRevision == dataRevision {
+		return entry.query, nil
 	}
 
-	updatedStatus, err := ear.ToTrustTier(rawUpdate["status"])
+	query, err := o.prepareQuery(ctx, policy, data)
 	if err != nil {
-		return nil, err
+		return query, err
 	}
 
-	rawTv, ok := rawUpdate["trust-vector"].(map[string]interface{})
-	if !ok {
-		err := fmt.Errorf(
-			"%w: \"trust-vector\" value should be map[string]interface{}, but got %T",
-			ErrBadOPAResult, value)
-		return nil, err
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cache == nil {
+		o.cache = make(map[string]opaCacheEntry)
 	}
+	o.cache[policyID] = opaCacheEntry{dataRevision: dataRevision, query: query}
 
-	for claim, rawValue := range rawTv {
-		if _, ok := updateTv[claim]; !ok {
-			err := fmt.Errorf("%w: unexpected claim %q ", ErrBadOPAResult, claim)
+	return query, nil
+}
+
+func (o *OPA) prepareQuery(
+	ctx context.Context,
+	policy string,
+	data map[string]any,
+) (rego.PreparedEvalQuery, error) {
+	opts, err := o.regoOptions(policy, data)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+
+	return rego.New(opts...).PrepareForEval(ctx)
+}
+
+// regoOptions returns the options used to construct a rego.Rego for the
+// specified policy, based on the type of policies handled by the backend. The
+// specified data documents (which may be nil) are made available under data.
+func (o *OPA) regoOptions(policy string, data map[string]any) ([]func(*rego.Rego), error) {
+	opts := []func(*rego.Rego){
+		rego.Package("policy"),
+		rego.Query("outcome"),
+		rego.Dump(log.NamedWriter("opa", log.DebugLevel)),
+	}
+	opts = append(opts, opaBuiltins()...)
+
+	switch o.GetName() {
+	case OPAType:
+		if data != nil {
+			opts = append(opts, rego.Store(inmem.NewFromObject(data)))
+		}
+
+		// Policies of this type use the Rego v0 syntax.  Keep that
+		// contract while using the OPA v1 Go API.
+		return append(opts,
+			rego.SetRegoVersion(ast.RegoV0),
+			rego.Module(preambleFile, preambleText),
+			rego.Module("policy.rego", policy),
+		), nil
+	case OPAV1Type:
+		if data != nil {
+			opts = append(opts, rego.Store(inmem.NewFromObject(data)))
+		}
+
+		preamble, err := parsePreamble()
+		if err != nil {
 			return nil, err
 		}
 
-		value, err := ear.ToTrustClaim(rawValue)
+		return append(opts,
+			rego.SetRegoVersion(ast.RegoV1),
+			rego.ParsedModule(preamble),
+			rego.Module("policy.rego", policy),
+		), nil
+	case OPABundleType:
+		preamble, err := parsePreamble()
 		if err != nil {
-			err := fmt.Errorf("%w: bad value %q for %q: %v",
-				ErrBadOPAResult, rawValue, claim, err)
 			return nil, err
 		}
 
-		updateTv[claim] = *value
+		b, err := ReadOPABundle(policy)
+		if err != nil {
+			return nil, err
+		}
+
+		// The bundle is activated into the store when the query is
+		// prepared, so the data is added to the bundle itself.
+		if err := mergeBundleData(b, data); err != nil {
+			return nil, err
+		}
+
+		return append(opts,
+			rego.SetRegoVersion(ast.RegoV1),
+			rego.ParsedModule(preamble),
+			rego.ParsedBundle("policy", b),
+		), nil
+	default:
+		return nil, fmt.Errorf("unexpected OPA policy type %q", o.Type)
 	}
+}
 
-	addedClaims, ok := rawUpdate["added-claims"].(map[string]interface{})
-	if !ok {
-		err := fmt.Errorf(
-			`%w: "added-claims" value should be map[string]interface{}, but got %T`,
-			ErrBadOPAResult, value)
-		return nil, err
+// ruleTracer is a topdown.QueryTracer that records the policy rules whose
+// bodies succeed during an evaluation. Default rules, and rules defined by the
+// preamble, are not recorded.
+type ruleTracer struct {
+	recorder *ruleRecorder
+}
+
+func (o *ruleTracer) Enabled() bool {
+	return true
+}
+
+func (o *ruleTracer) Config() topdown.TraceConfig {
+	return topdown.TraceConfig{}
+}
+
+func (o *ruleTracer) TraceEvent(evt topdown.Event) {
+	if evt.Op != topdown.ExitOp {
+		return
+	}
+
+	rule, ok := evt.Node.(*ast.Rule)
+	if !ok || rule.Default || rule.Location == nil || rule.Location.File == preambleFile {
+		return
+	}
+
+	o.recorder.add(fmt.Sprintf("%s (%s:%d)",
+		rule.Head.Ref(), rule.Location.File, rule.Location.Row))
+}
+
+// mergeBundleData adds the top-level entries of data to the data documents of
+// the specified bundle. Entries already defined by the bundle are not
+// overwritten; an error is returned instead. If the bundle's manifest
+// restricts its roots, the entries are added to the roots, as only data under
+// the roots is activated.
+func mergeBundleData(b *bundle.Bundle, data map[string]any) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if b.Data == nil {
+		b.Data = make(map[string]any)
+	}
+
+	var roots []string
+	if b.Manifest.Roots != nil {
+		roots = *b.Manifest.Roots
+	}
+
+	ownsAll := false
+	for _, root := range roots {
+		if root == "" {
+			ownsAll = true
+			break
+		}
+	}
+
+	for k, v := range data {
+		if _, ok := b.Data[k]; ok {
+			return fmt.Errorf("policy data entry %q conflicts with bundle data", k)
+		}
+
+		b.Data[k] = v
+
+		if !ownsAll {
+			roots = append(roots, k)
+		}
+	}
+
+	b.Manifest.Roots = &roots
+
+	return nil
+}
+
+// parsePreamble parses the preamble module. The preamble is written using Rego
+// v0 syntax, and so is parsed separately, so that it may be compiled alongside
+// modules written using Rego v1 syntax.
+func parsePreamble() (*ast.Module, error) {
+	return ast.ParseModuleWithOpts(preambleFile, preambleText,
+		ast.ParserOptions{RegoVersion: ast.RegoV0})
+}
+
+// EncodeOPABundle returns the representation of the specified OPA bundle
+// (a gzipped tarball) used as the rules of an OPABundleType policy.
+func EncodeOPABundle(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// ReadOPABundle parses the rules of an OPABundleType policy into a bundle.
+func ReadOPABundle(rules string) (*bundle.Bundle, error) {
+	data, err := base64.StdEncoding.DecodeString(rules)
+	if err != nil {
+		return nil, fmt.Errorf("bad bundle encoding: %w", err)
+	}
+
+	b, err := bundle.NewReader(bytes.NewReader(data)).
+		WithRegoVersion(ast.RegoV1).
+		Read()
+	if err != nil {
+		return nil, fmt.Errorf("bad bundle: %w", err)
 	}
 
-	update := map[string]interface{}{
-		"ear.status":                 updatedStatus,
-		"ear.trustworthiness-vector": updateTv,
-		"ear.veraison.policy-claims": &addedClaims,
+	if len(b.Modules) == 0 {
+		return nil, errors.New("bad bundle: no Rego modules found")
 	}
 
-	return update, nil
+	return &b, nil
 }
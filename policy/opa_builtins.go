@@ -0,0 +1,548 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/types"
+	"github.com/veraison/ear"
+	"github.com/veraison/swid"
+)
+
+// opaBuiltins returns the options registering the custom built-in functions
+// available to OPA policies, in addition to the standard OPA ones.
+func opaBuiltins() []func(*rego.Rego) {
+	return []func(*rego.Rego){
+		rego.Function2(&rego.Function{
+			Name: "veraison.measurement_match",
+			Description: "Returns true if the actual CoRIM measurement value matches " +
+				"the reference one, using CoRIM comparison semantics.",
+			Decl:    types.NewFunction(types.Args(types.A, types.A), types.B),
+			Memoize: true,
+		}, builtin2(func(reference, actual any) (any, error) {
+			return measurementMatch(reference, actual)
+		})),
+		rego.Function1(&rego.Function{
+			Name: "veraison.trust_claim",
+			Description: "Parses an EAR trust claim (specified by its value or name) " +
+				"into an object with its \"value\" and \"tier\".",
+			Decl:    types.NewFunction(types.Args(types.A), types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+			Memoize: true,
+		}, func(_ rego.BuiltinContext, a *ast.Term) (*ast.Term, error) {
+			v, err := ast.JSON(a.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			return toTerm(parseTrustClaim(v))
+		}),
+		rego.Function2(&rego.Function{
+			Name: "veraison.version_cmp",
+			Description: "Compares two versions, returning -1, 0, or 1 if the first " +
+				"is, respectively, lower than, equal to, or greater than the second.",
+			Decl:    types.NewFunction(types.Args(types.S, types.S), types.N),
+			Memoize: true,
+		}, builtin2(func(a, b any) (any, error) {
+			return versionCmp(a, b)
+		})),
+	}
+}
+
+// builtin2 adapts a function taking the JSON representations of its arguments
+// into a rego.Builtin2.
+func builtin2(f func(a, b any) (any, error)) rego.Builtin2 {
+	return func(_ rego.BuiltinContext, a, b *ast.Term) (*ast.Term, error) {
+		aVal, err := ast.JSON(a.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		bVal, err := ast.JSON(b.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return toTerm(f(aVal, bVal))
+	}
+}
+
+func toTerm(v any, err error) (*ast.Term, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := ast.InterfaceToValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewTerm(val), nil
+}
+
+// measurementMatch compares the actual CoRIM measurement value (mval) against
+// the reference one, returning true if every field in the reference matches
+// the corresponding field in the actual value. Either may be a full
+// measurement (i.e. an object with "key" and "value"), in which case their
+// keys must also match. Fields are compared as follows:
+//
+//   - "svn": an "exact-value" SVN must be equal to the actual SVN, and a
+//     "min-value" SVN must not be greater than it.
+//   - "digests": at least one hash algorithm must be common to the reference
+//     and the actual digests, and each actual digest using a common algorithm
+//     must equal one of the reference digests using that algorithm.
+//   - "raw-value": the values must be equal once the mask (either from a
+//     "masked" raw value, or from "raw-value-mask"), if any, is applied to
+//     both.
+//   - "int-range": the actual integer must be within the reference range.
+//   - other fields must be equal.
+func measurementMatch(reference, actual any) (bool, error) {
+	refMval, refKey, err := toMval(reference)
+	if err != nil {
+		return false, fmt.Errorf("reference: %w", err)
+	}
+
+	actMval, actKey, err := toMval(actual)
+	if err != nil {
+		return false, fmt.Errorf("actual: %w", err)
+	}
+
+	if refKey != nil && actKey != nil && !reflect.DeepEqual(refKey, actKey) {
+		return false, nil
+	}
+
+	for field, refVal := range refMval {
+		if field == "raw-value-mask" {
+			continue // applied when comparing "raw-value"
+		}
+
+		actVal, ok := actMval[field]
+		if !ok {
+			return false, nil
+		}
+
+		var match bool
+
+		switch field {
+		case "svn":
+			match, err = svnMatch(refVal, actVal)
+		case "digests":
+			match, err = digestsMatch(refVal, actVal)
+		case "raw-value":
+			match, err = rawValueMatch(refVal, refMval["raw-value-mask"], actVal)
+		case "int-range":
+			match, err = intRangeMatch(refVal, actVal)
+		default:
+			match = reflect.DeepEqual(refVal, actVal)
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", field, err)
+		}
+
+		if !match {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// toMval returns the measurement value represented by v, along with its key,
+// if v is a full measurement.
+func toMval(v any) (map[string]any, any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("expected an object, but got %T", v)
+	}
+
+	key, hasKey := m["key"]
+	val, hasVal := m["value"]
+
+	if hasKey && hasVal {
+		mval, ok := val.(map[string]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("expected an object value, but got %T", val)
+		}
+
+		return mval, key, nil
+	}
+
+	return m, nil, nil
+}
+
+func svnMatch(reference, actual any) (bool, error) {
+	refType, refSVN, err := parseTypedInt(reference, "exact-value")
+	if err != nil {
+		return false, err
+	}
+
+	actType, actSVN, err := parseTypedInt(actual, "exact-value")
+	if err != nil {
+		return false, err
+	}
+
+	if actType != "exact-value" {
+		return false, nil
+	}
+
+	switch refType {
+	case "exact-value":
+		return actSVN.Cmp(refSVN) == 0, nil
+	case "min-value":
+		return actSVN.Cmp(refSVN) >= 0, nil
+	default:
+		return false, fmt.Errorf("unexpected SVN type %q", refType)
+	}
+}
+
+func intRangeMatch(reference, actual any) (bool, error) {
+	_, actInt, err := parseTypedInt(actual, "rawIntInteger")
+	if err != nil {
+		return false, err
+	}
+
+	ref, ok := reference.(map[string]any)
+	if !ok {
+		_, refInt, err := parseTypedInt(reference, "rawIntInteger")
+		if err != nil {
+			return false, err
+		}
+
+		return actInt.Cmp(refInt) == 0, nil
+	}
+
+	switch ref["type"] {
+	case "rawIntInteger":
+		_, refInt, err := parseTypedInt(reference, "rawIntInteger")
+		if err != nil {
+			return false, err
+		}
+
+		return actInt.Cmp(refInt) == 0, nil
+	case "rawIntRange":
+		bounds, ok := ref["value"].(map[string]any)
+		if !ok {
+			return false, fmt.Errorf("expected range object, but got %T", ref["value"])
+		}
+
+		if minVal, ok := bounds["min"]; ok {
+			_, rangeMin, err := parseTypedInt(minVal, "")
+			if err != nil {
+				return false, err
+			}
+
+			if actInt.Cmp(rangeMin) < 0 {
+				return false, nil
+			}
+		}
+
+		if maxVal, ok := bounds["max"]; ok {
+			_, rangeMax, err := parseTypedInt(maxVal, "")
+			if err != nil {
+				return false, err
+			}
+
+			if actInt.Cmp(rangeMax) > 0 {
+				return false, nil
+			}
+		}
+
+		return true, nil
+	default:
+		return false, fmt.Errorf("unexpected int-range type %v", ref["type"])
+	}
+}
+
+// parseTypedInt parses an integer that is either a number, or a
+// {"type": ..., "value": <number>} object. defaultType is returned as the type
+// of plain numbers.
+func parseTypedInt(v any, defaultType string) (string, *big.Int, error) {
+	typ := defaultType
+
+	if m, ok := v.(map[string]any); ok {
+		t, ok := m["type"].(string)
+		if !ok {
+			return "", nil, errors.New(`missing "type"`)
+		}
+
+		typ = t
+		v = m["value"]
+	}
+
+	var s string
+	switch t := v.(type) {
+	case json.Number:
+		s = t.String()
+	case float64:
+		s = fmt.Sprint(t)
+	case int, int64, uint64:
+		s = fmt.Sprint(t)
+	default:
+		return "", nil, fmt.Errorf("expected an integer, but got %T", v)
+	}
+
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return "", nil, fmt.Errorf("expected an integer, but got %q", s)
+	}
+
+	return typ, i, nil
+}
+
+func digestsMatch(reference, actual any) (bool, error) {
+	refDigests, err := parseDigests(reference)
+	if err != nil {
+		return false, err
+	}
+
+	actDigests, err := parseDigests(actual)
+	if err != nil {
+		return false, err
+	}
+
+	common := false
+
+	for _, act := range actDigests {
+		found, compared := false, false
+
+		for _, ref := range refDigests {
+			if ref.HashAlgID != act.HashAlgID {
+				continue
+			}
+
+			compared = true
+			if bytes.Equal(ref.HashValue, act.HashValue) {
+				found = true
+				break
+			}
+		}
+
+		if compared {
+			common = true
+			if !found {
+				return false, nil
+			}
+		}
+	}
+
+	return common, nil
+}
+
+// parseDigests parses a list of digests, each either in the [alg, value] form
+// used by CoRIM (where alg is the algorithm ID or name, and value the
+// base64url-encoded hash), or in the "alg;value" form used by SWID (where
+// value is the standard base64-encoded hash).
+func parseDigests(v any) ([]swid.HashEntry, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of digests, but got %T", v)
+	}
+
+	ret := make([]swid.HashEntry, len(list))
+
+	for i, entry := range list {
+		switch t := entry.(type) {
+		case string:
+			he, err := swid.ParseHashEntry(t)
+			if err != nil {
+				return nil, fmt.Errorf("digest at index %d: %w", i, err)
+			}
+			ret[i] = he
+		case []any:
+			if len(t) != 2 {
+				return nil, fmt.Errorf("digest at index %d: expected [alg, value]", i)
+			}
+
+			var alg uint64
+			switch a := t[0].(type) {
+			case string:
+				alg = swid.AlgIDFromString(a)
+				if alg == 0 {
+					return nil, fmt.Errorf("digest at index %d: unknown algorithm %q", i, a)
+				}
+			default:
+				_, algInt, err := parseTypedInt(a, "")
+				if err != nil || !algInt.IsUint64() {
+					return nil, fmt.Errorf("digest at index %d: bad algorithm %v", i, a)
+				}
+				alg = algInt.Uint64()
+			}
+
+			encoded, ok := t[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("digest at index %d: expected string value", i)
+			}
+
+			value, err := decodeBase64(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("digest at index %d: %w", i, err)
+			}
+
+			ret[i] = swid.HashEntry{HashAlgID: alg, HashValue: value}
+		default:
+			return nil, fmt.Errorf("digest at index %d: unexpected %T", i, entry)
+		}
+	}
+
+	return ret, nil
+}
+
+func rawValueMatch(reference, refMask, actual any) (bool, error) {
+	var refBytes, mask []byte
+	var err error
+
+	if refMask != nil {
+		encoded, ok := refMask.(string)
+		if !ok {
+			return false, fmt.Errorf("expected string mask, but got %T", refMask)
+		}
+
+		if mask, err = decodeBase64(encoded); err != nil {
+			return false, err
+		}
+	}
+
+	ref, ok := reference.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("expected an object, but got %T", reference)
+	}
+
+	switch ref["type"] {
+	case "bytes":
+		if refBytes, err = rawValueBytes(reference); err != nil {
+			return false, err
+		}
+	case "masked":
+		masked, ok := ref["value"].(map[string]any)
+		if !ok {
+			return false, fmt.Errorf("expected masked value object, but got %T", ref["value"])
+		}
+
+		encVal, _ := masked["value"].(string)
+		if refBytes, err = decodeBase64(encVal); err != nil {
+			return false, err
+		}
+
+		encMask, _ := masked["mask"].(string)
+		if mask, err = decodeBase64(encMask); err != nil {
+			return false, err
+		}
+	default:
+		return false, fmt.Errorf("unexpected raw-value type %v", ref["type"])
+	}
+
+	actBytes, err := rawValueBytes(actual)
+	if err != nil {
+		return false, err
+	}
+
+	if len(actBytes) != len(refBytes) {
+		return false, nil
+	}
+
+	if mask == nil {
+		return bytes.Equal(actBytes, refBytes), nil
+	}
+
+	if len(mask) != len(refBytes) {
+		return false, fmt.Errorf("mask length %d does not match value length %d",
+			len(mask), len(refBytes))
+	}
+
+	for i := range refBytes {
+		if actBytes[i]&mask[i] != refBytes[i]&mask[i] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// rawValueBytes returns the bytes of a raw value that is either a
+// base64-encoded string, or a {"type": "bytes", "value": <base64>} object.
+func rawValueBytes(v any) ([]byte, error) {
+	if m, ok := v.(map[string]any); ok {
+		if m["type"] != "bytes" {
+			return nil, fmt.Errorf("unexpected raw-value type %v", m["type"])
+		}
+
+		v = m["value"]
+	}
+
+	encoded, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected base64 string, but got %T", v)
+	}
+
+	return decodeBase64(encoded)
+}
+
+// decodeBase64 decodes a string using any of the standard or URL-safe base64
+// encodings, padded or not.
+func decodeBase64(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	} {
+		if ret, err := enc.DecodeString(s); err == nil {
+			return ret, nil
+		}
+	}
+
+	return nil, fmt.Errorf("bad base64 encoding: %q", s)
+}
+
+// parseTrustClaim parses an EAR trust claim, specified either as its integer
+// value or its name (e.g. "approved_rt"), returning its value and tier.
+func parseTrustClaim(v any) (map[string]any, error) {
+	if f, ok := v.(float64); ok {
+		v = json.Number(fmt.Sprint(f))
+	}
+
+	claim, err := ear.ToTrustClaim(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"value": int(*claim),
+		"tier":  claim.GetTier().String(),
+	}, nil
+}
+
+// versionCmp compares two versions, returning -1, 0, or 1 if a is,
+// respectively, lower than, equal to, or greater than b. Versions are parsed
+// as SemVer, leniently: a "v" prefix, and missing minor or patch components
+// are allowed, and pre-release versions are ordered before the corresponding
+// release.
+func versionCmp(a, b any) (int, error) {
+	aStr, aOK := a.(string)
+	bStr, bOK := b.(string)
+	if !aOK || !bOK {
+		return 0, errors.New("versions must be strings")
+	}
+
+	aVer, err := semver.NewVersion(aStr)
+	if err != nil {
+		return 0, fmt.Errorf("bad version %q: %w", aStr, err)
+	}
+
+	bVer, err := semver.NewVersion(bStr)
+	if err != nil {
+		return 0, fmt.Errorf("bad version %q: %w", bStr, err)
+	}
+
+	return aVer.Compare(bVer), nil
+}
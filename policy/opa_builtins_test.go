@@ -0,0 +1,209 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/ear"
+)
+
+func Test_measurementMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		reference string
+		actual    string
+		expected  bool
+		err       string
+	}{
+		{
+			name:      "exact SVN",
+			reference: `{"svn": {"type": "exact-value", "value": 3}}`,
+			actual:    `{"svn": 3}`,
+			expected:  true,
+		},
+		{
+			name:      "exact SVN mismatch",
+			reference: `{"svn": {"type": "exact-value", "value": 3}}`,
+			actual:    `{"svn": {"type": "exact-value", "value": 4}}`,
+			expected:  false,
+		},
+		{
+			name:      "min SVN",
+			reference: `{"svn": {"type": "min-value", "value": 3}}`,
+			actual:    `{"svn": 4}`,
+			expected:  true,
+		},
+		{
+			name:      "min SVN too low",
+			reference: `{"svn": {"type": "min-value", "value": 3}}`,
+			actual:    `{"svn": 2}`,
+			expected:  false,
+		},
+		{
+			name:      "digest",
+			reference: `{"digests": [[1, "3q2-7w"], [7, "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4v"]]}`,
+			actual:    `{"digests": ["sha-256;3q2+7w=="]}`,
+			expected:  true,
+		},
+		{
+			name:      "digest mismatch",
+			reference: `{"digests": [[1, "3q2-7w"]]}`,
+			actual:    `{"digests": [["sha-256", "3q2-7A"]]}`,
+			expected:  false,
+		},
+		{
+			name:      "digest no common algorithm",
+			reference: `{"digests": [[1, "3q2-7w"]]}`,
+			actual:    `{"digests": [[7, "3q2-7w"]]}`,
+			expected:  false,
+		},
+		{
+			name:      "masked raw value",
+			reference: `{"raw-value": {"type": "masked", "value": {"value": "AQIDBA==", "mask": "/wD/AA=="}}}`,
+			actual:    `{"raw-value": {"type": "bytes", "value": "AQADAA=="}}`,
+			expected:  true,
+		},
+		{
+			name:      "raw value with mask",
+			reference: `{"raw-value": {"type": "bytes", "value": "AQIDBA=="}, "raw-value-mask": "/wD/AA=="}`,
+			actual:    `{"raw-value": "AgIDBA=="}`,
+			expected:  false,
+		},
+		{
+			name:      "raw value length mismatch",
+			reference: `{"raw-value": {"type": "bytes", "value": "AQIDBA=="}}`,
+			actual:    `{"raw-value": "AQID"}`,
+			expected:  false,
+		},
+		{
+			name:      "int range",
+			reference: `{"int-range": {"type": "rawIntRange", "value": {"min": 2, "max": 5}}}`,
+			actual:    `{"int-range": {"type": "rawIntInteger", "value": 5}}`,
+			expected:  true,
+		},
+		{
+			name:      "int range open",
+			reference: `{"int-range": {"type": "rawIntRange", "value": {"min": 2}}}`,
+			actual:    `{"int-range": 1}`,
+			expected:  false,
+		},
+		{
+			name:      "other fields",
+			reference: `{"version": {"value": "1.2.3", "scheme": "semver"}, "name": "fw"}`,
+			actual:    `{"version": {"value": "1.2.3", "scheme": "semver"}, "name": "fw", "svn": 1}`,
+			expected:  true,
+		},
+		{
+			name:      "missing field",
+			reference: `{"name": "fw", "svn": 1}`,
+			actual:    `{"name": "fw"}`,
+			expected:  false,
+		},
+		{
+			name:      "measurements",
+			reference: `{"key": {"type": "uint", "value": 1}, "value": {"svn": 1}}`,
+			actual:    `{"key": {"type": "uint", "value": 2}, "value": {"svn": 1}}`,
+			expected:  false,
+		},
+		{
+			name:      "bad digest",
+			reference: `{"digests": [[1, "not base64!"]]}`,
+			actual:    `{"digests": [[1, "3q2-7w"]]}`,
+			err:       "digests: digest at index 0: bad base64 encoding",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var reference, actual any
+			require.NoError(t, json.Unmarshal([]byte(tc.reference), &reference))
+			require.NoError(t, json.Unmarshal([]byte(tc.actual), &actual))
+
+			match, err := measurementMatch(reference, actual)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, match)
+		})
+	}
+}
+
+func Test_versionCmp(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2", "1.2.0", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.2.3-rc1", "1.2.3", -1},
+	} {
+		ret, err := versionCmp(tc.a, tc.b)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, ret, "%s vs %s", tc.a, tc.b)
+	}
+
+	_, err := versionCmp("one", "1.0.0")
+	assert.ErrorContains(t, err, `bad version "one"`)
+}
+
+func Test_parseTrustClaim(t *testing.T) {
+	ret, err := parseTrustClaim(json.Number("96"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"value": 96, "tier": "contraindicated"}, ret)
+
+	ret, err = parseTrustClaim("approved_rt")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"value": 2, "tier": "affirming"}, ret)
+
+	_, err = parseTrustClaim("nope")
+	assert.ErrorContains(t, err, "not a valid TrustClaim value")
+}
+
+func Test_OPA_Evaluate_builtins(t *testing.T) {
+	ctx := context.Background()
+
+	pa := &OPA{Type: OPAV1Type}
+	defer pa.Close()
+
+	resultMap, err := jsonFileToResultMap("test/inputs/psa-result.json")
+	require.NoError(t, err)
+
+	evidenceMap, err := jsonFileToMap("test/inputs/psa-evidence.json")
+	require.NoError(t, err)
+
+	rules, err := os.ReadFile("test/policies/builtins-v1.rego")
+	require.NoError(t, err)
+
+	evaluate := func(digest string) map[string]any {
+		endorsements := []map[string]any{{
+			"measurements": []any{map[string]any{
+				"key":   map[string]any{"type": "uint", "value": 1},
+				"value": map[string]any{"digests": []any{[]any{1, digest}}},
+			}},
+		}}
+
+		res, err := pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", "", string(rules), nil, "",
+			resultMap, evidenceMap["evidence"].(map[string]any), endorsements)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	// the BL measurement-value from the evidence, base64url-encoded
+	res := evaluate("BwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg")
+	assert.Equal(t, ear.ApprovedRuntimeClaim,
+		res["ear.trustworthiness-vector"].(map[string]any)["executables"])
+	assert.Equal(t, ear.TrustTierAffirming, *res["ear.status"].(*ear.TrustTier))
+
+	res = evaluate("CwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg")
+	assert.Equal(t, ear.ContraindicatedRuntimeClaim,
+		res["ear.trustworthiness-vector"].(map[string]any)["executables"])
+}
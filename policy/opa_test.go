@@ -3,11 +3,16 @@
 package policy
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,7 +48,7 @@ func (o EvaluateTestVector) Run(t *testing.T, ctx context.Context, pa *OPA) {
 	policy, err := os.ReadFile(o.PolicyPath)
 	require.NoError(t, err)
 
-	res, err := pa.Evaluate(ctx, map[string]any{}, o.Scheme, string(policy),
+	res, err := pa.Evaluate(ctx, map[string]any{}, o.Scheme, "", string(policy), nil, "",
 		resultMap, evidenceMap["evidence"].(map[string]any), endorsements)
 	if o.Expected.Error == "" {
 		require.NoError(t, err)
@@ -135,6 +140,223 @@ func Test_OPA_Validate(t *testing.T) {
 	}
 }
 
+func Test_OPA_Evaluate_cache(t *testing.T) {
+	ctx := context.Background()
+
+	pa, err := NewOPA(nil)
+	require.NoError(t, err)
+	defer pa.Close()
+
+	resultMap, err := jsonFileToResultMap("test/inputs/psa-result.json")
+	require.NoError(t, err)
+
+	evidenceMap, err := jsonFileToMap("test/inputs/psa-evidence.json")
+	require.NoError(t, err)
+	evidence := evidenceMap["evidence"].(map[string]any)
+
+	rules, err := os.ReadFile("test/policies/empty.rego")
+	require.NoError(t, err)
+
+	policyID := "7df7714e-aa04-4638-bcbf-434b1dd720f1"
+
+	_, err = pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", policyID, string(rules), nil, "",
+		resultMap, evidence, nil)
+	require.NoError(t, err)
+	assert.Contains(t, pa.cache, policyID)
+
+	// the cached query is used, so the rules are not recompiled
+	_, err = pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", policyID, "bad_rule:;;", nil, "",
+		resultMap, evidence, nil)
+	assert.NoError(t, err)
+
+	_, err = pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", "", string(rules), nil, "",
+		resultMap, evidence, nil)
+	require.NoError(t, err)
+	assert.Len(t, pa.cache, 1)
+
+	pa.Invalidate(policyID)
+	assert.NotContains(t, pa.cache, policyID)
+
+	_, err = pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", policyID, "bad_rule:;;", nil, "",
+		resultMap, evidence, nil)
+	assert.ErrorContains(t, err, "rego_parse_error")
+}
+
+func Test_OPA_Evaluate_v1(t *testing.T) {
+	ctx := context.Background()
+
+	pa := &OPA{Type: OPAV1Type}
+	defer pa.Close()
+
+	assert.Equal(t, "opa-v1", pa.GetName())
+
+	resultMap, err := jsonFileToResultMap("test/inputs/psa-result.json")
+	require.NoError(t, err)
+
+	evidenceMap, err := jsonFileToMap("test/inputs/psa-evidence.json")
+	require.NoError(t, err)
+
+	rules, err := os.ReadFile("test/policies/simple-v1.rego")
+	require.NoError(t, err)
+
+	res, err := pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", "", string(rules), nil, "",
+		resultMap, evidenceMap["evidence"].(map[string]any), nil)
+	require.NoError(t, err)
+
+	tv := res["ear.trustworthiness-vector"].(map[string]any)
+	assert.Equal(t, ear.ApprovedRuntimeClaim, tv["executables"])
+
+	// v1 syntax is rejected by the v0 backend, and vice versa
+	v0 := &OPA{}
+	assert.ErrorContains(t, v0.Validate(ctx, string(rules)), "rego_parse_error")
+
+	v0rules, err := os.ReadFile("test/policies/sw-up-to-dateness.rego")
+	require.NoError(t, err)
+	assert.ErrorContains(t, pa.Validate(ctx, string(v0rules)), "rego_parse_error")
+}
+
+func Test_OPA_Evaluate_bundle(t *testing.T) {
+	ctx := context.Background()
+
+	pa := &OPA{Type: OPABundleType}
+	defer pa.Close()
+
+	resultMap, err := jsonFileToResultMap("test/inputs/psa-result.json")
+	require.NoError(t, err)
+
+	rules := EncodeOPABundle(tarGzDir(t, "test/bundles/psa-allowlist"))
+	require.NoError(t, pa.Validate(ctx, rules))
+
+	for _, tv := range []struct {
+		EvidencePath string
+		Expected     ear.TrustClaim
+	}{
+		{"test/inputs/psa-evidence.json", ear.ApprovedRuntimeClaim},
+		{"test/inputs/psa-evidence-updatedBL.json", ear.ContraindicatedRuntimeClaim},
+	} {
+		evidenceMap, err := jsonFileToMap(tv.EvidencePath)
+		require.NoError(t, err)
+
+		res, err := pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", "", rules, nil, "",
+			resultMap, evidenceMap["evidence"].(map[string]any), nil)
+		require.NoError(t, err)
+
+		trustVector := res["ear.trustworthiness-vector"].(map[string]any)
+		assert.Equal(t, tv.Expected, trustVector["executables"], tv.EvidencePath)
+	}
+
+	err = pa.Validate(ctx, "not base64!")
+	assert.ErrorContains(t, err, "bad bundle encoding")
+
+	err = pa.Validate(ctx, EncodeOPABundle([]byte("not a bundle")))
+	assert.ErrorContains(t, err, "bad bundle")
+}
+
+func Test_OPA_Evaluate_data(t *testing.T) {
+	ctx := context.Background()
+
+	pa := &OPA{}
+	defer pa.Close()
+
+	policyID := "6b1e3c1a-5c5e-4a3f-9d0a-7c2f1e4b8a90"
+
+	resultMap, err := jsonFileToResultMap("test/inputs/psa-result.json")
+	require.NoError(t, err)
+
+	evidenceMap, err := jsonFileToMap("test/inputs/psa-evidence.json")
+	require.NoError(t, err)
+	evidence := evidenceMap["evidence"].(map[string]any)
+
+	rules, err := os.ReadFile("test/policies/data-allowlist.rego")
+	require.NoError(t, err)
+
+	evaluate := func(data map[string]any, dataRevision string) any {
+		res, err := pa.Evaluate(ctx, map[string]any{}, "PSA_IOT", policyID, string(rules),
+			data, dataRevision, resultMap, evidence, nil)
+		require.NoError(t, err)
+
+		return res["ear.trustworthiness-vector"].(map[string]any)["executables"]
+	}
+
+	allowed := map[string]any{
+		"allowlist": map[string]any{"bl_versions": []any{"3.4.2"}},
+	}
+	denied := map[string]any{
+		"allowlist": map[string]any{"bl_versions": []any{"3.5.1"}},
+	}
+
+	assert.Equal(t, ear.ApprovedRuntimeClaim, evaluate(allowed, "rev-1"))
+
+	// the prepared query (and so the data) is cached for the revision
+	assert.Equal(t, ear.ApprovedRuntimeClaim, evaluate(denied, "rev-1"))
+	pa.Invalidate(policyID)
+	assert.Equal(t, ear.ContraindicatedRuntimeClaim, evaluate(denied, "rev-1"))
+
+	// a new revision of the data is used without the need to invalidate
+	assert.Equal(t, ear.ApprovedRuntimeClaim, evaluate(allowed, "rev-2"))
+
+	// a query prepared with a previous revision (e.g. by a concurrent
+	// evaluation) does not replace the data for the current one
+	assert.Equal(t, ear.ContraindicatedRuntimeClaim, evaluate(denied, "rev-1"))
+	assert.Equal(t, ear.ApprovedRuntimeClaim, evaluate(allowed, "rev-2"))
+	assert.Len(t, pa.cache, 1)
+
+	// data must not clash with the data inside bundles
+	bpa := &OPA{Type: OPABundleType}
+	defer bpa.Close()
+
+	bundleRules := EncodeOPABundle(tarGzDir(t, "test/bundles/psa-allowlist"))
+
+	res, err := bpa.Evaluate(ctx, map[string]any{}, "PSA_IOT", "", bundleRules,
+		map[string]any{"denylist": []any{}}, "", resultMap, evidence, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ear.ApprovedRuntimeClaim,
+		res["ear.trustworthiness-vector"].(map[string]any)["executables"])
+
+	_, err = bpa.Evaluate(ctx, map[string]any{}, "PSA_IOT", "", bundleRules,
+		denied, "", resultMap, evidence, nil)
+	assert.ErrorContains(t, err, `policy data entry "allowlist" conflicts with bundle data`)
+}
+
+// tarGzDir returns a gzipped tarball containing the files under the specified
+// directory, with paths relative to that directory.
+func tarGzDir(t *testing.T, dir string) []byte {
+	var buf bytes.Buffer
+
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		hdr := &tar.Header{Name: "/" + filepath.ToSlash(rel), Mode: 0o600, Size: int64(len(data))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		_, err = tw.Write(data)
+		return err
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
 func jsonFileToMap(path string) (map[string]any, error) {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
@@ -36,22 +36,45 @@ type Policy struct {
 	// Active indicates whether this policy instance is currently active
 	// for the associated key.
 	Active bool `json:"active"`
+
+	// CreatedBy identifies the principal (as reported by the authorizer)
+	// that created this policy version.
+	CreatedBy string `json:"created_by,omitempty"`
+
+	// ATime is the time this policy version was most recently activated.
+	// It is nil if the version has never been activated.
+	ATime *time.Time `json:"atime,omitempty"`
+
+	// ActivatedBy identifies the principal (as reported by the authorizer)
+	// that most recently activated this policy version.
+	ActivatedBy string `json:"activated_by,omitempty"`
+
+	// ScheduledActivation is the time at which this policy version is
+	// scheduled to be activated. It is nil if no activation is pending.
+	ScheduledActivation *time.Time `json:"scheduled_activation,omitempty"`
+
+	// ScheduledBy identifies the principal (as reported by the authorizer)
+	// that scheduled the pending activation.
+	ScheduledBy string `json:"scheduled_by,omitempty"`
 }
 
 // NewPolicy creates a new Policy based on the specified PolicyID and rules.
-func NewPolicy(key PolicyKey, name, typ, rules string) (*Policy, error) {
+// user identifies the principal creating the policy; it may be empty if
+// unknown.
+func NewPolicy(key PolicyKey, name, typ, rules, user string) (*Policy, error) {
 	polUUID, err := uuid.NewUUID()
 	if err != nil {
 		return nil, err
 	}
 
 	return &Policy{
-		StoreKey: key,
-		UUID:     polUUID,
-		CTime:    time.Now(),
-		Type:     typ,
-		Name:     name,
-		Rules:    rules,
+		StoreKey:  key,
+		UUID:      polUUID,
+		CTime:     time.Now(),
+		Type:      typ,
+		Name:      name,
+		Rules:     rules,
+		CreatedBy: user,
 	}, nil
 }
 
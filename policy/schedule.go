@@ -0,0 +1,131 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrNoPendingActivation = errors.New("no pending activation")
+
+// ScheduleActivation schedules the policy version with the specified id for
+// the specified key to be activated at the specified time. user identifies the
+// principal scheduling the activation. If at is not in the future, the version
+// is activated immediately. Scheduling a version that already has a pending
+// activation replaces it.
+//
+// A scheduled activation takes effect the first time the policies for the key
+// are retrieved from the store at, or after, the scheduled time. It is
+// recorded in the policy as if the version had been activated at the scheduled
+// time by the principal that scheduled it.
+func (o *Store) ScheduleActivation(key PolicyKey, id uuid.UUID, at time.Time, user string) error {
+	if !at.After(time.Now()) {
+		return o.Activate(key, id, user)
+	}
+
+	return o.modifyExisting(key, func(policies []*Policy) ([]*Policy, error) {
+		pol := findPolicy(policies, id)
+		if pol == nil {
+			return nil, fmt.Errorf("%w with UUID %q for key %q", ErrNoPolicy, id, key.String())
+		}
+
+		pol.ScheduledActivation = &at
+		pol.ScheduledBy = user
+
+		return policies, nil
+	})
+}
+
+// GetPending returns the versions of the policy with the specified key that
+// have a pending activation, ordered by their scheduled activation time,
+// earliest first. The returned slice is empty if there are none.
+func (o *Store) GetPending(key PolicyKey) ([]*Policy, error) {
+	policies, err := o.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Policy, 0, len(policies))
+	for _, pol := range policies {
+		if pol.ScheduledActivation != nil {
+			ret = append(ret, pol)
+		}
+	}
+
+	sortBySchedule(ret)
+
+	return ret, nil
+}
+
+// CancelActivation cancels the pending activation of the policy version with
+// the specified id for the specified key. An error wrapping
+// ErrNoPendingActivation is returned if the version does not have one.
+func (o *Store) CancelActivation(key PolicyKey, id uuid.UUID) error {
+	return o.modifyExisting(key, func(policies []*Policy) ([]*Policy, error) {
+		pol := findPolicy(policies, id)
+		if pol == nil {
+			return nil, fmt.Errorf("%w with UUID %q for key %q", ErrNoPolicy, id, key.String())
+		}
+
+		if pol.ScheduledActivation == nil {
+			return nil, fmt.Errorf("%w for UUID %q under key %q",
+				ErrNoPendingActivation, id, key.String())
+		}
+
+		pol.ScheduledActivation = nil
+		pol.ScheduledBy = ""
+
+		return policies, nil
+	})
+}
+
+// applySchedule updates policies (all versions of the same policy) to reflect
+// the activations scheduled at, or before, now. Due activations are applied in
+// the order they were scheduled for, so that the version with the latest one
+// ends up active, and the activation times of the others are retained for
+// Rollback.
+func applySchedule(policies []*Policy, now time.Time) {
+	var due []*Policy
+	for _, pol := range policies {
+		if pol.ScheduledActivation != nil && !pol.ScheduledActivation.After(now) {
+			due = append(due, pol)
+		}
+	}
+
+	sortBySchedule(due)
+
+	for _, pol := range due {
+		for _, other := range policies {
+			other.Active = false
+		}
+
+		at := *pol.ScheduledActivation
+		pol.Active = true
+		pol.ATime = &at
+		pol.ActivatedBy = pol.ScheduledBy
+		pol.ScheduledActivation = nil
+		pol.ScheduledBy = ""
+	}
+}
+
+func sortBySchedule(policies []*Policy) {
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].ScheduledActivation.Before(*policies[j].ScheduledActivation)
+	})
+}
+
+func findPolicy(policies []*Policy, id uuid.UUID) *Policy {
+	for _, pol := range policies {
+		if bytes.Equal(id[:], pol.UUID[:]) {
+			return pol
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func Test_Store_ScheduleActivation(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "policy"}
+
+	first, err := store.Add(key, "first", "test", "rules 1", "")
+	require.NoError(t, err)
+	require.NoError(t, store.Activate(key, first.UUID, "alice"))
+
+	second, err := store.Update(key, "second", "test", "rules 2", "")
+	require.NoError(t, err)
+
+	third, err := store.Update(key, "third", "test", "rules 3", "")
+	require.NoError(t, err)
+
+	later := time.Now().Add(2 * time.Hour)
+	soon := time.Now().Add(time.Hour)
+
+	require.NoError(t, store.ScheduleActivation(key, third.UUID, later, "carol"))
+	require.NoError(t, store.ScheduleActivation(key, second.UUID, soon, "bob"))
+
+	pending, err := store.GetPending(key)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, second.UUID, pending[0].UUID)
+	assert.Equal(t, "bob", pending[0].ScheduledBy)
+	assert.Equal(t, third.UUID, pending[1].UUID)
+
+	active, err := store.GetActive(key)
+	require.NoError(t, err)
+	assert.Equal(t, first.UUID, active.UUID)
+
+	require.NoError(t, store.CancelActivation(key, third.UUID))
+
+	err = store.CancelActivation(key, third.UUID)
+	assert.ErrorIs(t, err, ErrNoPendingActivation)
+
+	pending, err = store.GetPending(key)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.UUID, pending[0].UUID)
+
+	// simulate the scheduled time passing by re-writing the schedule
+	due := time.Now().Add(-time.Minute)
+	err = store.modify(key, func(policies []*Policy) ([]*Policy, error) {
+		for _, pol := range policies {
+			if pol.UUID == second.UUID {
+				pol.ScheduledActivation = &due
+			}
+		}
+
+		return policies, nil
+	})
+	require.NoError(t, err)
+
+	active, err = store.GetActive(key)
+	require.NoError(t, err)
+	assert.Equal(t, second.UUID, active.UUID)
+	assert.Equal(t, "bob", active.ActivatedBy)
+	assert.True(t, due.Equal(*active.ATime))
+	assert.Nil(t, active.ScheduledActivation)
+
+	pending, err = store.GetPending(key)
+	require.NoError(t, err)
+	assert.Len(t, pending, 0)
+
+	rolledBack, err := store.Rollback(key, "dave")
+	require.NoError(t, err)
+	assert.Equal(t, first.UUID, rolledBack.UUID)
+
+	// activation times that are not in the future take effect immediately
+	require.NoError(t, store.ScheduleActivation(key, third.UUID, time.Now(), "erin"))
+
+	active, err = store.GetActive(key)
+	require.NoError(t, err)
+	assert.Equal(t, third.UUID, active.UUID)
+	assert.Equal(t, "erin", active.ActivatedBy)
+
+	err = store.ScheduleActivation(key, uuid.New(), later, "")
+	assert.ErrorIs(t, err, ErrNoPolicy)
+}
+
+func Test_applySchedule(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-2 * time.Hour)
+	recent := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	policies := []*Policy{
+		{Name: "active", Active: true, ATime: &past},
+		{Name: "recent", ScheduledActivation: &recent, ScheduledBy: "bob"},
+		{Name: "past", ScheduledActivation: &past, ScheduledBy: "alice"},
+		{Name: "future", ScheduledActivation: &future},
+	}
+
+	applySchedule(policies, now)
+
+	assert.False(t, policies[0].Active)
+
+	assert.True(t, policies[1].Active)
+	assert.Equal(t, recent, *policies[1].ATime)
+	assert.Equal(t, "bob", policies[1].ActivatedBy)
+	assert.Nil(t, policies[1].ScheduledActivation)
+
+	assert.False(t, policies[2].Active)
+	assert.Equal(t, past, *policies[2].ATime)
+	assert.Equal(t, "alice", policies[2].ActivatedBy)
+
+	assert.False(t, policies[3].Active)
+	assert.Equal(t, future, *policies[3].ScheduledActivation)
+}
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package policy
 
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
@@ -16,6 +18,14 @@ import (
 
 var ErrNoPolicy = errors.New("no policy found")
 var ErrNoActivePolicy = errors.New("no active policy for key")
+var ErrPolicyActive = errors.New("policy is active")
+var ErrNoPreviousPolicy = errors.New("no previously active policy for key")
+var ErrConcurrentUpdate = errors.New("too many concurrent updates")
+
+// maxUpdateAttempts is the number of times an update of the policies under a
+// key is attempted, when it keeps conflicting with concurrent updates, before
+// giving up.
+var maxUpdateAttempts = 10
 
 // NewStore returns a new policy store. Config options are the same as those
 // used for kvstore.New().
@@ -40,28 +50,46 @@ func (o *Store) Setup() error {
 }
 
 // Add a policy with the specified ID and rules. If a policy with that ID
-// already exists, an error is returned.
-func (o *Store) Add(id PolicyKey, name, typ, rules string) (*Policy, error) {
-	if _, err := o.Get(id); err == nil {
-		return nil, fmt.Errorf("policy with id %q already exists", id)
+// already exists, an error is returned. user identifies the principal creating
+// the policy, and is recorded in the policy's audit fields.
+func (o *Store) Add(id PolicyKey, name, typ, rules, user string) (*Policy, error) {
+	newPolicy, err := NewPolicy(id, name, typ, rules, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.modify(id, func(policies []*Policy) ([]*Policy, error) {
+		if len(policies) != 0 {
+			return nil, fmt.Errorf("policy with id %q already exists", id)
+		}
+
+		return []*Policy{newPolicy}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return o.Update(id, name, typ, rules)
+	return newPolicy, nil
 }
 
 // Update sets the provided rules as the latest version of the policy with the
-// specified key. If a policy with that key does not exist, it is created.
-func (o *Store) Update(key PolicyKey, name, typ, rules string) (*Policy, error) {
-	newPolicy, err := NewPolicy(key, name, typ, rules)
+// specified key. If a policy with that key does not exist, it is created. user
+// identifies the principal creating the new version.
+func (o *Store) Update(key PolicyKey, name, typ, rules, user string) (*Policy, error) {
+	newPolicy, err := NewPolicy(key, name, typ, rules, user)
 	if err != nil {
 		return newPolicy, err
 	}
 
-	return newPolicy, o.addPolicy(newPolicy)
+	return newPolicy, o.modify(key, func(policies []*Policy) ([]*Policy, error) {
+		return append(policies, newPolicy), nil
+	})
 }
 
 // Get returns the slice of all Policies associated with the specified ID. Each
-// Policy represents a different version of the same logical policy.
+// Policy represents a different version of the same logical policy. Scheduled
+// activations that have become due are reflected in the returned Policies (see
+// ScheduleActivation).
 func (o *Store) Get(key PolicyKey) ([]*Policy, error) {
 	vals, err := o.KVStore.Get(key.String())
 	if err != nil {
@@ -71,19 +99,7 @@ func (o *Store) Get(key PolicyKey) ([]*Policy, error) {
 		return nil, err
 	}
 
-	var policies []*Policy // nolint:prealloc
-
-	for _, v := range vals {
-		var p Policy
-		if err = json.Unmarshal([]byte(v), &p); err != nil {
-			return nil, err
-		}
-
-		p.StoreKey = key
-		policies = append(policies, &p)
-	}
-
-	return policies, nil
+	return decodePolicies(key, vals)
 }
 
 // List returns []Policy containing latest versions of all policies. All
@@ -134,83 +150,168 @@ func (o *Store) ListAllVersions() ([]*Policy, error) {
 	return policies, nil
 }
 
+// ListVersionsPage returns all versions of a page of up to limit policies whose
+// store keys start with the specified prefix (e.g. "<tenant>:<scheme>:" for
+// the policies of a tenant for a scheme), in order of their keys, following
+// the specified cursor (or from the first policy, if it is empty). The
+// returned cursor may be passed to a subsequent call to retrieve the next
+// page; it is empty if there are no further policies. If limit is not
+// positive, all remaining policies are returned.
+func (o *Store) ListVersionsPage(prefix, cursor string, limit int) ([]*Policy, string, error) {
+	keys, next, err := o.KVStore.ScanKeys(prefix, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var policies []*Policy
+	for _, k := range keys {
+		key, ok, err := parsePolicyKey(k)
+		if err != nil {
+			return nil, "", err
+		} else if !ok {
+			continue
+		}
+
+		versions, err := o.Get(key)
+		if err != nil {
+			if errors.Is(err, ErrNoPolicy) {
+				// removed since the keys were scanned
+				continue
+			}
+
+			return nil, "", err
+		}
+
+		policies = append(policies, versions...)
+	}
+
+	return policies, next, nil
+}
+
 // GetPolicyKeys returns a []PolicyID of the policies currently in the store.
+// Keys of policy data (see PolicyData) and policy chains (see PolicyChain)
+// are not included.
 func (o *Store) GetPolicyKeys() ([]PolicyKey, error) {
 	keys, err := o.KVStore.GetKeys()
 	if err != nil {
 		return nil, err
 	}
 
-	ids := make([]PolicyKey, len(keys))
-	for i, k := range keys {
-		key, err := PolicyKeyFromString(k)
+	ids := make([]PolicyKey, 0, len(keys))
+	for _, k := range keys {
+		key, ok, err := parsePolicyKey(k)
 		if err != nil {
-			return nil, fmt.Errorf("bad key in store: %w", err)
+			return nil, err
+		} else if !ok {
+			continue
 		}
 
-		ids[i] = key
+		ids = append(ids, key)
 	}
 
 	return ids, nil
 }
 
-// Activate activates the policy version with the specified id for the
-// specified key.
-func (o *Store) Activate(key PolicyKey, id uuid.UUID) error {
-	policies, err := o.Get(key)
-	if err != nil {
-		return err
+// parsePolicyKey parses the specified store key. ok is false if the key is not
+// that of a policy (e.g. it is the key of policy data, or of a policy chain).
+func parsePolicyKey(k string) (key PolicyKey, ok bool, err error) {
+	if isPolicyDataKey(k) {
+		return PolicyKey{}, false, nil
 	}
 
-	activated := false
-	for _, pol := range policies {
-		if bytes.Equal(id[:], pol.UUID[:]) {
-			pol.Active = true
-			activated = true
-		} else {
-			pol.Active = false
-		}
+	key, err = PolicyKeyFromString(k)
+	if err != nil {
+		return PolicyKey{}, false, fmt.Errorf("bad key in store: %w", err)
 	}
 
-	if !activated {
-		return fmt.Errorf("%w with UUID %q for key %q", ErrNoPolicy, id, key.String())
+	if IsReservedName(key.Name) {
+		return PolicyKey{}, false, nil
 	}
 
-	if err := o.Del(key); err != nil {
-		return err
-	}
+	return key, true, nil
+}
 
-	for _, pol := range policies {
-		if err := o.addPolicy(pol); err != nil {
-			return err
+// Activate activates the policy version with the specified id for the
+// specified key. user identifies the principal performing the activation, and
+// is recorded, along with the activation time, in the activated policy. Any
+// pending activation of that version is cancelled.
+func (o *Store) Activate(key PolicyKey, id uuid.UUID, user string) error {
+	return o.modifyExisting(key, func(policies []*Policy) ([]*Policy, error) {
+		return policies, activate(key, policies, id, user)
+	})
+}
+
+// Rollback re-activates the policy version that was active for the specified
+// key before the current one, i.e. the inactive version with the most recent
+// activation time. The re-activated policy is returned.
+func (o *Store) Rollback(key PolicyKey, user string) (*Policy, error) {
+	var previous *Policy
+
+	err := o.modifyExisting(key, func(policies []*Policy) ([]*Policy, error) {
+		previous = nil
+		for _, pol := range policies {
+			if pol.Active || pol.ATime == nil {
+				continue
+			}
+
+			if previous == nil || pol.ATime.After(*previous.ATime) {
+				previous = pol
+			}
 		}
+
+		if previous == nil {
+			return nil, fmt.Errorf("%w %q", ErrNoPreviousPolicy, key.String())
+		}
+
+		return policies, activate(key, policies, previous.UUID, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return previous, nil
 }
 
-// DeactivateAll deactivates all policies associated with the key.
-func (o *Store) DeactivateAll(key PolicyKey) error {
+// History returns all versions of the policy with the specified key, ordered
+// by creation time, oldest first.
+func (o *Store) History(key PolicyKey) ([]*Policy, error) {
 	policies, err := o.Get(key)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, pol := range policies {
-		pol.Active = false
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policies[i].CTime.Before(policies[j].CTime)
+	})
+
+	return policies, nil
+}
+
+// Diff returns a unified diff of the rules of the policy versions with the
+// specified UUIDs under the specified key.
+func (o *Store) Diff(key PolicyKey, fromID, toID uuid.UUID) (string, error) {
+	from, err := o.GetPolicy(key, fromID)
+	if err != nil {
+		return "", err
 	}
 
-	if err := o.Del(key); err != nil {
-		return err
+	to, err := o.GetPolicy(key, toID)
+	if err != nil {
+		return "", err
 	}
 
-	for _, pol := range policies {
-		if err := o.addPolicy(pol); err != nil {
-			return err
+	return DiffPolicies(from, to)
+}
+
+// DeactivateAll deactivates all policies associated with the key.
+func (o *Store) DeactivateAll(key PolicyKey) error {
+	return o.modifyExisting(key, func(policies []*Policy) ([]*Policy, error) {
+		for _, pol := range policies {
+			pol.Active = false
 		}
-	}
 
-	return nil
+		return policies, nil
+	})
 }
 
 // GetActive returns the current active version of the policy with the
@@ -253,16 +354,162 @@ func (o *Store) Del(key PolicyKey) error {
 	return o.KVStore.Del(key.String())
 }
 
+// DelPolicy removes the policy version with the specified UUID from under the
+// specified key. The currently active version cannot be removed; it must be
+// deactivated (or another version activated) first.
+func (o *Store) DelPolicy(key PolicyKey, id uuid.UUID) error {
+	return o.modifyExisting(key, func(policies []*Policy) ([]*Policy, error) {
+		remaining := make([]*Policy, 0, len(policies))
+		found := false
+		for _, pol := range policies {
+			if bytes.Equal(id[:], pol.UUID[:]) {
+				if pol.Active {
+					return nil, fmt.Errorf("%w: cannot delete %q under key %q",
+						ErrPolicyActive, id.String(), key.String())
+				}
+				found = true
+				continue
+			}
+
+			remaining = append(remaining, pol)
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w with UUID %q under key %q",
+				ErrNoPolicy, id.String(), key.String())
+		}
+
+		// if there are no remaining versions, the key is removed
+		return remaining, nil
+	})
+}
+
+// DelTenant removes all policies, policy data and policy chains of the
+// specified tenant.
+func (o *Store) DelTenant(tenantID string) error {
+	keys, _, err := o.KVStore.ScanKeys(tenantID+":", "", 0)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := o.KVStore.Del(k); err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Close the connection to the underlying kvstore.
 func (o *Store) Close() error {
 	return o.KVStore.Close()
 }
 
-func (o *Store) addPolicy(policy *Policy) error {
-	policyBytes, err := json.Marshal(policy)
-	if err != nil {
-		return err
+// modify atomically replaces the policies under the specified key with those
+// returned by fn, which is passed the current policies (with due scheduled
+// activations applied; none, if the key is not in the store). If fn returns
+// no policies, the key is removed. If the policies are concurrently modified
+// by someone else, fn is applied again to the updated policies, so it must not
+// have side effects beyond modifying the policies it is passed.
+func (o *Store) modify(key PolicyKey, fn func([]*Policy) ([]*Policy, error)) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		vals, version, err := o.KVStore.GetVersioned(key.String())
+		if err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+			return err
+		}
+
+		policies, err := decodePolicies(key, vals)
+		if err != nil {
+			return err
+		}
+
+		policies, err = fn(policies)
+		if err != nil {
+			return err
+		}
+
+		newVals, err := encodePolicies(policies)
+		if err != nil {
+			return err
+		}
+
+		err = o.KVStore.CompareAndSwap(key.String(), version, newVals)
+		if !errors.Is(err, kvstore.ErrVersionMismatch) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w of %q", ErrConcurrentUpdate, key.String())
+}
+
+// modifyExisting is like modify, but returns an error wrapping ErrNoPolicy if
+// the key is not in the store.
+func (o *Store) modifyExisting(key PolicyKey, fn func([]*Policy) ([]*Policy, error)) error {
+	return o.modify(key, func(policies []*Policy) ([]*Policy, error) {
+		if len(policies) == 0 {
+			return nil, fmt.Errorf("%w: %q", ErrNoPolicy, key)
+		}
+
+		return fn(policies)
+	})
+}
+
+// activate activates the policy version with the specified id among policies
+// (all versions of the policy with the specified key), deactivating the
+// others.
+func activate(key PolicyKey, policies []*Policy, id uuid.UUID, user string) error {
+	activated := false
+	for _, pol := range policies {
+		if bytes.Equal(id[:], pol.UUID[:]) {
+			now := time.Now()
+			pol.Active = true
+			pol.ATime = &now
+			pol.ActivatedBy = user
+			pol.ScheduledActivation = nil
+			pol.ScheduledBy = ""
+			activated = true
+		} else {
+			pol.Active = false
+		}
+	}
+
+	if !activated {
+		return fmt.Errorf("%w with UUID %q for key %q", ErrNoPolicy, id, key.String())
+	}
+
+	return nil
+}
+
+func decodePolicies(key PolicyKey, vals []string) ([]*Policy, error) {
+	var policies []*Policy // nolint:prealloc
+
+	for _, v := range vals {
+		var p Policy
+		if err := json.Unmarshal([]byte(v), &p); err != nil {
+			return nil, err
+		}
+
+		p.StoreKey = key
+		policies = append(policies, &p)
+	}
+
+	applySchedule(policies, time.Now())
+
+	return policies, nil
+}
+
+func encodePolicies(policies []*Policy) ([]string, error) {
+	vals := make([]string, 0, len(policies))
+
+	for _, pol := range policies {
+		policyBytes, err := json.Marshal(pol)
+		if err != nil {
+			return nil, err
+		}
+
+		vals = append(vals, string(policyBytes))
 	}
 
-	return o.KVStore.Add(policy.StoreKey.String(), string(policyBytes))
+	return vals, nil
 }
@@ -1,10 +1,12 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package policy
 
 import (
+	"sync"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,31 +24,31 @@ func Test_Store_CRUD(t *testing.T) {
 	key := PolicyKey{"1", "scheme", "policy"}
 
 	policy, err := store.Add(key, "test", "test",
-		"1. the chief's always right; 2. if the chief's wrong, see 1.")
+		"1. the chief's always right; 2. if the chief's wrong, see 1.", "")
 	require.NoError(t, err)
 
 	_, err = store.GetActive(key)
 	assert.EqualError(t, err, "no active policy for key \"1:scheme:policy\"")
 
-	err = store.Activate(key, policy.UUID)
+	err = store.Activate(key, policy.UUID, "")
 	require.NoError(t, err)
 
 	policy, err = store.GetActive(key)
 	require.NoError(t, err)
 	assert.Equal(t, key, policy.StoreKey)
 
-	_, err = store.Add(key, "test", "test", "On second thought, chief's not always right.")
+	_, err = store.Add(key, "test", "test", "On second thought, chief's not always right.", "")
 	assert.ErrorContains(t, err, "already exists")
 
 	secondPolicy, err := store.Update(key, "test", "test",
-		"On second thought, chief's not always right.")
+		"On second thought, chief's not always right.", "")
 	require.NoError(t, err)
 
 	policy, err = store.GetActive(key)
 	require.NoError(t, err)
 	assert.Equal(t, "1. the chief's always right; 2. if the chief's wrong, see 1.", policy.Rules)
 
-	err = store.Activate(key, secondPolicy.UUID)
+	err = store.Activate(key, secondPolicy.UUID, "")
 	require.NoError(t, err)
 
 	policy, err = store.GetActive(key)
@@ -67,7 +69,7 @@ func Test_Store_CRUD(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, len(policies))
 
-	err = store.Activate(key, secondPolicy.UUID)
+	err = store.Activate(key, secondPolicy.UUID, "")
 	require.NoError(t, err)
 
 	policies, err = store.List()
@@ -87,3 +89,225 @@ func Test_Store_CRUD(t *testing.T) {
 	_, err = store.GetActive(key)
 	assert.ErrorIs(t, err, ErrNoPolicy)
 }
+
+func Test_Store_History_Rollback(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "policy"}
+
+	first, err := store.Add(key, "test", "test", "allow = true\n", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", first.CreatedBy)
+
+	second, err := store.Update(key, "test", "test", "allow = false\n", "bob")
+	require.NoError(t, err)
+
+	_, err = store.Rollback(key, "carol")
+	assert.ErrorIs(t, err, ErrNoPreviousPolicy)
+
+	require.NoError(t, store.Activate(key, first.UUID, "carol"))
+	require.NoError(t, store.Activate(key, second.UUID, "dave"))
+
+	active, err := store.GetActive(key)
+	require.NoError(t, err)
+	assert.Equal(t, second.UUID, active.UUID)
+	assert.Equal(t, "bob", active.CreatedBy)
+	assert.Equal(t, "dave", active.ActivatedBy)
+	assert.NotNil(t, active.ATime)
+
+	rolledBack, err := store.Rollback(key, "erin")
+	require.NoError(t, err)
+	assert.Equal(t, first.UUID, rolledBack.UUID)
+	assert.True(t, rolledBack.Active)
+	assert.Equal(t, "erin", rolledBack.ActivatedBy)
+
+	history, err := store.History(key)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, first.UUID, history[0].UUID)
+	assert.Equal(t, second.UUID, history[1].UUID)
+}
+
+func Test_Store_DelPolicy(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "policy"}
+
+	first, err := store.Add(key, "test", "test", "allow = true\n", "")
+	require.NoError(t, err)
+
+	second, err := store.Update(key, "test", "test", "allow = false\n", "")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Activate(key, first.UUID, ""))
+
+	err = store.DelPolicy(key, first.UUID)
+	assert.ErrorIs(t, err, ErrPolicyActive)
+
+	err = store.DelPolicy(key, uuid.New())
+	assert.ErrorIs(t, err, ErrNoPolicy)
+
+	require.NoError(t, store.DelPolicy(key, second.UUID))
+
+	versions, err := store.Get(key)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, first.UUID, versions[0].UUID)
+	assert.True(t, versions[0].Active)
+
+	require.NoError(t, store.DeactivateAll(key))
+	require.NoError(t, store.DelPolicy(key, first.UUID))
+
+	_, err = store.Get(key)
+	assert.ErrorIs(t, err, ErrNoPolicy)
+}
+
+func Test_Store_DelTenant(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Add(PolicyKey{"1", "scheme", "opa"}, "test", "test", "allow = true\n", "")
+	require.NoError(t, err)
+	_, err = store.SetData("1", "scheme", map[string]any{"allowed": []any{1}}, "")
+	require.NoError(t, err)
+	_, err = store.SetChain("1", "scheme", []string{"opa"}, "")
+	require.NoError(t, err)
+
+	other := PolicyKey{"10", "scheme", "opa"}
+	_, err = store.Add(other, "test", "test", "allow = true\n", "")
+	require.NoError(t, err)
+
+	require.NoError(t, store.DelTenant("1"))
+
+	keys, err := store.KVStore.GetKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.String()}, keys)
+}
+
+func Test_Store_ListVersionsPage(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, name := range []string{"c", "a", "b"} {
+		key := PolicyKey{"1", "scheme", name}
+		_, err = store.Add(key, "test", "test", "allow = true\n", "")
+		require.NoError(t, err)
+		_, err = store.Update(key, "test", "test", "allow = false\n", "")
+		require.NoError(t, err)
+	}
+
+	_, err = store.Add(PolicyKey{"1", "other", "a"}, "test", "test", "allow = true\n", "")
+	require.NoError(t, err)
+	_, err = store.SetChain("1", "scheme", []string{"a", "b"}, "")
+	require.NoError(t, err)
+
+	// the policy chain, and the policy for the other scheme, are not
+	// included
+	var names []string
+	cursor := ""
+	for {
+		policies, next, err := store.ListVersionsPage("1:scheme:", cursor, 2)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(policies), 4)
+
+		for _, pol := range policies {
+			names = append(names, pol.StoreKey.Name)
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, []string{"a", "a", "b", "b", "c", "c"}, names)
+}
+
+func Test_Store_Diff(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "policy"}
+
+	first, err := store.Add(key, "test", "test", "package policy\n\nallow = true\n", "")
+	require.NoError(t, err)
+
+	second, err := store.Update(key, "test", "test", "package policy\n\nallow = false\n", "")
+	require.NoError(t, err)
+
+	diff, err := store.Diff(key, first.UUID, second.UUID)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- "+first.UUID.String())
+	assert.Contains(t, diff, "+++ "+second.UUID.String())
+	assert.Contains(t, diff, "-allow = true\n")
+	assert.Contains(t, diff, "+allow = false\n")
+
+	diff, err = store.Diff(key, first.UUID, first.UUID)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func Test_Store_Activate_concurrent(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	key := PolicyKey{"1", "scheme", "policy"}
+
+	var ids []uuid.UUID
+	for i := 0; i < 8; i++ {
+		pol, err := store.Update(key, "test", "test", "rules", "")
+		require.NoError(t, err)
+		ids = append(ids, pol.UUID)
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			// with enough contention, some activations may give up
+			err := store.Activate(key, id, "")
+			if err != nil {
+				assert.ErrorIs(t, err, ErrConcurrentUpdate)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	policies, err := store.Get(key)
+	require.NoError(t, err)
+	require.Len(t, policies, len(ids))
+
+	numActive := 0
+	for _, pol := range policies {
+		if pol.Active {
+			numActive++
+		}
+	}
+	assert.Equal(t, 1, numActive)
+}
@@ -0,0 +1,7 @@
+package lib.psa
+
+bl_version_allowed(evidence) if {
+	some component in evidence["psa-software-components"]
+	component["measurement-type"] == "BL"
+	component.version in data.allowlist.bl_versions
+}
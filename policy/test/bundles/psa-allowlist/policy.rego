@@ -0,0 +1,7 @@
+package policy
+
+import data.lib.psa
+
+executables := APPROVED_RT if {
+	psa.bl_version_allowed(evidence)
+} else := CONTRAINDICATED_RT
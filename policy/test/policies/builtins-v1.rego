@@ -0,0 +1,16 @@
+package policy
+
+import rego.v1
+
+bl := [c | some c in evidence["psa-software-components"]; c["measurement-type"] == "BL"][0]
+
+executables := APPROVED_RT if {
+	some e in endorsements
+	some m in e.measurements
+	veraison.measurement_match(m.value, {"digests": [["sha-256", bl["measurement-value"]]]})
+	veraison.version_cmp(bl.version, "3.4.0") >= 0
+} else := CONTRAINDICATED_RT
+
+status := AFFIRMING if {
+	veraison.trust_claim(executables).tier == "affirming"
+}
@@ -0,0 +1,9 @@
+package policy
+
+executables = APPROVED_RT {
+	some i
+	evidence["psa-software-components"][i]["measurement-type"] == "BL"
+	evidence["psa-software-components"][i].version == data.allowlist.bl_versions[_]
+} else = CONTRAINDICATED_RT {
+	true
+}
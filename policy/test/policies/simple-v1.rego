@@ -0,0 +1,6 @@
+package policy
+
+executables := APPROVED_RT if {
+	some component in evidence["psa-software-components"]
+	component["measurement-type"] == "BL"
+}
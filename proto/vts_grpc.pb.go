@@ -34,6 +34,17 @@ type VTSClient interface {
 	GetSupportedCoservMediaTypes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MediaTypeList, error)
 	// Returns the public key used to sign CoSERV results
 	GetCoservSigningPublicKey(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PublicKey, error)
+	// Returns the attestation schemes handling the supported provisioning
+	// media types.
+	GetProvisioningMediaTypeSchemes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MediaTypeSchemes, error)
+	// Returns attestation information for the provided attestation token,
+	// streamed in chunks. This is intended for large tokens (e.g. those
+	// including TPM event logs) that may exceed the maximum message size.
+	GetAttestationStream(ctx context.Context, opts ...grpc.CallOption) (VTS_GetAttestationStreamClient, error)
+	// Submits endorsements streamed in chunks. This is intended for large
+	// submissions (e.g. CoRIM bundles) that may exceed the maximum message
+	// size.
+	SubmitEndorsementsStream(ctx context.Context, opts ...grpc.CallOption) (VTS_SubmitEndorsementsStreamClient, error)
 }
 
 type vTSClient struct {
@@ -125,6 +136,83 @@ func (c *vTSClient) GetCoservSigningPublicKey(ctx context.Context, in *emptypb.E
 	return out, nil
 }
 
+func (c *vTSClient) GetProvisioningMediaTypeSchemes(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MediaTypeSchemes, error) {
+	out := new(MediaTypeSchemes)
+	err := c.cc.Invoke(ctx, "/proto.VTS/GetProvisioningMediaTypeSchemes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vTSClient) GetAttestationStream(ctx context.Context, opts ...grpc.CallOption) (VTS_GetAttestationStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VTS_ServiceDesc.Streams[0], "/proto.VTS/GetAttestationStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vTSGetAttestationStreamClient{stream}
+	return x, nil
+}
+
+type VTS_GetAttestationStreamClient interface {
+	Send(*AttestationTokenChunk) error
+	CloseAndRecv() (*AppraisalContext, error)
+	grpc.ClientStream
+}
+
+type vTSGetAttestationStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *vTSGetAttestationStreamClient) Send(m *AttestationTokenChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *vTSGetAttestationStreamClient) CloseAndRecv() (*AppraisalContext, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AppraisalContext)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *vTSClient) SubmitEndorsementsStream(ctx context.Context, opts ...grpc.CallOption) (VTS_SubmitEndorsementsStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VTS_ServiceDesc.Streams[1], "/proto.VTS/SubmitEndorsementsStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vTSSubmitEndorsementsStreamClient{stream}
+	return x, nil
+}
+
+type VTS_SubmitEndorsementsStreamClient interface {
+	Send(*SubmitEndorsementsChunk) error
+	CloseAndRecv() (*SubmitEndorsementsResponse, error)
+	grpc.ClientStream
+}
+
+type vTSSubmitEndorsementsStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *vTSSubmitEndorsementsStreamClient) Send(m *SubmitEndorsementsChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *vTSSubmitEndorsementsStreamClient) CloseAndRecv() (*SubmitEndorsementsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SubmitEndorsementsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // VTSServer is the server API for VTS service.
 // All implementations must embed UnimplementedVTSServer
 // for forward compatibility
@@ -144,6 +232,17 @@ type VTSServer interface {
 	GetSupportedCoservMediaTypes(context.Context, *emptypb.Empty) (*MediaTypeList, error)
 	// Returns the public key used to sign CoSERV results
 	GetCoservSigningPublicKey(context.Context, *emptypb.Empty) (*PublicKey, error)
+	// Returns the attestation schemes handling the supported provisioning
+	// media types.
+	GetProvisioningMediaTypeSchemes(context.Context, *emptypb.Empty) (*MediaTypeSchemes, error)
+	// Returns attestation information for the provided attestation token,
+	// streamed in chunks. This is intended for large tokens (e.g. those
+	// including TPM event logs) that may exceed the maximum message size.
+	GetAttestationStream(VTS_GetAttestationStreamServer) error
+	// Submits endorsements streamed in chunks. This is intended for large
+	// submissions (e.g. CoRIM bundles) that may exceed the maximum message
+	// size.
+	SubmitEndorsementsStream(VTS_SubmitEndorsementsStreamServer) error
 	mustEmbedUnimplementedVTSServer()
 }
 
@@ -178,6 +277,15 @@ func (UnimplementedVTSServer) GetSupportedCoservMediaTypes(context.Context, *emp
 func (UnimplementedVTSServer) GetCoservSigningPublicKey(context.Context, *emptypb.Empty) (*PublicKey, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCoservSigningPublicKey not implemented")
 }
+func (UnimplementedVTSServer) GetProvisioningMediaTypeSchemes(context.Context, *emptypb.Empty) (*MediaTypeSchemes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProvisioningMediaTypeSchemes not implemented")
+}
+func (UnimplementedVTSServer) GetAttestationStream(VTS_GetAttestationStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetAttestationStream not implemented")
+}
+func (UnimplementedVTSServer) SubmitEndorsementsStream(VTS_SubmitEndorsementsStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitEndorsementsStream not implemented")
+}
 func (UnimplementedVTSServer) mustEmbedUnimplementedVTSServer() {}
 
 // UnsafeVTSServer may be embedded to opt out of forward compatibility for this service.
@@ -353,6 +461,76 @@ func _VTS_GetCoservSigningPublicKey_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VTS_GetProvisioningMediaTypeSchemes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VTSServer).GetProvisioningMediaTypeSchemes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.VTS/GetProvisioningMediaTypeSchemes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VTSServer).GetProvisioningMediaTypeSchemes(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VTS_GetAttestationStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VTSServer).GetAttestationStream(&vTSGetAttestationStreamServer{stream})
+}
+
+type VTS_GetAttestationStreamServer interface {
+	SendAndClose(*AppraisalContext) error
+	Recv() (*AttestationTokenChunk, error)
+	grpc.ServerStream
+}
+
+type vTSGetAttestationStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *vTSGetAttestationStreamServer) SendAndClose(m *AppraisalContext) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *vTSGetAttestationStreamServer) Recv() (*AttestationTokenChunk, error) {
+	m := new(AttestationTokenChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _VTS_SubmitEndorsementsStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VTSServer).SubmitEndorsementsStream(&vTSSubmitEndorsementsStreamServer{stream})
+}
+
+type VTS_SubmitEndorsementsStreamServer interface {
+	SendAndClose(*SubmitEndorsementsResponse) error
+	Recv() (*SubmitEndorsementsChunk, error)
+	grpc.ServerStream
+}
+
+type vTSSubmitEndorsementsStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *vTSSubmitEndorsementsStreamServer) SendAndClose(m *SubmitEndorsementsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *vTSSubmitEndorsementsStreamServer) Recv() (*SubmitEndorsementsChunk, error) {
+	m := new(SubmitEndorsementsChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // VTS_ServiceDesc is the grpc.ServiceDesc for VTS service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -396,7 +574,22 @@ var VTS_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCoservSigningPublicKey",
 			Handler:    _VTS_GetCoservSigningPublicKey_Handler,
 		},
+		{
+			MethodName: "GetProvisioningMediaTypeSchemes",
+			Handler:    _VTS_GetProvisioningMediaTypeSchemes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetAttestationStream",
+			Handler:       _VTS_GetAttestationStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SubmitEndorsementsStream",
+			Handler:       _VTS_SubmitEndorsementsStream_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "vts.proto",
 }
@@ -0,0 +1,304 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package provenance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/ear"
+	"github.com/veraison/services/handler"
+	"github.com/veraison/services/kvstore"
+	"go.uber.org/zap"
+)
+
+var ErrNoRecord = errors.New("no provenance record found")
+
+// maxUpdateAttempts is the number of times the Record of an endorsement is
+// attempted to be replaced while it is being concurrently updated.
+const maxUpdateAttempts = 10
+
+// Record describes the submission of a CoRIM to the VTS.
+type Record struct {
+	// TagID is the corim-id of the CoRIM.
+	TagID string `json:"tag-id"`
+	// Submitter identifies the principal that submitted the CoRIM, if
+	// known.
+	Submitter string `json:"submitter,omitempty"`
+	// SubmittedAt is the time the CoRIM was added to the endorsement store.
+	SubmittedAt time.Time `json:"submitted-at"`
+	// Signer is the subject of the certificate the CoRIM was signed with,
+	// if it was signed.
+	Signer string `json:"signer,omitempty"`
+}
+
+// NewStore returns a new provenance store. Config options are the same as
+// those used for kvstore.New().
+func NewStore(v *viper.Viper, logger *zap.SugaredLogger) (*Store, error) {
+	kvStore, err := kvstore.New(v, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{KVStore: kvStore, Logger: logger}, nil
+}
+
+// Store keeps the provenance of the endorsements in the endorsement store. A
+// Record is kept for every measurement of every reference value and endorsed
+// value triple, and for every key of every key triple submitted, under a key
+// consisting of the label the triple was stored with and the measurement's or
+// key's ID (see handler.EndorsementIDs and handler.VerificationKeyIDs),
+// delimited by a colon.
+type Store struct {
+	KVStore kvstore.IKVStore
+	Logger  *zap.SugaredLogger
+}
+
+// Setup the underyling kvstore. This is a one-time setup that only needs to be
+// performed once for a deployment.
+func (o *Store) Setup() error {
+	return o.KVStore.Setup()
+}
+
+// Add records the provenance of the reference values, endorsed values and
+// verification keys inside the CoRIM, which has been added to the endorsement
+// store with the specified label. Only the Record of the most recent
+// submission of each of them is kept.
+func (o *Store) Add(label string, uc *corim.UnsignedCorim, rec Record) error {
+	recBytes, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	for i, tag := range uc.Tags {
+		if tag.Number != corim.ComidTag {
+			continue
+		}
+
+		var c comid.Comid
+		if err := c.FromCBOR(tag.Content); err != nil {
+			return fmt.Errorf("decoding failed for CoMID at index %d: %w", i, err)
+		}
+
+		var ids []string
+
+		for _, triples := range []*comid.ValueTriples{
+			c.Triples.ReferenceValues,
+			c.Triples.EndorsedValues,
+		} {
+			if triples == nil {
+				continue
+			}
+
+			for j := range triples.Values {
+				tripleIDs, err := handler.EndorsementIDs(&triples.Values[j])
+				if err != nil {
+					return fmt.Errorf("CoMID at index %d: triple %d: %w", i, j, err)
+				}
+
+				ids = append(ids, tripleIDs...)
+			}
+		}
+
+		for _, triples := range []*comid.KeyTriples{
+			c.Triples.AttestVerifKeys,
+			c.Triples.DevIdentityKeys,
+		} {
+			if triples == nil {
+				continue
+			}
+
+			for j := range *triples {
+				tripleIDs, err := handler.VerificationKeyIDs(&(*triples)[j])
+				if err != nil {
+					return fmt.Errorf("CoMID at index %d: key triple %d: %w", i, j, err)
+				}
+
+				ids = append(ids, tripleIDs...)
+			}
+		}
+
+		for _, id := range ids {
+			if err := o.put(storeKey(label, id), rec, string(recBytes)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// put replaces the Record under the key with rec (whose JSON encoding is
+// recBytes), unless the Record already there is of a later submission. This
+// uses CompareAndSwap, so that concurrent submissions cannot replace the
+// Record of a later one.
+func (o *Store) put(key string, rec Record, recBytes string) error {
+	for i := 0; i < maxUpdateAttempts; i++ {
+		vals, version, err := o.KVStore.GetVersioned(key)
+		if err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+			return err
+		}
+
+		if len(vals) > 0 {
+			latest, err := latestRecord(key, vals)
+			if err != nil {
+				return err
+			}
+
+			if latest.SubmittedAt.After(rec.SubmittedAt) {
+				return nil
+			}
+		}
+
+		err = o.KVStore.CompareAndSwap(key, version, []string{recBytes})
+		if !errors.Is(err, kvstore.ErrVersionMismatch) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("could not record provenance for %q due to concurrent updates", key)
+}
+
+// Get returns the Record of the most recent submission of the endorsement
+// with the specified ID, or an error wrapping ErrNoRecord if there is none.
+func (o *Store) Get(label, id string) (*Record, error) {
+	key := storeKey(label, id)
+
+	vals, err := o.KVStore.Get(key)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrNoRecord, key)
+		}
+		return nil, err
+	}
+
+	return latestRecord(key, vals)
+}
+
+// latestRecord returns the Record with the latest SubmittedAt among the
+// values of the key. There is normally only one, however, earlier versions
+// kept a Record for every submission.
+func latestRecord(key string, vals []string) (*Record, error) {
+	var latest *Record
+
+	for _, val := range vals {
+		var rec Record
+		if err := json.Unmarshal([]byte(val), &rec); err != nil {
+			return nil, fmt.Errorf("bad provenance record for %q: %w", key, err)
+		}
+
+		if latest == nil || !rec.SubmittedAt.Before(latest.SubmittedAt) {
+			latest = &rec
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoRecord, key)
+	}
+
+	return latest, nil
+}
+
+// Annotate replaces the endorsements recorded in the submods of the result by
+// handler.AnnotateEndorsements with the Records of the CoRIMs that supplied
+// them. Endorsements without a Record are omitted, as are duplicate Records.
+func (o *Store) Annotate(label string, result *ear.AttestationResult) error {
+	for name, submod := range result.Submods {
+		ids, ok := endorsementIDs(submod)
+		if !ok {
+			continue
+		}
+
+		recs := []Record{}
+		seen := make(map[Record]bool)
+
+		for _, id := range ids {
+			rec, err := o.Get(label, id)
+			if err != nil {
+				if errors.Is(err, ErrNoRecord) {
+					o.Logger.Debugw("no provenance record", "submod", name, "id", id)
+					continue
+				}
+				return err
+			}
+
+			if !seen[*rec] {
+				seen[*rec] = true
+				recs = append(recs, *rec)
+			}
+		}
+
+		(*submod.VeraisonAnnotatedEvidence)[handler.EndorsementProvenanceClaim] = recs
+	}
+
+	return nil
+}
+
+// DelLabel removes the Records of all of the endorsements stored under the
+// specified label.
+func (o *Store) DelLabel(label string) error {
+	keys, _, err := o.KVStore.ScanKeys(label+":", "", 0)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := o.KVStore.Del(k); err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close the connection to the underlying kvstore.
+func (o *Store) Close() error {
+	return o.KVStore.Close()
+}
+
+// Strip removes the endorsements recorded by handler.AnnotateEndorsements from
+// the submods of the result. This is used in place of Store.Annotate when
+// provenance is not being kept.
+func Strip(result *ear.AttestationResult) {
+	for _, submod := range result.Submods {
+		if _, ok := endorsementIDs(submod); ok {
+			delete(*submod.VeraisonAnnotatedEvidence, handler.EndorsementProvenanceClaim)
+		}
+	}
+}
+
+func endorsementIDs(submod *ear.Appraisal) ([]string, bool) {
+	if submod == nil || submod.VeraisonAnnotatedEvidence == nil {
+		return nil, false
+	}
+
+	val, ok := (*submod.VeraisonAnnotatedEvidence)[handler.EndorsementProvenanceClaim]
+	if !ok {
+		return nil, false
+	}
+
+	// the claim may have been through a JSON round-trip (e.g. when
+	// returned by a scheme plugin), so its elements are not necessarily
+	// strings
+	var ids []string
+
+	switch t := val.(type) {
+	case []string:
+		ids = t
+	case []interface{}:
+		for _, id := range t {
+			ids = append(ids, fmt.Sprint(id))
+		}
+	}
+
+	return ids, true
+}
+
+func storeKey(label, id string) string {
+	return fmt.Sprintf("%s:%s", label, id)
+}
@@ -0,0 +1,241 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package provenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/corim/corim"
+	"github.com/veraison/ear"
+	"github.com/veraison/services/handler"
+	"github.com/veraison/services/log"
+	"github.com/veraison/swid"
+)
+
+const testLabel = "0/PSA_IOT"
+
+func newTestStore(t *testing.T) *Store {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+
+	return store
+}
+
+func newTestValueTriple(name string, digest byte) *comid.ValueTriple {
+	vendor, model := "ACME", "RoadRunner"
+	triple := comid.ValueTriple{
+		Environment: comid.Environment{
+			Class: &comid.Class{Vendor: &vendor, Model: &model},
+		},
+	}
+
+	m := comid.Measurement{}
+	m.Val.Name = &name
+	m.AddDigest(int(swid.Sha256), append([]byte{digest}, make([]byte, 31)...))
+	triple.Measurements.Add(&m)
+
+	return &triple
+}
+
+func newTestCorim(t *testing.T, tagID string, triples ...*comid.ValueTriple) *corim.UnsignedCorim {
+	c := comid.Comid{}
+	c.SetTagIdentity("43BBE37F-2E61-4B33-AED3-53CFF1428B16", 0)
+	for _, triple := range triples {
+		c.AddReferenceValue(triple)
+	}
+	require.NoError(t, c.Valid())
+
+	uc := corim.NewUnsignedCorim().SetID(tagID).AddComid(&c)
+	require.NotNil(t, uc)
+
+	return uc
+}
+
+func idOf(t *testing.T, triple *comid.ValueTriple) string {
+	ids, err := handler.EndorsementIDs(triple)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	return ids[0]
+}
+
+func TestStore_Add_Get(t *testing.T) {
+	store := newTestStore(t)
+
+	bl, prot := newTestValueTriple("BL", 1), newTestValueTriple("PRoT", 2)
+
+	first := Record{
+		TagID:       "corim-1",
+		Submitter:   "alice",
+		SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Signer:      "CN=ACME Signer",
+	}
+	require.NoError(t, store.Add(testLabel, newTestCorim(t, "corim-1", bl, prot), first))
+
+	second := Record{
+		TagID:       "corim-2",
+		Submitter:   "bob",
+		SubmittedAt: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Add(testLabel, newTestCorim(t, "corim-2", bl), second))
+
+	rec, err := store.Get(testLabel, idOf(t, bl))
+	require.NoError(t, err)
+	assert.Equal(t, second, *rec)
+
+	rec, err = store.Get(testLabel, idOf(t, prot))
+	require.NoError(t, err)
+	assert.Equal(t, first, *rec)
+
+	_, err = store.Get("0/ARM_CCA", idOf(t, prot))
+	assert.ErrorIs(t, err, ErrNoRecord)
+}
+
+func TestStore_Add_out_of_order(t *testing.T) {
+	store := newTestStore(t)
+
+	bl := newTestValueTriple("BL", 1)
+
+	later := Record{TagID: "corim-2", SubmittedAt: time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.Add(testLabel, newTestCorim(t, "corim-2", bl), later))
+
+	earlier := Record{TagID: "corim-1", SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.Add(testLabel, newTestCorim(t, "corim-1", bl), earlier))
+
+	rec, err := store.Get(testLabel, idOf(t, bl))
+	require.NoError(t, err)
+	assert.Equal(t, later, *rec)
+
+	// the record is replaced, rather than added to
+	latest := Record{TagID: "corim-3", SubmittedAt: time.Date(2026, 10, 3, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.Add(testLabel, newTestCorim(t, "corim-3", bl), latest))
+
+	vals, err := store.KVStore.Get(storeKey(testLabel, idOf(t, bl)))
+	require.NoError(t, err)
+	assert.Len(t, vals, 1)
+
+	rec, err = store.Get(testLabel, idOf(t, bl))
+	require.NoError(t, err)
+	assert.Equal(t, latest, *rec)
+}
+
+func TestStore_Get_multiple_records(t *testing.T) {
+	store := newTestStore(t)
+
+	key := storeKey(testLabel, "id")
+
+	// earlier versions kept a record for every submission, in the order
+	// they were added
+	for _, val := range []string{
+		`{"tag-id": "corim-2", "submitted-at": "2026-10-02T12:00:00Z"}`,
+		`{"tag-id": "corim-3", "submitted-at": "2026-10-03T12:00:00Z"}`,
+		`{"tag-id": "corim-1", "submitted-at": "2026-10-01T12:00:00Z"}`,
+	} {
+		require.NoError(t, store.KVStore.Add(key, val))
+	}
+
+	rec, err := store.Get(testLabel, "id")
+	require.NoError(t, err)
+	assert.Equal(t, "corim-3", rec.TagID)
+
+	require.NoError(t, store.KVStore.Add(key, `"bad"`))
+
+	_, err = store.Get(testLabel, "id")
+	assert.ErrorContains(t, err, "bad provenance record")
+}
+
+func TestStore_Add_key_triples(t *testing.T) {
+	store := newTestStore(t)
+
+	key, err := comid.NewPKIXBase64Key(comid.TestECPubKey)
+	require.NoError(t, err)
+
+	triple := &comid.KeyTriple{
+		Environment: newTestValueTriple("BL", 1).Environment,
+		VerifKeys:   comid.CryptoKeys{key},
+	}
+
+	c := comid.Comid{}
+	c.SetTagIdentity("43BBE37F-2E61-4B33-AED3-53CFF1428B16", 0)
+	c.AddAttestVerifKey(triple)
+	require.NoError(t, c.Valid())
+
+	uc := corim.NewUnsignedCorim().SetID("corim-1").AddComid(&c)
+	require.NotNil(t, uc)
+
+	rec := Record{TagID: "corim-1", SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, store.Add(testLabel, uc, rec))
+
+	ids, err := handler.VerificationKeyIDs(triple)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	ret, err := store.Get(testLabel, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, rec, *ret)
+}
+
+func TestStore_Annotate(t *testing.T) {
+	store := newTestStore(t)
+
+	bl, prot := newTestValueTriple("BL", 1), newTestValueTriple("PRoT", 2)
+	unknown := newTestValueTriple("unknown", 3)
+
+	rec := Record{
+		TagID:       "corim-1",
+		Submitter:   "alice",
+		SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Add(testLabel, newTestCorim(t, "corim-1", bl, prot), rec))
+
+	result := ear.NewAttestationResult("PSA_IOT", "test", "test")
+	appraisal := result.Submods["PSA_IOT"]
+	claims := map[string]interface{}{"psa-nonce": "AAAA"}
+	appraisal.VeraisonAnnotatedEvidence = &claims
+	require.NoError(t, handler.AnnotateEndorsements(appraisal, bl, prot, unknown))
+
+	require.NoError(t, store.Annotate(testLabel, result))
+
+	annotated := *appraisal.VeraisonAnnotatedEvidence
+	assert.Equal(t, "AAAA", annotated["psa-nonce"])
+	assert.Equal(t, []Record{rec}, annotated[handler.EndorsementProvenanceClaim])
+}
+
+func TestStore_DelLabel(t *testing.T) {
+	store := newTestStore(t)
+
+	bl := newTestValueTriple("BL", 1)
+	rec := Record{TagID: "corim-1", SubmittedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+
+	for _, label := range []string{"acme/PSA_IOT", "acme/PSA_IOT_V2", testLabel} {
+		require.NoError(t, store.Add(label, newTestCorim(t, "corim-1", bl), rec))
+	}
+
+	require.NoError(t, store.DelLabel("acme/PSA_IOT"))
+
+	_, err := store.Get("acme/PSA_IOT", idOf(t, bl))
+	assert.ErrorIs(t, err, ErrNoRecord)
+
+	for _, label := range []string{"acme/PSA_IOT_V2", testLabel} {
+		_, err = store.Get(label, idOf(t, bl))
+		assert.NoError(t, err, label)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	result := ear.NewAttestationResult("PSA_IOT", "test", "test")
+	appraisal := result.Submods["PSA_IOT"]
+	require.NoError(t, handler.AnnotateEndorsements(appraisal, newTestValueTriple("BL", 1)))
+
+	Strip(result)
+
+	assert.Empty(t, *appraisal.VeraisonAnnotatedEvidence)
+}
@@ -5,20 +5,28 @@ package api
 import (
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/veraison/services/auth"
+	"github.com/veraison/services/bodylimit"
 	"github.com/veraison/services/capability"
 	"github.com/veraison/services/provisioning/provisioner"
+	"github.com/veraison/services/tenant"
 	"go.uber.org/zap"
 )
 
 var (
-	tenantID           = "0"
+	// defaultTenantID is the tenant requests are for if the principal
+	// does not belong to one (see getTenantID).
+	defaultTenantID    = "0"
 	defaultCacheMaxAge = 60 * time.Second
+
+	// DefaultMaxBodySize is the maximum size of submissions, unless
+	// configured otherwise.
+	DefaultMaxBodySize int64 = 16 << 20
 )
 
 type IHandler interface {
@@ -28,18 +36,27 @@ type IHandler interface {
 
 type Handler struct {
 	Provisioner provisioner.IProvisioner
+	BodyLimits  *bodylimit.Limits
 
 	WkCacheMaxAge time.Duration
 	logger        *zap.SugaredLogger
 }
 
+// NewHandler returns a new handler for the provisioning API. If bodyLimits is
+// nil, submissions are limited to DefaultMaxBodySize.
 func NewHandler(
 	p provisioner.IProvisioner,
 	logger *zap.SugaredLogger,
 	wkCacheMaxAge string,
+	bodyLimits *bodylimit.Limits,
 ) IHandler {
+	if bodyLimits == nil {
+		bodyLimits = bodylimit.New(DefaultMaxBodySize)
+	}
+
 	return &Handler{
 		Provisioner:   p,
+		BodyLimits:    bodyLimits,
 		logger:        logger,
 		WkCacheMaxAge: capability.ParseCacheMaxAge(wkCacheMaxAge, defaultCacheMaxAge, logger),
 	}
@@ -100,8 +117,41 @@ func (o *Handler) Submit(c *gin.Context) {
 		return
 	}
 
+	scheme, err := o.Provisioner.GetSchemeForMediaType(mediaType)
+	if err != nil {
+		ReportProblem(c,
+			http.StatusInternalServerError,
+			fmt.Sprintf("could not get scheme from provisioner: %v", err),
+		)
+		return
+	}
+
+	resource := auth.Resource{Scheme: scheme, Tenant: getTenantID(c)}
+	if !auth.IsPermitted(c, auth.ProvisionEndorsementsAction, resource) {
+		ReportProblem(c,
+			http.StatusForbidden,
+			fmt.Sprintf("not permitted to provision endorsements for %s", scheme),
+		)
+		return
+	}
+
+	if !tenant.IsSchemeEnabled(c, scheme) {
+		ReportProblem(c,
+			http.StatusForbidden,
+			fmt.Sprintf("scheme %s is not enabled for the tenant", scheme),
+		)
+		return
+	}
+
 	// read body
-	payload, err := io.ReadAll(c.Request.Body)
+	payload, err := o.BodyLimits.ReadBody(c.Writer, c.Request, mediaType)
+	if errors.Is(err, bodylimit.ErrTooLarge) {
+		ReportProblem(c,
+			http.StatusRequestEntityTooLarge,
+			err.Error(),
+		)
+		return
+	}
 	if err != nil {
 		ReportProblem(c,
 			http.StatusBadRequest,
@@ -118,7 +168,7 @@ func (o *Handler) Submit(c *gin.Context) {
 		return
 	}
 
-	err = o.Provisioner.SubmitEndorsements(tenantID, payload, mediaType)
+	err = o.Provisioner.SubmitEndorsements(getTenantID(c), auth.GetPrincipal(c), payload, mediaType)
 	if err != nil {
 		o.logger.Errorw("submit endorsement failed", "error", err)
 
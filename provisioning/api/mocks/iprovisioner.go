@@ -34,6 +34,21 @@ func (m *MockIProvisioner) EXPECT() *MockIProvisionerMockRecorder {
 	return m.recorder
 }
 
+// GetSchemeForMediaType mocks base method.
+func (m *MockIProvisioner) GetSchemeForMediaType(mt string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemeForMediaType", mt)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemeForMediaType indicates an expected call of GetSchemeForMediaType.
+func (mr *MockIProvisionerMockRecorder) GetSchemeForMediaType(mt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemeForMediaType", reflect.TypeOf((*MockIProvisioner)(nil).GetSchemeForMediaType), mt)
+}
+
 // GetVTSState mocks base method.
 func (m *MockIProvisioner) GetVTSState() (*proto.ServiceState, error) {
 	m.ctrl.T.Helper()
@@ -65,17 +80,17 @@ func (mr *MockIProvisionerMockRecorder) IsSupportedMediaType(mt interface{}) *go
 }
 
 // SubmitEndorsements mocks base method.
-func (m *MockIProvisioner) SubmitEndorsements(tenantID string, data []byte, mt string) error {
+func (m *MockIProvisioner) SubmitEndorsements(tenantID, submitter string, data []byte, mt string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SubmitEndorsements", tenantID, data, mt)
+	ret := m.ctrl.Call(m, "SubmitEndorsements", tenantID, submitter, data, mt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SubmitEndorsements indicates an expected call of SubmitEndorsements.
-func (mr *MockIProvisionerMockRecorder) SubmitEndorsements(tenantID, data, mt interface{}) *gomock.Call {
+func (mr *MockIProvisionerMockRecorder) SubmitEndorsements(tenantID, submitter, data, mt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitEndorsements", reflect.TypeOf((*MockIProvisioner)(nil).SubmitEndorsements), tenantID, data, mt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitEndorsements", reflect.TypeOf((*MockIProvisioner)(nil).SubmitEndorsements), tenantID, submitter, data, mt)
 }
 
 // SupportedMediaTypes mocks base method.
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/veraison/services/auth"
+	"github.com/veraison/services/ratelimit"
+	"github.com/veraison/services/tenant"
 )
 
 var publicApiMap = make(map[string]string)
@@ -16,7 +18,16 @@ const (
 	getWellKnownProvisioningInfoPath = "/.well-known/veraison/provisioning"
 )
 
-func NewRouter(handler IHandler, authorizer auth.IAuthorizer) *gin.Engine {
+// NewRouter returns the router for the provisioning API. If tenants is not nil,
+// submissions are only accepted for registered tenants that have not been
+// suspended. If limiter is not nil, submissions are subject to its rate limits
+// and quotas.
+func NewRouter(
+	handler IHandler,
+	authorizer auth.IAuthorizer,
+	tenants *tenant.Checker,
+	limiter *ratelimit.Limiter,
+) *gin.Engine {
 	router := gin.New()
 
 	router.Use(gin.Logger())
@@ -25,10 +36,31 @@ func NewRouter(handler IHandler, authorizer auth.IAuthorizer) *gin.Engine {
 	router.GET(getWellKnownProvisioningInfoPath, handler.GetWellKnownProvisioningInfo)
 
 	provGroup := router.Group(provisioningPath)
-	provGroup.Use(authorizer.GetGinHandler(auth.ProvisionerRole))
+	// The scheme endorsements are provisioned for is only known once the
+	// submission's media type has been processed, so permission for the
+	// specific scheme is checked by the handler.
+	provGroup.Use(authorizer.GetPermissionHandler(auth.ProvisionEndorsementsAction, nil))
+	if tenants != nil {
+		provGroup.Use(tenants.GetGinHandler(getTenantID))
+	}
+	if limiter != nil {
+		limiter.SetTrustedProxies(router)
+		provGroup.Use(limiter.GetGinHandler(getTenantID))
+	}
 
 	provGroup.POST("submit", handler.Submit)
 	publicApiMap["provisioningSubmit"] = path.Join(provisioningPath, "submit")
 
 	return router
 }
+
+// getTenantID returns the ID of the tenant a request is for: the tenant of the
+// authenticated principal, or the default tenant if the principal does not
+// belong to one (e.g. because authentication is disabled).
+func getTenantID(c *gin.Context) string {
+	if tenantID := auth.GetTenant(c); tenantID != "" {
+		return tenantID
+	}
+
+	return defaultTenantID
+}
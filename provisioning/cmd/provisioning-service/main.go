@@ -6,16 +6,20 @@ package main
 import (
 	"context"
 	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/veraison/services/auth"
+	"github.com/veraison/services/bodylimit"
 	"github.com/veraison/services/config"
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/proto"
 	"github.com/veraison/services/provisioning/api"
 	"github.com/veraison/services/provisioning/provisioner"
+	"github.com/veraison/services/ratelimit"
+	"github.com/veraison/services/tenant"
 	"github.com/veraison/services/vtsclient"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -25,11 +29,13 @@ var (
 )
 
 type cfg struct {
-	ListenAddr      string `mapstructure:"listen-addr" valid:"dialstring"`
-	Protocol        string `mapstructure:"protocol" valid:"in(http|https)"`
-	Cert            string `mapstructure:"cert" config:"zerodefault"`
-	CertKey         string `mapstructure:"cert-key" config:"zerodefault"`
-	DiscoveryMaxAge string `mapstructure:"discovery-max-age" config:"zerodefault"`
+	ListenAddr      string           `mapstructure:"listen-addr" valid:"dialstring"`
+	Protocol        string           `mapstructure:"protocol" valid:"in(http|https)"`
+	Cert            string           `mapstructure:"cert" config:"zerodefault"`
+	CertKey         string           `mapstructure:"cert-key" config:"zerodefault"`
+	DiscoveryMaxAge string           `mapstructure:"discovery-max-age" config:"zerodefault"`
+	MaxBodySize     string           `mapstructure:"max-body-size" config:"zerodefault"`
+	MaxBodySizes    []map[string]any `mapstructure:"max-body-sizes" config:"zerodefault"`
 }
 
 func (o cfg) Validate() error {
@@ -111,12 +117,49 @@ func main() {
 		}
 	}()
 
-	apiHandler := api.NewHandler(provisioner, log.Named("api"), cfg.DiscoveryMaxAge)
+	log.Info("initializing tenant checker")
+	tenants, err := tenant.NewCheckerFromConfig(v.Sub("tenant-store"), log.Named("tenant"))
+	if err != nil {
+		log.Fatalf("could not init tenant checker: %v", err)
+	}
+	if tenants != nil {
+		defer func() {
+			if err := tenants.Close(); err != nil {
+				log.Errorf("Could not close tenant store: %v", err)
+			}
+		}()
+	}
+
+	log.Info("initializing rate limiter")
+	limiter, err := ratelimit.NewLimiterFromConfig(v.Sub("rate-limit"),
+		tenants.GetStore(), log.Named("ratelimit"))
+	if err != nil {
+		log.Fatalf("could not init rate limiter: %v", err)
+	}
+	if limiter != nil {
+		defer func() {
+			if err := limiter.Close(); err != nil {
+				log.Errorf("Could not close rate limiter: %v", err)
+			}
+		}()
+	}
+
+	if cfg.Protocol != "https" && auth.GetTLSConfig(authorizer) != nil {
+		log.Fatal(`the auth backend requires protocol to be "https"`)
+	}
+
+	bodyLimits, err := bodylimit.NewFromConfig(api.DefaultMaxBodySize,
+		cfg.MaxBodySize, cfg.MaxBodySizes)
+	if err != nil {
+		log.Fatalf("Could not load body size limits: %v", err)
+	}
+
+	apiHandler := api.NewHandler(provisioner, log.Named("api"), cfg.DiscoveryMaxAge, bodyLimits)
 
 	if cfg.Protocol == "https" {
-		go apiServerTLS(apiHandler, authorizer, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
+		go apiServerTLS(apiHandler, authorizer, tenants, limiter, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
 	} else {
-		go apiServer(apiHandler, authorizer, cfg.ListenAddr)
+		go apiServer(apiHandler, authorizer, tenants, limiter, cfg.ListenAddr)
 	}
 
 	sigs := make(chan os.Signal, 1)
@@ -138,10 +181,16 @@ func terminator(
 	done <- true
 }
 
-func apiServer(apiHandler api.IHandler, authorizer auth.IAuthorizer, listenAddr string) {
+func apiServer(
+	apiHandler api.IHandler,
+	authorizer auth.IAuthorizer,
+	tenants *tenant.Checker,
+	limiter *ratelimit.Limiter,
+	listenAddr string,
+) {
 	log.Infow("initializing provisioning API HTTP service", "address", listenAddr)
 
-	if err := api.NewRouter(apiHandler, authorizer).Run(listenAddr); err != nil {
+	if err := api.NewRouter(apiHandler, authorizer, tenants, limiter).Run(listenAddr); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
@@ -149,12 +198,19 @@ func apiServer(apiHandler api.IHandler, authorizer auth.IAuthorizer, listenAddr
 func apiServerTLS(
 	apiHandler api.IHandler,
 	authorizer auth.IAuthorizer,
+	tenants *tenant.Checker,
+	limiter *ratelimit.Limiter,
 	listenAddr, certFile, keyFile string,
 ) {
 	log.Infow("initializing provisioning API HTTPS service", "address", listenAddr)
 
-	err := api.NewRouter(apiHandler, authorizer).RunTLS(listenAddr, certFile, keyFile)
-	if err != nil {
+	server := &http.Server{
+		Addr:      listenAddr,
+		Handler:   api.NewRouter(apiHandler, authorizer, tenants, limiter),
+		TLSConfig: auth.GetTLSConfig(authorizer),
+	}
+
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package provisioner
 
@@ -8,5 +8,6 @@ type IProvisioner interface {
 	GetVTSState() (*proto.ServiceState, error)
 	IsSupportedMediaType(mt string) (bool, error)
 	SupportedMediaTypes() ([]string, error)
-	SubmitEndorsements(tenantID string, data []byte, mt string) error
+	GetSchemeForMediaType(mt string) (string, error)
+	SubmitEndorsements(tenantID, submitter string, data []byte, mt string) error
 }
@@ -1,4 +1,4 @@
-// Copyright 2022-2024 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 
 package provisioner
@@ -61,10 +61,39 @@ func (p *Provisioner) SupportedMediaTypes() ([]string, error) {
 	return mts.GetMediaTypes(), nil
 }
 
-func (p *Provisioner) SubmitEndorsements(tenantID string, data []byte, mt string) error {
+// GetSchemeForMediaType returns the name of the attestation scheme that handles
+// endorsements of the specified media type.
+func (p *Provisioner) GetSchemeForMediaType(mt string) (string, error) {
+	normalizedMediaType, err := api.NormalizeMediaType(mt)
+	if err != nil {
+		return "", fmt.Errorf("%w: validation failed for %s (%v)", ErrInputParam, mt, err)
+	}
+
+	mts, err := p.VTSClient.GetProvisioningMediaTypeSchemes(
+		context.Background(),
+		&emptypb.Empty{},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	scheme, ok := mts.GetSchemes()[normalizedMediaType]
+	if !ok {
+		return "", fmt.Errorf("%w: no scheme found for %s", ErrInputParam, mt)
+	}
+
+	return scheme, nil
+}
+
+func (p *Provisioner) SubmitEndorsements(tenantID, submitter string, data []byte, mt string) error {
 	// return p.VTSClient.SubmitEndorsements(context.Background(),)
-	sReq := &proto.SubmitEndorsementsRequest{MediaType: mt, Data: data}
-	sRes, err := p.VTSClient.SubmitEndorsements(context.Background(), sReq)
+	sReq := &proto.SubmitEndorsementsRequest{
+		MediaType: mt,
+		Data:      data,
+		Submitter: submitter,
+		TenantId:  tenantID,
+	}
+	sRes, err := vtsclient.SubmitEndorsements(context.Background(), p.VTSClient, sReq)
 	if err != nil {
 		if errors.As(err, &vtsclient.NoConnectionError{}) {
 			return errors.New("no connection")
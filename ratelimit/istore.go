@@ -0,0 +1,32 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// UpdateFunc is called with the current value held under a key (nil if
+// there is none), and returns the updated value along with the duration after
+// which it expires. If the returned value is nil, the key is left unchanged.
+type UpdateFunc func(current []byte) (updated []byte, ttl time.Duration)
+
+// IStore holds the state of the rate limits. Implementations must be safe for
+// concurrent use, including by multiple service instances sharing the same
+// store.
+type IStore interface {
+	Init(v *viper.Viper) error
+	Close() error
+
+	// Update atomically replaces the value held under the key with the
+	// value returned by update. update may be called more than once if
+	// the value is modified concurrently.
+	Update(key string, update UpdateFunc) error
+
+	// Increment atomically increments the counter held under the key and
+	// returns its new value. If the counter does not exist, it is created
+	// with a value of 1, expiring after ttl.
+	Increment(key string, ttl time.Duration) (uint64, error)
+}
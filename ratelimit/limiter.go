@@ -0,0 +1,402 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/auth"
+	"github.com/veraison/services/config"
+	"github.com/veraison/services/tenant"
+	"go.uber.org/zap"
+)
+
+const DefaultBackend = "ttlcache"
+
+type cfg struct {
+	Backend                 string                 `mapstructure:"backend"`
+	TenantRequestsPerMinute int                    `mapstructure:"tenant-requests-per-minute" config:"zerodefault"`
+	TenantBurst             int                    `mapstructure:"tenant-burst" config:"zerodefault"`
+	TenantRequestsPerDay    int                    `mapstructure:"tenant-requests-per-day" config:"zerodefault"`
+	ClientRequestsPerMinute int                    `mapstructure:"client-requests-per-minute" config:"zerodefault"`
+	ClientBurst             int                    `mapstructure:"client-burst" config:"zerodefault"`
+	ClientRequestsPerDay    int                    `mapstructure:"client-requests-per-day" config:"zerodefault"`
+	TrustedProxies          []string               `mapstructure:"trusted-proxies" config:"zerodefault"`
+	BackendConfigs          map[string]interface{} `mapstructure:",remain"`
+}
+
+func (o cfg) Validate() error {
+	if err := o.tenantLimits().Validate(); err != nil {
+		return fmt.Errorf("tenant limits: %w", err)
+	}
+
+	if err := o.clientLimits().Validate(); err != nil {
+		return fmt.Errorf("client limits: %w", err)
+	}
+
+	for _, proxy := range o.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			return fmt.Errorf("invalid trusted proxy %q: expected an IP address or CIDR", proxy)
+		}
+	}
+
+	supportedBackends := map[string]bool{
+		"ttlcache":  true,
+		"memcached": true,
+	}
+
+	var unexpected []string
+	for k := range o.BackendConfigs {
+		if _, ok := supportedBackends[k]; !ok {
+			unexpected = append(unexpected, k)
+		}
+	}
+
+	if len(unexpected) > 0 {
+		sort.Strings(unexpected)
+		return fmt.Errorf("unexpected directives: %s", strings.Join(unexpected, ", "))
+	}
+
+	return nil
+}
+
+func (o cfg) tenantLimits() Limits {
+	return Limits{
+		RequestsPerMinute: o.TenantRequestsPerMinute,
+		Burst:             o.TenantBurst,
+		RequestsPerDay:    o.TenantRequestsPerDay,
+	}
+}
+
+func (o cfg) clientLimits() Limits {
+	return Limits{
+		RequestsPerMinute: o.ClientRequestsPerMinute,
+		Burst:             o.ClientBurst,
+		RequestsPerDay:    o.ClientRequestsPerDay,
+	}
+}
+
+// Limits are the limits on the requests made by a tenant or a client. A zero
+// value means that the corresponding limit is not applied.
+type Limits struct {
+	// RequestsPerMinute is the rate at which the token bucket is
+	// replenished.
+	RequestsPerMinute int
+	// Burst is the size of the token bucket, i.e. the number of requests
+	// that may be made at once. If zero, it is RequestsPerMinute.
+	Burst int
+	// RequestsPerDay is the maximum number of requests per (UTC) day.
+	RequestsPerDay int
+}
+
+// Validate returns an error if the limits are invalid.
+func (o Limits) Validate() error {
+	if o.RequestsPerMinute < 0 || o.Burst < 0 || o.RequestsPerDay < 0 {
+		return errors.New("limits may not be negative")
+	}
+
+	if o.Burst != 0 && o.RequestsPerMinute == 0 {
+		return errors.New("burst specified without requests per minute")
+	}
+
+	return nil
+}
+
+// Limiter rejects requests from tenants and clients that have exceeded their
+// rate limits or daily quotas. Rate limits are enforced using token buckets,
+// allowing bursts of requests up to the bucket size.
+type Limiter struct {
+	Store  IStore
+	Logger *zap.SugaredLogger
+
+	// TenantLimits are the limits applied to each tenant, unless
+	// overridden by the tenant's quotas in the registry.
+	TenantLimits Limits
+	// ClientLimits are the limits applied to each client identity (the
+	// authenticated principal, or the client's IP address if the request
+	// is not authenticated).
+	ClientLimits Limits
+	// Tenants is the registry from which the tenants' quotas are read. It
+	// may be nil, in which case TenantLimits apply to all tenants.
+	Tenants *tenant.Store
+	// TrustedProxies are the IP addresses or CIDRs of the proxies trusted
+	// to report the IP addresses of clients (see SetTrustedProxies). If
+	// empty, the address of the peer is used as the client's.
+	TrustedProxies []string
+
+	now func() time.Time
+}
+
+func NewLimiter(
+	store IStore,
+	tenantLimits, clientLimits Limits,
+	tenants *tenant.Store,
+	logger *zap.SugaredLogger,
+) *Limiter {
+	return &Limiter{
+		Store:        store,
+		Logger:       logger,
+		TenantLimits: tenantLimits,
+		ClientLimits: clientLimits,
+		Tenants:      tenants,
+		now:          time.Now,
+	}
+}
+
+// NewLimiterFromConfig returns a new Limiter using the limits and the store
+// backend specified by the provided config. tenants may be nil (see Limiter).
+// If v is nil (i.e. rate limiting has not been configured), nil is returned,
+// and requests should not be limited.
+func NewLimiterFromConfig(
+	v *viper.Viper,
+	tenants *tenant.Store,
+	logger *zap.SugaredLogger,
+) (*Limiter, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	cfg := cfg{
+		Backend: DefaultBackend,
+	}
+
+	loader := config.NewLoader(&cfg)
+	if err := loader.LoadFromViper(v); err != nil {
+		return nil, err
+	}
+
+	var store IStore
+	switch cfg.Backend {
+	case "ttlcache":
+		store = NewTTLCache()
+	case "memcached":
+		store = NewMemcached()
+	default:
+		return nil, fmt.Errorf("backend %q is not supported", cfg.Backend)
+	}
+
+	if err := store.Init(v.Sub(cfg.Backend)); err != nil {
+		return nil, err
+	}
+
+	limiter := NewLimiter(store, cfg.tenantLimits(), cfg.clientLimits(), tenants, logger)
+	limiter.TrustedProxies = cfg.TrustedProxies
+
+	return limiter, nil
+}
+
+// Close the underlying store.
+func (o *Limiter) Close() error {
+	return o.Store.Close()
+}
+
+// SetTrustedProxies configures the router to only take the IP addresses of
+// clients from the X-Forwarded-For (or X-Real-IP) headers of requests made
+// via the TrustedProxies, so that clients cannot evade their limits by
+// spoofing the headers. It must be called for routers the handler returned
+// by GetGinHandler is used with, as gin trusts all proxies by default. If the
+// TrustedProxies are invalid, no proxies are trusted.
+func (o *Limiter) SetTrustedProxies(router *gin.Engine) {
+	if err := router.SetTrustedProxies(o.TrustedProxies); err != nil {
+		o.Logger.Errorw("invalid trusted proxies (not trusting any)", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+}
+
+// GetGinHandler returns a gin.HandlerFunc that rejects requests from tenants
+// and clients that have exceeded their limits with 429 Too Many Requests and a
+// Retry-After header. getTenantID returns the ID of the tenant a request is
+// for; if it is nil, only client limits are applied. The handler should be
+// set after the authorizer's, so that clients are identified by their
+// principal. This function can be set as gin middleware by passing it to
+// gin.Engine.Use().
+func (o *Limiter) GetGinHandler(getTenantID func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if getTenantID != nil {
+			id := getTenantID(c)
+			if !o.allow(c, "tenant", id, o.getTenantLimits(id)) {
+				return
+			}
+		}
+
+		o.allow(c, "client", getClientID(c), o.ClientLimits)
+	}
+}
+
+// allow returns true if the request does not exceed the limits for the
+// identity within the scope. Otherwise, a problem is reported and false is
+// returned. Should the limits not be possible to check (e.g. because the
+// store is unavailable), the request is allowed.
+func (o *Limiter) allow(c *gin.Context, scope, id string, limits Limits) bool {
+	retryAfter, reason, err := o.check(scope, id, limits)
+	if err != nil {
+		o.Logger.Errorw("could not check rate limits", scope, id, "error", err)
+		return true
+	}
+
+	if retryAfter <= 0 {
+		return true
+	}
+
+	o.Logger.Debugw("rejecting request", scope, id, "reason", reason,
+		"retry-after", retryAfter)
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	auth.ReportProblem(c, http.StatusTooManyRequests,
+		fmt.Sprintf("%s exceeded for %s %q", reason, scope, id))
+
+	return false
+}
+
+// check returns the time after which a request from the identity within the
+// scope may be retried, along with the reason, if the request exceeds its
+// limits. Otherwise, zero is returned.
+func (o *Limiter) check(scope, id string, limits Limits) (time.Duration, string, error) {
+	key := makeKey(scope, id)
+
+	if limits.RequestsPerMinute > 0 {
+		burst := limits.Burst
+		if burst == 0 {
+			burst = limits.RequestsPerMinute
+		}
+
+		retryAfter, err := o.takeToken(key+":rate", limits.RequestsPerMinute, burst)
+		if err != nil || retryAfter > 0 {
+			return retryAfter, "rate limit", err
+		}
+	}
+
+	if limits.RequestsPerDay > 0 {
+		retryAfter, err := o.countRequest(key+":day", limits.RequestsPerDay)
+		if err != nil || retryAfter > 0 {
+			return retryAfter, "daily quota", err
+		}
+	}
+
+	return 0, "", nil
+}
+
+// takeToken takes a token from the bucket held under the key, returning the
+// time until one becomes available if it is empty. The bucket is implemented
+// using the generic cell rate algorithm, so that its state is a single
+// timestamp: the theoretical arrival time (TAT) of the next request were the
+// requests to arrive at exactly the permitted rate.
+func (o *Limiter) takeToken(key string, perMinute, burst int) (time.Duration, error) {
+	now := o.now()
+	interval := time.Minute / time.Duration(perMinute)
+	tolerance := interval * time.Duration(burst-1)
+
+	var retryAfter time.Duration
+
+	err := o.Store.Update(key, func(current []byte) ([]byte, time.Duration) {
+		retryAfter = 0
+
+		tat := now
+		if current != nil {
+			nanos, err := strconv.ParseInt(string(current), 10, 64)
+			if err == nil && time.Unix(0, nanos).After(now) {
+				tat = time.Unix(0, nanos)
+			}
+		}
+
+		if wait := tat.Sub(now) - tolerance; wait > 0 {
+			retryAfter = wait
+			return nil, 0
+		}
+
+		tat = tat.Add(interval)
+
+		return []byte(strconv.FormatInt(tat.UnixNano(), 10)), tat.Sub(now)
+	})
+
+	return retryAfter, err
+}
+
+// countRequest increments the counter of today's requests held under the key,
+// returning the time until the end of the day if it exceeds the quota.
+func (o *Limiter) countRequest(key string, perDay int) (time.Duration, error) {
+	now := o.now().UTC()
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	untilEndOfDay := endOfDay.Sub(now)
+
+	count, err := o.Store.Increment(key+":"+now.Format("20060102"), untilEndOfDay)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > uint64(perDay) {
+		return untilEndOfDay, nil
+	}
+
+	return 0, nil
+}
+
+// getTenantLimits returns the limits for the tenant with the specified ID:
+// the configured tenant limits, overridden by the tenant's quotas, if set in
+// the registry.
+func (o *Limiter) getTenantLimits(id string) Limits {
+	limits := o.TenantLimits
+
+	if o.Tenants == nil {
+		return limits
+	}
+
+	t, err := o.Tenants.Get(id)
+	if err != nil {
+		if !errors.Is(err, tenant.ErrNoTenant) {
+			o.Logger.Errorw("could not get tenant quotas", "tenant", id, "error", err)
+		}
+		return limits
+	}
+
+	if t.Quotas.RequestsPerMinute > 0 {
+		limits.RequestsPerMinute = t.Quotas.RequestsPerMinute
+	}
+
+	if t.Quotas.RequestsPerDay > 0 {
+		limits.RequestsPerDay = t.Quotas.RequestsPerDay
+	}
+
+	return limits
+}
+
+// getClientID returns the identity of the client making the request: the
+// authenticated principal, or, if the request has not been authenticated,
+// the client's IP address (see Limiter.SetTrustedProxies).
+func getClientID(c *gin.Context) string {
+	if principal := auth.GetPrincipal(c); principal != "" {
+		return principal
+	}
+
+	return c.ClientIP()
+}
+
+// makeKey returns the key under which the state of the limits for the
+// identity within the scope is held. Identities are hashed, as they may
+// contain characters (e.g. spaces in certificate subjects) that are not valid
+// in keys of some stores.
+func makeKey(scope, id string) string {
+	digest := sha256.Sum256([]byte(id))
+	return "ratelimit:" + scope + ":" + hex.EncodeToString(digest[:16])
+}
+
+func isIPOrCIDR(s string) bool {
+	if net.ParseIP(s) != nil {
+		return true
+	}
+
+	_, _, err := net.ParseCIDR(s)
+
+	return err == nil
+}
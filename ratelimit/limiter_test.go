@@ -0,0 +1,234 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package ratelimit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moogar0880/problems"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/tenant"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (o *testClock) Now() time.Time {
+	return o.now
+}
+
+func (o *testClock) Advance(d time.Duration) {
+	o.now = o.now.Add(d)
+}
+
+func newTestLimiter(t *testing.T, tenantLimits, clientLimits Limits, tenants *tenant.Store) (*Limiter, *testClock) {
+	store := NewTTLCache()
+	require.NoError(t, store.Init(nil))
+	t.Cleanup(func() { _ = store.Close() })
+
+	clock := &testClock{now: time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)}
+
+	limiter := NewLimiter(store, tenantLimits, clientLimits, tenants, log.Named("test"))
+	limiter.now = clock.Now
+
+	return limiter, clock
+}
+
+func TestLimiter_check_rate(t *testing.T) {
+	limiter, clock := newTestLimiter(t, Limits{}, Limits{}, nil)
+	limits := Limits{RequestsPerMinute: 60, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		retryAfter, _, err := limiter.check("tenant", "acme", limits)
+		require.NoError(t, err)
+		assert.Zero(t, retryAfter, i)
+	}
+
+	retryAfter, reason, err := limiter.check("tenant", "acme", limits)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, retryAfter)
+	assert.Equal(t, "rate limit", reason)
+
+	// other identities have their own buckets
+	retryAfter, _, err = limiter.check("tenant", "other", limits)
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+
+	clock.Advance(500 * time.Millisecond)
+
+	retryAfter, _, err = limiter.check("tenant", "acme", limits)
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	clock.Advance(500 * time.Millisecond)
+
+	retryAfter, _, err = limiter.check("tenant", "acme", limits)
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+
+	retryAfter, _, err = limiter.check("tenant", "acme", limits)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, retryAfter)
+}
+
+func TestLimiter_check_daily_quota(t *testing.T) {
+	limiter, clock := newTestLimiter(t, Limits{}, Limits{}, nil)
+	limits := Limits{RequestsPerDay: 2}
+
+	for i := 0; i < 2; i++ {
+		retryAfter, _, err := limiter.check("client", "alice", limits)
+		require.NoError(t, err)
+		assert.Zero(t, retryAfter, i)
+	}
+
+	retryAfter, reason, err := limiter.check("client", "alice", limits)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, retryAfter)
+	assert.Equal(t, "daily quota", reason)
+
+	// the quota is reset at the start of the next day
+	clock.Advance(time.Minute)
+
+	retryAfter, _, err = limiter.check("client", "alice", limits)
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+}
+
+func TestLimiter_getTenantLimits(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := tenant.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Add(&tenant.Tenant{
+		ID:     "acme",
+		State:  tenant.StateActive,
+		Quotas: tenant.Quotas{RequestsPerMinute: 600},
+	}, "")
+	require.NoError(t, err)
+
+	defaults := Limits{RequestsPerMinute: 60, Burst: 10, RequestsPerDay: 1000}
+	limiter, _ := newTestLimiter(t, defaults, Limits{}, store)
+
+	assert.Equal(t, Limits{RequestsPerMinute: 600, Burst: 10, RequestsPerDay: 1000},
+		limiter.getTenantLimits("acme"))
+	assert.Equal(t, defaults, limiter.getTenantLimits("unknown"))
+}
+
+func TestLimiter_GetGinHandler(t *testing.T) {
+	limiter, _ := newTestLimiter(t,
+		Limits{RequestsPerMinute: 1, Burst: 2},
+		Limits{RequestsPerMinute: 1},
+		nil,
+	)
+
+	router := gin.New()
+	router.Use(limiter.GetGinHandler(func(c *gin.Context) string {
+		return c.GetHeader("Tenant")
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(tenantID, clientIP string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Tenant", tenantID)
+		req.RemoteAddr = clientIP + ":1234"
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, get("acme", "192.0.2.1").Code)
+	assert.Equal(t, http.StatusOK, get("acme", "192.0.2.2").Code)
+
+	w := get("acme", "192.0.2.3")
+
+	var body problems.DefaultProblem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "application/problem+json", w.Result().Header.Get("Content-Type"))
+	assert.Equal(t, "60", w.Result().Header.Get("Retry-After"))
+	assert.Equal(t, `rate limit exceeded for tenant "acme"`, body.Detail)
+
+	w = get("other", "192.0.2.1")
+
+	body = problems.DefaultProblem{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Result().Header.Get("Retry-After"))
+	assert.Equal(t, `rate limit exceeded for client "192.0.2.1"`, body.Detail)
+}
+
+func TestLimiter_SetTrustedProxies(t *testing.T) {
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		limiter, _ := newTestLimiter(t, Limits{}, Limits{RequestsPerMinute: 1}, nil)
+		limiter.TrustedProxies = trustedProxies
+
+		router := gin.New()
+		limiter.SetTrustedProxies(router)
+		router.Use(limiter.GetGinHandler(nil))
+		router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		return router
+	}
+
+	get := func(router *gin.Engine, remoteIP, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = remoteIP + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// by default, no proxies are trusted, so clients cannot evade their
+	// limits by spoofing X-Forwarded-For
+	router := newRouter(nil)
+	assert.Equal(t, http.StatusOK, get(router, "192.0.2.1", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, get(router, "192.0.2.1", "198.51.100.2"))
+
+	// clients behind a trusted proxy are identified by the address it
+	// reports
+	router = newRouter([]string{"192.0.2.0/24"})
+	assert.Equal(t, http.StatusOK, get(router, "192.0.2.1", "198.51.100.1"))
+	assert.Equal(t, http.StatusOK, get(router, "192.0.2.1", "198.51.100.2"))
+	assert.Equal(t, http.StatusTooManyRequests, get(router, "192.0.2.2", "198.51.100.2"))
+
+	// ...but not when the request is not made via a trusted proxy
+	assert.Equal(t, http.StatusOK, get(router, "203.0.113.1", "198.51.100.3"))
+	assert.Equal(t, http.StatusTooManyRequests, get(router, "203.0.113.1", "198.51.100.4"))
+
+	// invalid proxies are not trusted
+	router = newRouter([]string{"not-an-ip"})
+	assert.Equal(t, http.StatusOK, get(router, "192.0.2.1", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, get(router, "192.0.2.1", "198.51.100.2"))
+}
+
+func TestNewLimiterFromConfig_trusted_proxies(t *testing.T) {
+	v := viper.New()
+	v.Set("client-requests-per-minute", 60)
+	v.Set("trusted-proxies", []string{"10.0.0.1", "192.0.2.0/24", "2001:db8::/32"})
+
+	limiter, err := NewLimiterFromConfig(v, nil, log.Named("test"))
+	require.NoError(t, err)
+	defer limiter.Close()
+
+	assert.Equal(t, []string{"10.0.0.1", "192.0.2.0/24", "2001:db8::/32"}, limiter.TrustedProxies)
+
+	v.Set("trusted-proxies", []string{"10.0.0.0/33"})
+
+	_, err = NewLimiterFromConfig(v, nil, log.Named("test"))
+	assert.ErrorContains(t, err, `invalid trusted proxy "10.0.0.0/33"`)
+}
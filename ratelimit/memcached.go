@@ -0,0 +1,131 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/config"
+)
+
+const DefaultMemcachedServer = "localhost:11211"
+
+// maxCASAttempts is the number of times an update is attempted before giving
+// up due to the value being concurrently modified.
+const maxCASAttempts = 10
+
+var errContention = errors.New("value modified concurrently too many times")
+
+type memcachedConfig struct {
+	Servers []string `mapstructure:"servers"`
+}
+
+// Memcached keeps the state of the rate limits in memcached, so that the
+// limits apply across all service instances using the same servers (e.g. the
+// servers used by the verification session manager).
+type Memcached struct {
+	client *memcache.Client
+}
+
+func NewMemcached() *Memcached {
+	return &Memcached{}
+}
+
+func (o *Memcached) Init(v *viper.Viper) error {
+	cfg := memcachedConfig{
+		Servers: []string{DefaultMemcachedServer},
+	}
+
+	if v != nil {
+		loader := config.NewLoader(&cfg)
+		if err := loader.LoadFromViper(v); err != nil {
+			return fmt.Errorf("memcached: %w", err)
+		}
+	}
+
+	client := memcache.New(cfg.Servers...)
+	if err := client.Ping(); err != nil {
+		return fmt.Errorf("memcached: %w", err)
+	}
+	o.client = client
+
+	return nil
+}
+
+func (o *Memcached) Close() error {
+	return o.client.Close()
+}
+
+func (o *Memcached) Update(key string, update UpdateFunc) error {
+	for i := 0; i < maxCASAttempts; i++ {
+		item, err := o.client.Get(key)
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			updated, ttl := update(nil)
+			if updated == nil {
+				return nil
+			}
+
+			err = o.client.Add(&memcache.Item{
+				Key:        key,
+				Value:      updated,
+				Expiration: toExpiration(ttl),
+			})
+			if errors.Is(err, memcache.ErrNotStored) {
+				continue // added concurrently
+			}
+
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		updated, ttl := update(item.Value)
+		if updated == nil {
+			return nil
+		}
+
+		item.Value = updated
+		item.Expiration = toExpiration(ttl)
+
+		err = o.client.CompareAndSwap(item)
+		if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+			continue // modified or expired concurrently
+		}
+
+		return err
+	}
+
+	return fmt.Errorf("memcached: %q: %w", key, errContention)
+}
+
+func (o *Memcached) Increment(key string, ttl time.Duration) (uint64, error) {
+	count, err := o.client.Increment(key, 1)
+	if !errors.Is(err, memcache.ErrCacheMiss) {
+		return count, err
+	}
+
+	err = o.client.Add(&memcache.Item{
+		Key:        key,
+		Value:      []byte("1"),
+		Expiration: toExpiration(ttl),
+	})
+	if err == nil {
+		return 1, nil
+	} else if !errors.Is(err, memcache.ErrNotStored) {
+		return 0, err
+	}
+
+	// added concurrently
+	return o.client.Increment(key, 1)
+}
+
+// toExpiration converts ttl to a memcached expiration in seconds. This is
+// rounded up, as an expiration of zero means that the item does not expire.
+func toExpiration(ttl time.Duration) int32 {
+	return int32(math.Max(1, math.Ceil(ttl.Seconds())))
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package ratelimit
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/spf13/viper"
+)
+
+// TTLCache keeps the state of the rate limits in the memory of the service
+// process, so the limits apply to each service instance separately.
+type TTLCache struct {
+	mu    sync.Mutex
+	cache *ttlcache.Cache[string, []byte]
+}
+
+func NewTTLCache() *TTLCache {
+	return &TTLCache{}
+}
+
+func (o *TTLCache) Init(v *viper.Viper) error {
+	o.cache = ttlcache.New[string, []byte](
+		ttlcache.WithDisableTouchOnHit[string, []byte](),
+	)
+
+	go o.cache.Start()
+
+	return nil
+}
+
+func (o *TTLCache) Close() error {
+	o.cache.Stop()
+
+	return nil
+}
+
+func (o *TTLCache) Update(key string, update UpdateFunc) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var current []byte
+	if item := o.cache.Get(key); item != nil {
+		current = item.Value()
+	}
+
+	if updated, ttl := update(current); updated != nil {
+		o.cache.Set(key, updated, ttl)
+	}
+
+	return nil
+}
+
+func (o *TTLCache) Increment(key string, ttl time.Duration) (uint64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	item := o.cache.Get(key)
+	if item == nil {
+		o.cache.Set(key, []byte("1"), ttl)
+		return 1, nil
+	}
+
+	count, err := strconv.ParseUint(string(item.Value()), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count++
+
+	// preserve the original expiry, so that the counter is reset at the
+	// end of its period irrespective of when it was last incremented.
+	remaining := time.Until(item.ExpiresAt())
+	if remaining <= 0 {
+		remaining = time.Nanosecond
+	}
+	o.cache.Set(key, []byte(strconv.FormatUint(count, 10)), remaining)
+
+	return count, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTLCache_Update(t *testing.T) {
+	store := NewTTLCache()
+	require.NoError(t, store.Init(nil))
+	defer store.Close()
+
+	var seen [][]byte
+	update := func(current []byte) ([]byte, time.Duration) {
+		seen = append(seen, current)
+		return append(current, 'x'), time.Minute
+	}
+
+	require.NoError(t, store.Update("key", update))
+	require.NoError(t, store.Update("key", update))
+	require.NoError(t, store.Update("key", func([]byte) ([]byte, time.Duration) {
+		return nil, 0
+	}))
+	require.NoError(t, store.Update("key", update))
+
+	assert.Equal(t, [][]byte{nil, []byte("x"), []byte("xx")}, seen)
+
+	require.NoError(t, store.Update("expiring", func([]byte) ([]byte, time.Duration) {
+		return []byte("x"), time.Millisecond
+	}))
+	time.Sleep(5 * time.Millisecond)
+
+	seen = nil
+	require.NoError(t, store.Update("expiring", update))
+	assert.Equal(t, [][]byte{nil}, seen)
+}
+
+func TestTTLCache_Increment(t *testing.T) {
+	store := NewTTLCache()
+	require.NoError(t, store.Init(nil))
+	defer store.Close()
+
+	for i := uint64(1); i <= 3; i++ {
+		count, err := store.Increment("key", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+
+	count, err := store.Increment("expiring", time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	time.Sleep(5 * time.Millisecond)
+
+	count, err = store.Increment("expiring", time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
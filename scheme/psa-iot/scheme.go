@@ -187,7 +187,8 @@ func (o *Implementation) AppraiseClaims(
 		appraisal.TrustVector.StorageOpaque = ear.UnencryptedSecretsClaim
 	}
 
-	matched, err := matchClaimsToReferenceValues(o.logger, psaClaims, endorsements)
+	matchedEndorsements, matched, err := matchClaimsToReferenceValues(
+		o.logger, psaClaims, endorsements)
 	if err != nil {
 		return result, err
 	}
@@ -203,6 +204,14 @@ func (o *Implementation) AppraiseClaims(
 	appraisal.UpdateStatusFromTrustVector()
 	appraisal.VeraisonAnnotatedEvidence = &claims
 
+	// record the reference values the software components were matched
+	// against, so that their provenance can be reported
+	if matched {
+		if err := handler.AnnotateEndorsements(appraisal, matchedEndorsements...); err != nil {
+			return result, err
+		}
+	}
+
 	return result, nil
 }
 
@@ -215,21 +224,33 @@ func convertMapToPSAClaims(m map[string]any) (psatoken.IClaims, error) {
 	return psatoken.DecodeAndValidateClaimsFromJSON(data)
 }
 
+// referenceValue is a software component measurement provisioned as a
+// reference value.
+type referenceValue struct {
+	label   string
+	version string
+	// triple is the endorsement the measurement is part of.
+	triple *comid.ValueTriple
+}
+
+// matchClaimsToReferenceValues returns true iff every software component in
+// the claims matches a reference value in the endorsements. If so, the
+// endorsements containing the matched reference values are also returned.
 func matchClaimsToReferenceValues(
 	logger *zap.SugaredLogger,
 	claims psatoken.IClaims,
 	endorsements []*comid.ValueTriple,
-) (bool, error) {
-	referenceValues := make(map[string][2]string)
+) ([]*comid.ValueTriple, bool, error) {
+	referenceValues := make(map[string]referenceValue)
 	for _, triple := range endorsements {
 		for _, measurement := range triple.Measurements.Values {
 			if measurement.Val.Digests == nil {
-				return false, errors.New("no digests in reference value measurement")
+				return nil, false, errors.New("no digests in reference value measurement")
 			}
 
 			numDigests := len(*measurement.Val.Digests)
 			if numDigests != 1 {
-				return false, fmt.Errorf(
+				return nil, false, fmt.Errorf(
 					"expected exactly 1 digest in measurement; found %d",
 					numDigests,
 				)
@@ -247,49 +268,57 @@ func matchClaimsToReferenceValues(
 				version = measurement.Val.Ver.Version
 			}
 
-			referenceValues[encoded] = [2]string{label, version}
+			referenceValues[encoded] = referenceValue{label, version, triple}
 		}
 	}
 
 	swComponents, err := claims.GetSoftwareComponents()
 	if err != nil {
-		return false, handler.BadEvidence(err)
+		return nil, false, handler.BadEvidence(err)
 	}
 
+	var matchedEndorsements []*comid.ValueTriple
+	seen := make(map[*comid.ValueTriple]bool)
+
 	for i, swComp := range swComponents {
 		mval, err := swComp.GetMeasurementValue()
 		if err != nil {
-			return false, handler.BadEvidence(fmt.Errorf("S/W comp. %d value: %w", i, err))
+			return nil, false, handler.BadEvidence(fmt.Errorf("S/W comp. %d value: %w", i, err))
 		}
 		mvalEncoded := base64.StdEncoding.EncodeToString(mval)
 
 		mtype, err := swComp.GetMeasurementType()
 		if err != nil {
-			return false, handler.BadEvidence(fmt.Errorf("S/W comp. %d type: %w", i, err))
+			return nil, false, handler.BadEvidence(fmt.Errorf("S/W comp. %d type: %w", i, err))
 		}
 
 		mversion, err := swComp.GetVersion()
 		if err != nil {
-			return false, handler.BadEvidence(fmt.Errorf("S/W comp. %d version: %w", i, err))
+			return nil, false, handler.BadEvidence(fmt.Errorf("S/W comp. %d version: %w", i, err))
 		}
 
 		rvInfo, matched := referenceValues[mvalEncoded]
 		if !matched {
 			logger.Debugf("S/W comp. %d measurement failed to match", i)
-			return false, nil
+			return nil, false, nil
 		}
 		logger.Debugf("S/W comp. %d measurement matched", i)
-		refValLabel := rvInfo[0]
-		refValVersion := rvInfo[1]
+		refValLabel := rvInfo.label
+		refValVersion := rvInfo.version
 
 		typeMatched := refValLabel == "" || mtype == refValLabel
 		versionMatched := refValVersion == "" || mversion == refValVersion
 		logger.Debugf("S/W comp. %d type matched: %t, version matched: %t", i, typeMatched, versionMatched)
 
 		if !typeMatched || !versionMatched {
-			return false, nil
+			return nil, false, nil
+		}
+
+		if !seen[rvInfo.triple] {
+			seen[rvInfo.triple] = true
+			matchedEndorsements = append(matchedEndorsements, rvInfo.triple)
 		}
 	}
 
-	return true, nil
+	return matchedEndorsements, true, nil
 }
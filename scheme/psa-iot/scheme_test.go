@@ -0,0 +1,111 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package psa_iot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/ear"
+	"github.com/veraison/services/handler"
+	"github.com/veraison/swid"
+)
+
+// loadTestClaims returns the claims extracted from test/psa-token.cbor.
+func loadTestClaims(t *testing.T) map[string]any {
+	data, err := os.ReadFile("test/extracted.json")
+	require.NoError(t, err)
+
+	var extracted struct {
+		Evidence map[string]any `json:"evidence"`
+	}
+	require.NoError(t, json.Unmarshal(data, &extracted))
+
+	return extracted.Evidence
+}
+
+// newTestReferenceValue returns a reference value for the software component
+// with the specified measurement type, version and (base64-encoded) value.
+func newTestReferenceValue(t *testing.T, mtype, version, value string) *comid.ValueTriple {
+	digest, err := base64.StdEncoding.DecodeString(value)
+	require.NoError(t, err)
+
+	m := comid.Measurement{}
+	m.Val.Name = &mtype
+	m.Val.Ver = comid.NewVersion().SetVersion(version)
+	m.AddDigest(int(swid.Sha256), digest)
+
+	vendor, model := "ACME", "RoadRunner"
+	triple := comid.ValueTriple{
+		Environment: comid.Environment{
+			Class: &comid.Class{Vendor: &vendor, Model: &model},
+		},
+	}
+	triple.Measurements.Add(&m)
+
+	return &triple
+}
+
+// newTestReferenceValues returns a reference value for each of the software
+// components in the test claims.
+func newTestReferenceValues(t *testing.T) []*comid.ValueTriple {
+	return []*comid.ValueTriple{
+		newTestReferenceValue(t, "BL", "3.4.2", "BwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg="),
+		newTestReferenceValue(t, "M1", "1.2.0", "CwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg="),
+		newTestReferenceValue(t, "M2", "1.2.3", "DwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg="),
+		newTestReferenceValue(t, "M3", "1.0.0", "EwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg="),
+	}
+}
+
+func endorsementIDsOf(t *testing.T, triples ...*comid.ValueTriple) []any {
+	var ids []any
+	for _, triple := range triples {
+		tripleIDs, err := handler.EndorsementIDs(triple)
+		require.NoError(t, err)
+
+		for _, id := range tripleIDs {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func TestImplementation_AppraiseClaims_matched(t *testing.T) {
+	matched := newTestReferenceValues(t)
+	unrelated := newTestReferenceValue(t, "M4", "1.0.0",
+		"FwYFBAMCAQAPDg0MCwoJCBcWFRQTEhEQHx4dHBsaGRg=")
+
+	result, err := NewImplementation().AppraiseClaims(
+		loadTestClaims(t), append(matched, unrelated))
+	require.NoError(t, err)
+
+	appraisal := result.Submods[Descriptor.Name]
+	assert.Equal(t, ear.ApprovedRuntimeClaim, appraisal.TrustVector.Executables)
+
+	// only the endorsements that were matched are recorded
+	annotated := *appraisal.VeraisonAnnotatedEvidence
+	assert.ElementsMatch(t, endorsementIDsOf(t, matched...),
+		annotated[handler.EndorsementProvenanceClaim])
+}
+
+func TestImplementation_AppraiseClaims_mismatch(t *testing.T) {
+	// the reference value for the last software component is missing
+	endorsements := newTestReferenceValues(t)[:3]
+
+	result, err := NewImplementation().AppraiseClaims(loadTestClaims(t), endorsements)
+	require.NoError(t, err)
+
+	appraisal := result.Submods[Descriptor.Name]
+	assert.Equal(t, ear.UnrecognizedRuntimeClaim, appraisal.TrustVector.Executables)
+
+	// none of the endorsements are recorded, as the software was not
+	// recognized
+	annotated := *appraisal.VeraisonAnnotatedEvidence
+	assert.NotContains(t, annotated, handler.EndorsementProvenanceClaim)
+}
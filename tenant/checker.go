@@ -0,0 +1,150 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/veraison/services/auth"
+	"go.uber.org/zap"
+)
+
+var ErrTenantSuspended = errors.New("tenant is suspended")
+
+// ContextKey is the key under which the Tenant a request is for is recorded in
+// the gin.Context by the Checker's handler.
+const ContextKey = "tenant"
+
+// GetTenant returns the Tenant the request is for, as recorded by the Checker's
+// handler, or nil if the request has not been checked (i.e. the tenant
+// registry is not used).
+func GetTenant(c *gin.Context) *Tenant {
+	t, ok := c.Get(ContextKey)
+	if !ok {
+		return nil
+	}
+
+	return t.(*Tenant)
+}
+
+// IsSchemeEnabled returns true iff the specified scheme is enabled for the
+// tenant the request is for. If the request has not been checked, all schemes
+// are enabled.
+func IsSchemeEnabled(c *gin.Context, scheme string) bool {
+	t := GetTenant(c)
+	if t == nil {
+		return true
+	}
+
+	return t.IsSchemeEnabled(scheme)
+}
+
+// Checker ensures that requests are only served for registered tenants that
+// have not been suspended.
+type Checker struct {
+	Store  *Store
+	Logger *zap.SugaredLogger
+
+	// OnUnknownTenant, if set, is called with the ID of the tenant when a
+	// request for a tenant that is not in the registry is rejected. This
+	// allows services to purge data they hold for deleted tenants (such as
+	// verification sessions) that is not accessible to the management
+	// service.
+	OnUnknownTenant func(id string) error
+}
+
+func NewChecker(store *Store, logger *zap.SugaredLogger) *Checker {
+	return &Checker{Store: store, Logger: logger}
+}
+
+// NewCheckerFromConfig returns a new Checker using the tenant store specified
+// by the provided config (see NewStore). If v is nil (i.e. a tenant store has
+// not been configured), nil is returned, and requests should not be checked.
+func NewCheckerFromConfig(v *viper.Viper, logger *zap.SugaredLogger) (*Checker, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	store, err := NewStore(v, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewChecker(store, logger), nil
+}
+
+// GetStore returns the underlying tenant store, or nil if the Checker is nil
+// (i.e. tenants are not checked).
+func (o *Checker) GetStore() *Store {
+	if o == nil {
+		return nil
+	}
+
+	return o.Store
+}
+
+// Close the underlying tenant store.
+func (o *Checker) Close() error {
+	return o.Store.Close()
+}
+
+// Check returns nil if requests for the tenant with the specified ID may be
+// served. Otherwise, an error wrapping ErrNoTenant or ErrTenantSuspended is
+// returned.
+func (o *Checker) Check(id string) error {
+	_, err := o.check(id)
+	return err
+}
+
+// GetGinHandler returns a gin.HandlerFunc that rejects requests for tenants
+// that may not be served. getTenantID returns the ID of the tenant a request
+// is for. The Tenant of accepted requests is recorded in the gin.Context (see
+// GetTenant), so that handlers may check whether the scheme a request is for
+// is enabled (see IsSchemeEnabled). This function can be set as gin middleware
+// by passing it to gin.Engine.Use().
+func (o *Checker) GetGinHandler(getTenantID func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := getTenantID(c)
+
+		tenant, err := o.check(id)
+		switch {
+		case err == nil:
+			c.Set(ContextKey, tenant)
+			c.Next()
+		case errors.Is(err, ErrNoTenant):
+			o.Logger.Debugw("rejecting request for unknown tenant", "tenant", id)
+			if o.OnUnknownTenant != nil {
+				if err := o.OnUnknownTenant(id); err != nil {
+					o.Logger.Errorw("could not purge unknown tenant", "tenant", id, "error", err)
+				}
+			}
+			auth.ReportProblem(c, http.StatusForbidden,
+				fmt.Sprintf("unknown tenant %q", id))
+		case errors.Is(err, ErrTenantSuspended):
+			o.Logger.Debugw("rejecting request for suspended tenant", "tenant", id)
+			auth.ReportProblem(c, http.StatusForbidden,
+				fmt.Sprintf("tenant %q is suspended", id))
+		default:
+			o.Logger.Errorw("could not check tenant", "tenant", id, "error", err)
+			auth.ReportProblem(c, http.StatusInternalServerError,
+				fmt.Sprintf("could not check tenant %q", id))
+		}
+	}
+}
+
+func (o *Checker) check(id string) (*Tenant, error) {
+	tenant, err := o.Store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tenant.IsActive() {
+		return nil, fmt.Errorf("%w: %q", ErrTenantSuspended, id)
+	}
+
+	return tenant, nil
+}
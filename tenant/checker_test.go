@@ -0,0 +1,131 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func Test_Checker_GetGinHandler(t *testing.T) {
+	store := newMemoryStore(t)
+	defer store.Close()
+
+	_, err := store.Add(&Tenant{ID: "active"}, "")
+	require.NoError(t, err)
+	_, err = store.Add(&Tenant{ID: "suspended", State: StateSuspended}, "")
+	require.NoError(t, err)
+
+	checker := NewChecker(store, log.Named("test"))
+
+	var unknown []string
+	checker.OnUnknownTenant = func(id string) error {
+		unknown = append(unknown, id)
+		return nil
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(checker.GetGinHandler(func(c *gin.Context) string {
+		return c.Query("tenant")
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tvs := []struct {
+		tenant string
+		status int
+		detail string
+	}{
+		{"active", http.StatusNoContent, ""},
+		{"suspended", http.StatusForbidden, `tenant \"suspended\" is suspended`},
+		{"unknown", http.StatusForbidden, `unknown tenant \"unknown\"`},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.tenant, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/?tenant="+tv.tenant, http.NoBody)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tv.status, w.Code)
+			assert.Contains(t, w.Body.String(), tv.detail)
+		})
+	}
+
+	assert.Equal(t, []string{"unknown"}, unknown)
+}
+
+func Test_Checker_GetGinHandler_scheme(t *testing.T) {
+	store := newMemoryStore(t)
+	defer store.Close()
+
+	_, err := store.Add(&Tenant{ID: "all"}, "")
+	require.NoError(t, err)
+	_, err = store.Add(&Tenant{ID: "psa", Schemes: []string{"PSA_IOT"}}, "")
+	require.NoError(t, err)
+
+	checker := NewChecker(store, log.Named("test"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(checker.GetGinHandler(func(c *gin.Context) string {
+		return c.Query("tenant")
+	}))
+	router.GET("/", func(c *gin.Context) {
+		assert.Equal(t, c.Query("tenant"), GetTenant(c).ID)
+
+		if !IsSchemeEnabled(c, c.Query("scheme")) {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	tvs := []struct {
+		tenant string
+		scheme string
+		status int
+	}{
+		{"all", "PSA_IOT", http.StatusNoContent},
+		{"all", "ARM_CCA", http.StatusNoContent},
+		{"psa", "PSA_IOT", http.StatusNoContent},
+		{"psa", "ARM_CCA", http.StatusForbidden},
+	}
+
+	for _, tv := range tvs {
+		t.Run(tv.tenant+"/"+tv.scheme, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet,
+				"/?tenant="+tv.tenant+"&scheme="+tv.scheme, http.NoBody)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tv.status, w.Code)
+		})
+	}
+}
+
+func Test_IsSchemeEnabled_unchecked(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.Nil(t, GetTenant(c))
+	assert.True(t, IsSchemeEnabled(c, "PSA_IOT"))
+}
+
+func Test_Checker_GetStore(t *testing.T) {
+	store := newMemoryStore(t)
+	defer store.Close()
+
+	assert.Same(t, store, NewChecker(store, log.Named("test")).GetStore())
+
+	var unchecked *Checker
+	assert.Nil(t, unchecked.GetStore())
+}
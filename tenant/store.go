@@ -0,0 +1,159 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/veraison/services/kvstore"
+	"go.uber.org/zap"
+)
+
+var ErrNoTenant = errors.New("no tenant found")
+var ErrTenantExists = errors.New("tenant already exists")
+
+// NewStore returns a new tenant store. Config options are the same as those
+// used for kvstore.New().
+func NewStore(v *viper.Viper, logger *zap.SugaredLogger) (*Store, error) {
+	kvStore, err := kvstore.New(v, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{KVStore: kvStore, Logger: logger}, nil
+}
+
+// Store is the registry of tenants, kept in a kvstore under their IDs.
+type Store struct {
+	KVStore kvstore.IKVStore
+	Logger  *zap.SugaredLogger
+}
+
+// Setup the underyling kvstore. This is a one-time setup that only needs to be
+// performed once for a deployment.
+func (o *Store) Setup() error {
+	return o.KVStore.Setup()
+}
+
+// Add registers a new tenant. The tenant is created in StateActive, unless
+// its State is set. user identifies the principal creating the tenant.
+func (o *Store) Add(tenant *Tenant, user string) (*Tenant, error) {
+	if tenant.State == "" {
+		tenant.State = StateActive
+	}
+
+	if err := tenant.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := o.Get(tenant.ID); err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrTenantExists, tenant.ID)
+	} else if !errors.Is(err, ErrNoTenant) {
+		return nil, err
+	}
+
+	now := time.Now()
+	tenant.CTime = now
+	tenant.MTime = now
+	tenant.CreatedBy = user
+	tenant.UpdatedBy = user
+
+	return tenant, o.set(tenant)
+}
+
+// Get returns the tenant with the specified ID, or an error wrapping
+// ErrNoTenant if it has not been registered.
+func (o *Store) Get(id string) (*Tenant, error) {
+	vals, err := o.KVStore.Get(id)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %q", ErrNoTenant, id)
+		}
+		return nil, err
+	}
+
+	if len(vals) != 1 {
+		return nil, fmt.Errorf("found %d values for tenant %q; expected 1", len(vals), id)
+	}
+
+	var tenant Tenant
+	if err := json.Unmarshal([]byte(vals[0]), &tenant); err != nil {
+		return nil, fmt.Errorf("bad tenant %q: %w", id, err)
+	}
+
+	return &tenant, nil
+}
+
+// List returns all registered tenants, ordered by ID.
+func (o *Store) List() ([]*Tenant, error) {
+	ids, err := o.KVStore.GetKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(ids)
+
+	ret := make([]*Tenant, 0, len(ids))
+	for _, id := range ids {
+		tenant, err := o.Get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, tenant)
+	}
+
+	return ret, nil
+}
+
+// SetState sets the state of the tenant with the specified ID (see
+// StateActive and StateSuspended), returning the updated tenant. user
+// identifies the principal performing the update.
+func (o *Store) SetState(id, state, user string) (*Tenant, error) {
+	tenant, err := o.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.State = state
+	if err := tenant.Validate(); err != nil {
+		return nil, err
+	}
+
+	tenant.MTime = time.Now()
+	tenant.UpdatedBy = user
+
+	return tenant, o.set(tenant)
+}
+
+// Del removes the tenant with the specified ID from the registry. This does
+// not remove the tenant's data from the other stores.
+func (o *Store) Del(id string) error {
+	if err := o.KVStore.Del(id); err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return fmt.Errorf("%w: %q", ErrNoTenant, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Close the connection to the underlying kvstore.
+func (o *Store) Close() error {
+	return o.KVStore.Close()
+}
+
+func (o *Store) set(tenant *Tenant) error {
+	tenantBytes, err := json.Marshal(tenant)
+	if err != nil {
+		return err
+	}
+
+	return o.KVStore.Set(tenant.ID, string(tenantBytes))
+}
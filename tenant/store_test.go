@@ -0,0 +1,79 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package tenant
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/log"
+)
+
+func newMemoryStore(t *testing.T) *Store {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+
+	return store
+}
+
+func Test_Store_CRUD(t *testing.T) {
+	store := newMemoryStore(t)
+	defer store.Close()
+
+	_, err := store.Get("acme")
+	assert.ErrorIs(t, err, ErrNoTenant)
+
+	tenant, err := store.Add(&Tenant{
+		ID:      "acme",
+		Name:    "ACME Corp.",
+		Schemes: []string{"PSA_IOT"},
+	}, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, StateActive, tenant.State)
+	assert.Equal(t, "alice", tenant.CreatedBy)
+	assert.False(t, tenant.CTime.IsZero())
+
+	_, err = store.Add(&Tenant{ID: "acme"}, "alice")
+	assert.ErrorIs(t, err, ErrTenantExists)
+
+	_, err = store.Add(&Tenant{ID: "bad:id"}, "alice")
+	assert.ErrorIs(t, err, ErrBadTenant)
+
+	_, err = store.Add(&Tenant{ID: "0"}, "")
+	require.NoError(t, err)
+
+	tenant, err = store.SetState("acme", StateSuspended, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, StateSuspended, tenant.State)
+	assert.Equal(t, "bob", tenant.UpdatedBy)
+
+	_, err = store.SetState("acme", "deleted", "bob")
+	assert.ErrorIs(t, err, ErrBadTenant)
+
+	_, err = store.SetState("nobody", StateActive, "bob")
+	assert.ErrorIs(t, err, ErrNoTenant)
+
+	tenant, err = store.Get("acme")
+	require.NoError(t, err)
+	assert.Equal(t, "ACME Corp.", tenant.Name)
+	assert.Equal(t, StateSuspended, tenant.State)
+	assert.Equal(t, []string{"PSA_IOT"}, tenant.Schemes)
+
+	tenants, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, tenants, 2)
+	assert.Equal(t, "0", tenants[0].ID)
+	assert.Equal(t, "acme", tenants[1].ID)
+
+	require.NoError(t, store.Del("acme"))
+	assert.ErrorIs(t, store.Del("acme"), ErrNoTenant)
+
+	tenants, err = store.List()
+	require.NoError(t, err)
+	assert.Len(t, tenants, 1)
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrBadTenant = errors.New("bad tenant")
+
+const (
+	// StateActive is the state of a tenant whose requests are accepted by
+	// the services.
+	StateActive = "active"
+	// StateSuspended is the state of a tenant whose requests are rejected
+	// by the services. Its data is retained.
+	StateSuspended = "suspended"
+)
+
+// Quotas are the limits on a tenant's use of the services. A zero value means
+// that the corresponding quota is not limited.
+type Quotas struct {
+	// RequestsPerMinute is the maximum number of API requests the tenant
+	// may make per minute.
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+
+	// RequestsPerDay is the maximum number of API requests the tenant may
+	// make per (UTC) day.
+	RequestsPerDay int `json:"requests_per_day,omitempty"`
+}
+
+// Tenant is the registry entry for a tenant of the services.
+type Tenant struct {
+	// ID is the tenant ID, as used to partition the stores.
+	ID string `json:"id"`
+
+	// Name is a human-readable name for the tenant.
+	Name string `json:"name,omitempty"`
+
+	// State is the state of the tenant; either StateActive or
+	// StateSuspended.
+	State string `json:"state"`
+
+	// Schemes are the names of the attestation schemes enabled for the
+	// tenant. If empty, all schemes supported by the deployment are
+	// enabled.
+	Schemes []string `json:"schemes,omitempty"`
+
+	// Quotas are the limits on the tenant's use of the services.
+	Quotas Quotas `json:"quotas"`
+
+	// DefaultPolicy is the name of the policy evaluated for the tenant's
+	// appraisals when a policy chain has not been set. If empty, the
+	// policy named after the policy engine is used.
+	DefaultPolicy string `json:"default_policy,omitempty"`
+
+	// CTime is the time the tenant was created.
+	CTime time.Time `json:"ctime"`
+
+	// MTime is the time the tenant was last updated.
+	MTime time.Time `json:"mtime"`
+
+	// CreatedBy identifies the principal (as reported by the authorizer)
+	// that created the tenant.
+	CreatedBy string `json:"created_by,omitempty"`
+
+	// UpdatedBy identifies the principal (as reported by the authorizer)
+	// that last updated the tenant.
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+// Validate returns an error wrapping ErrBadTenant if the tenant is invalid.
+func (o *Tenant) Validate() error {
+	if o.ID == "" {
+		return fmt.Errorf("%w: ID not set", ErrBadTenant)
+	}
+
+	// tenant IDs form part of the keys and labels under which the tenant's
+	// data is stored (e.g. "<tenant>:<scheme>:<name>" for policies, and
+	// "<tenant>/<scheme>" for endorsements)
+	if strings.ContainsAny(o.ID, ":/") {
+		return fmt.Errorf("%w: ID %q may not contain ':' or '/'", ErrBadTenant, o.ID)
+	}
+
+	switch o.State {
+	case StateActive, StateSuspended:
+	default:
+		return fmt.Errorf("%w: unexpected state %q", ErrBadTenant, o.State)
+	}
+
+	if o.Quotas.RequestsPerMinute < 0 {
+		return fmt.Errorf("%w: requests_per_minute quota may not be negative", ErrBadTenant)
+	}
+
+	if o.Quotas.RequestsPerDay < 0 {
+		return fmt.Errorf("%w: requests_per_day quota may not be negative", ErrBadTenant)
+	}
+
+	return nil
+}
+
+// IsActive returns true iff requests for the tenant should be accepted.
+func (o *Tenant) IsActive() bool {
+	return o.State == StateActive
+}
+
+// IsSchemeEnabled returns true iff the specified scheme is enabled for the
+// tenant.
+func (o *Tenant) IsSchemeEnabled(scheme string) bool {
+	if len(o.Schemes) == 0 {
+		return true
+	}
+
+	for _, enabled := range o.Schemes {
+		if enabled == scheme {
+			return true
+		}
+	}
+
+	return false
+}
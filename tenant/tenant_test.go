@@ -0,0 +1,41 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package tenant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tenant_Validate(t *testing.T) {
+	tenant := Tenant{ID: "acme", State: StateActive}
+	assert.NoError(t, tenant.Validate())
+
+	tenant = Tenant{State: StateActive}
+	assert.EqualError(t, tenant.Validate(), "bad tenant: ID not set")
+
+	tenant = Tenant{ID: "acme:1", State: StateActive}
+	assert.ErrorIs(t, tenant.Validate(), ErrBadTenant)
+
+	tenant = Tenant{ID: "acme/1", State: StateActive}
+	assert.ErrorIs(t, tenant.Validate(), ErrBadTenant)
+
+	tenant = Tenant{ID: "acme", State: "deleted"}
+	assert.EqualError(t, tenant.Validate(), `bad tenant: unexpected state "deleted"`)
+
+	tenant = Tenant{ID: "acme", State: StateActive, Quotas: Quotas{RequestsPerMinute: -1}}
+	assert.ErrorIs(t, tenant.Validate(), ErrBadTenant)
+
+	tenant = Tenant{ID: "acme", State: StateActive, Quotas: Quotas{RequestsPerDay: -1}}
+	assert.ErrorIs(t, tenant.Validate(), ErrBadTenant)
+}
+
+func Test_Tenant_IsSchemeEnabled(t *testing.T) {
+	tenant := Tenant{ID: "acme"}
+	assert.True(t, tenant.IsSchemeEnabled("PSA_IOT"))
+
+	tenant.Schemes = []string{"ARM_CCA", "PSA_IOT"}
+	assert.True(t, tenant.IsSchemeEnabled("PSA_IOT"))
+	assert.False(t, tenant.IsSchemeEnabled("TPM_ENACTTRUST"))
+}
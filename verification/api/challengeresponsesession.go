@@ -103,13 +103,17 @@ func (o *nonce) UnmarshalJSON(b []byte) error {
 }
 
 type ChallengeResponseSession struct {
-	id       string
-	Status   Status        `json:"status"`
-	Nonce    nonce         `json:"nonce"`
-	Expiry   time.Time     `json:"expiry"`
-	Accept   []string      `json:"accept"`
-	Evidence *EvidenceBlob `json:"evidence,omitempty"`
-	Result   *string       `json:"result,omitempty"`
+	id     string
+	Status Status    `json:"status"`
+	Nonce  nonce     `json:"nonce"`
+	Expiry time.Time `json:"expiry"`
+	Accept []string  `json:"accept"`
+	// Context is the (JSON object) appraisal context supplied by the
+	// relying party when creating the session. It is made available to
+	// the policy evaluating the evidence.
+	Context  json.RawMessage `json:"context,omitempty"`
+	Evidence *EvidenceBlob   `json:"evidence,omitempty"`
+	Result   *string         `json:"result,omitempty"`
 }
 
 func (o *ChallengeResponseSession) SetEvidence(mt string, evidence []byte) {
@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"mime"
 	"net/http"
 	"path"
 	"strconv"
@@ -19,6 +19,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/veraison/cmw"
+	"github.com/veraison/services/bodylimit"
 	"github.com/veraison/services/capability"
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/verification/sessionmanager"
@@ -28,6 +29,7 @@ import (
 
 const (
 	ChallengeResponseSessionMediaType = "application/vnd.veraison.challenge-response-session+json"
+	SessionContextMediaType           = "application/json"
 )
 
 var (
@@ -35,8 +37,12 @@ var (
 )
 
 var (
-	tenantID           = "0"
+	defaultTenantID    = "0"
 	defaultCacheMaxAge = 60 * time.Second
+
+	// DefaultMaxBodySize is the maximum size of request bodies (i.e.
+	// evidence and session contexts), unless configured otherwise.
+	DefaultMaxBodySize int64 = 4 << 20
 )
 
 type IHandler interface {
@@ -50,17 +56,30 @@ type IHandler interface {
 type Handler struct {
 	SessionManager sessionmanager.ISessionManager
 	Verifier       verifier.IVerifier
+	BodyLimits     *bodylimit.Limits
 
 	WkCacheMaxAge time.Duration
 	logger        *zap.SugaredLogger
 }
 
-func NewHandler(sm sessionmanager.ISessionManager, v verifier.IVerifier, wkCacheMaxAge string) IHandler {
+// NewHandler returns a new handler for the verification API. If bodyLimits is
+// nil, request bodies are limited to DefaultMaxBodySize.
+func NewHandler(
+	sm sessionmanager.ISessionManager,
+	v verifier.IVerifier,
+	wkCacheMaxAge string,
+	bodyLimits *bodylimit.Limits,
+) IHandler {
 	logger := log.Named("api-handler")
 
+	if bodyLimits == nil {
+		bodyLimits = bodylimit.New(DefaultMaxBodySize)
+	}
+
 	return &Handler{
 		SessionManager: sm,
 		Verifier:       v,
+		BodyLimits:     bodyLimits,
 		WkCacheMaxAge:  capability.ParseCacheMaxAge(wkCacheMaxAge, defaultCacheMaxAge, logger),
 		logger:         logger,
 	}
@@ -158,7 +177,12 @@ func parseNonceRequest(nonceParam, nonceSizeParam string) ([]byte, error) {
 	return nonce, nil
 }
 
-func newSession(sessionNonce nonce, supportedMediaTypes []string, ttl time.Duration) (uuid.UUID, []byte, error) {
+func newSession(
+	sessionNonce nonce,
+	supportedMediaTypes []string,
+	sessionContext json.RawMessage,
+	ttl time.Duration,
+) (uuid.UUID, []byte, error) {
 	id, err := mintSessionID()
 	if err != nil {
 		return uuid.UUID{}, nil, err
@@ -170,6 +194,8 @@ func newSession(sessionNonce nonce, supportedMediaTypes []string, ttl time.Durat
 		Nonce:  sessionNonce,
 		Expiry: time.Now().Add(ttl), // RFC3339 format, with sub-second precision added if present
 		Accept: supportedMediaTypes,
+		// the appraisal context supplied by the relying party (if any)
+		Context: sessionContext,
 	}
 
 	jsonSession, err := json.Marshal(session)
@@ -231,6 +257,8 @@ func readSessionIDFromRequestURI(c *gin.Context) (uuid.UUID, error) {
 }
 
 func (o *Handler) GetSession(c *gin.Context) {
+	tenantID := getTenantID(c)
+
 	// do content negotiation (accept application/vnd.veraison.challenge-response-session+json)
 	offered := c.NegotiateFormat(ChallengeResponseSessionMediaType)
 	if offered != ChallengeResponseSessionMediaType {
@@ -265,6 +293,8 @@ func (o *Handler) GetSession(c *gin.Context) {
 }
 
 func (o *Handler) DelSession(c *gin.Context) {
+	tenantID := getTenantID(c)
+
 	id, err := readSessionIDFromRequestURI(c)
 	if err != nil {
 		ReportProblem(c,
@@ -300,6 +330,8 @@ func isCMW(mt string) bool {
 }
 
 func (o *Handler) SubmitEvidence(c *gin.Context) {
+	tenantID := getTenantID(c)
+
 	// do content negotiation (accept application/vnd.veraison.challenge-response-session+json)
 	offered := c.NegotiateFormat(ChallengeResponseSessionMediaType)
 	if offered != ChallengeResponseSessionMediaType {
@@ -310,8 +342,18 @@ func (o *Handler) SubmitEvidence(c *gin.Context) {
 		return
 	}
 
+	// read content-type and check against supported attestation formats
+	mediaType := c.Request.Header.Get("Content-Type")
+
 	// read body (i.e., evidence)
-	evidence, err := io.ReadAll(c.Request.Body)
+	evidence, err := o.BodyLimits.ReadBody(c.Writer, c.Request, mediaType)
+	if errors.Is(err, bodylimit.ErrTooLarge) {
+		ReportProblem(c,
+			http.StatusRequestEntityTooLarge,
+			err.Error(),
+		)
+		return
+	}
 	if err != nil || len(evidence) == 0 {
 		o.logger.Error("unable to read evidence from the request body: %v", err)
 		ReportProblem(c,
@@ -321,9 +363,6 @@ func (o *Handler) SubmitEvidence(c *gin.Context) {
 		return
 	}
 
-	// read content-type and check against supported attestation formats
-	mediaType := c.Request.Header.Get("Content-Type")
-
 	if isCMW(mediaType) {
 		var w cmw.CMW
 
@@ -410,8 +449,8 @@ func (o *Handler) SubmitEvidence(c *gin.Context) {
 	// reported if something in the verifier or the connection goes wrong.
 	// Any problems with the evidence are expected to be reported via the
 	// attestation result.
-	attestationResult, err := o.Verifier.ProcessEvidence(tenantID, session.Nonce,
-		evidence, mediaType)
+	attestationResult, err := o.Verifier.ProcessEvidence(tenantID, id.String(),
+		session.Nonce, evidence, mediaType, session.Context)
 	if err != nil {
 		o.logger.Error(err)
 		session.SetStatus(StatusFailed)
@@ -444,6 +483,8 @@ func (o *Handler) SubmitEvidence(c *gin.Context) {
 }
 
 func (o *Handler) NewChallengeResponse(c *gin.Context) {
+	tenantID := getTenantID(c)
+
 	offered := c.NegotiateFormat(ChallengeResponseSessionMediaType)
 	if offered != ChallengeResponseSessionMediaType {
 		ReportProblem(c,
@@ -469,6 +510,15 @@ func (o *Handler) NewChallengeResponse(c *gin.Context) {
 		return
 	}
 
+	sessionContext, status, err := readSessionContext(c, o.BodyLimits)
+	if err != nil {
+		ReportProblem(c,
+			status,
+			fmt.Sprintf("failed handling session context: %s", err),
+		)
+		return
+	}
+
 	supportedMediaTypes, err := o.Verifier.SupportedMediaTypes()
 	if err != nil {
 		ReportProblem(c,
@@ -478,7 +528,8 @@ func (o *Handler) NewChallengeResponse(c *gin.Context) {
 		return
 	}
 
-	id, session, err := newSession(nonce(sessionNonce), supportedMediaTypes, ConfigSessionTTL)
+	id, session, err := newSession(nonce(sessionNonce), supportedMediaTypes,
+		sessionContext, ConfigSessionTTL)
 	if err != nil {
 		ReportProblem(c,
 			http.StatusInternalServerError,
@@ -499,6 +550,41 @@ func (o *Handler) NewChallengeResponse(c *gin.Context) {
 	sendChallengeResponseSessionCreated(c, id.String(), session)
 }
 
+// readSessionContext reads the optional appraisal context supplied by the
+// relying party in the body of a new session request. The context must be a
+// JSON object. If it cannot be read, the HTTP status that should be reported
+// is returned alongside the error.
+func readSessionContext(c *gin.Context, limits *bodylimit.Limits) (json.RawMessage, int, error) {
+	body, err := limits.ReadBody(c.Writer, c.Request, SessionContextMediaType)
+	if errors.Is(err, bodylimit.ErrTooLarge) {
+		return nil, http.StatusRequestEntityTooLarge, err
+	} else if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if len(body) == 0 {
+		return nil, 0, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if err != nil || mediaType != SessionContextMediaType {
+		return nil, http.StatusUnsupportedMediaType,
+			fmt.Errorf("the only supported context format is %s", SessionContextMediaType)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, http.StatusBadRequest, errors.New("context must be a JSON object")
+	}
+
+	if _, ok := fields["nonce"]; ok {
+		return nil, http.StatusBadRequest,
+			errors.New(`"nonce" is reserved and cannot be set in the context`)
+	}
+
+	return json.RawMessage(body), 0, nil
+}
+
 func sendChallengeResponseSessionWithStatus(c *gin.Context, status int, jsonSession []byte) {
 	c.Data(status, ChallengeResponseSessionMediaType, jsonSession)
 }
@@ -20,11 +20,17 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/moogar0880/problems"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/veraison/cmw"
+	"github.com/veraison/services/auth"
+	"github.com/veraison/services/bodylimit"
 	"github.com/veraison/services/capability"
+	"github.com/veraison/services/log"
 	"github.com/veraison/services/proto"
+	"github.com/veraison/services/ratelimit"
+	"github.com/veraison/services/tenant"
 	mock_deps "github.com/veraison/services/verification/api/mocks"
 )
 
@@ -54,6 +60,7 @@ var (
 		"application/psa-attestation-token"
 	]
 }`
+	testAuthorizer    = auth.NewPassthroughAuthorizer(log.Named("test"))
 	testFailedProblem = `{
 	"type": "about:blank",
 	"title": "Internal Server Error",
@@ -146,7 +153,7 @@ func TestHandler_NewChallengeResponse_UnsupportedAccept(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPost, "/challenge-response/v1/newSession", http.NoBody)
 	req.Header.Set("Accept", "application/unsupported+ber")
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -156,6 +163,78 @@ func TestHandler_NewChallengeResponse_UnsupportedAccept(t *testing.T) {
 	assert.Equal(t, expectedBody, body)
 }
 
+func TestHandler_NewChallengeResponse_SuspendedTenant(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	store, err := tenant.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = store.Add(&tenant.Tenant{ID: defaultTenantID, State: tenant.StateSuspended}, "")
+	require.NoError(t, err)
+
+	h := &Handler{}
+
+	expectedCode := http.StatusForbidden
+	expectedBody := problems.DefaultProblem{
+		Type:   "about:blank",
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: fmt.Sprintf("tenant %q is suspended", defaultTenantID),
+	}
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(http.MethodPost, "/challenge-response/v1/newSession", http.NoBody)
+	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+
+	NewRouter(h, testAuthorizer, tenant.NewChecker(store, log.Named("test")), nil).ServeHTTP(w, req)
+
+	var body problems.DefaultProblem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, expectedCode, w.Code)
+	assert.Equal(t, expectedBody, body)
+}
+
+func TestHandler_NewChallengeResponse_RateLimited(t *testing.T) {
+	store := ratelimit.NewTTLCache()
+	require.NoError(t, store.Init(nil))
+	defer store.Close()
+
+	limiter := ratelimit.NewLimiter(store, ratelimit.Limits{RequestsPerMinute: 1},
+		ratelimit.Limits{}, nil, log.Named("test"))
+
+	h := &Handler{}
+
+	expectedBody := problems.DefaultProblem{
+		Type:   "about:blank",
+		Title:  "Too Many Requests",
+		Status: http.StatusTooManyRequests,
+		Detail: fmt.Sprintf("rate limit exceeded for tenant %q", defaultTenantID),
+	}
+
+	router := NewRouter(h, testAuthorizer, nil, limiter)
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+
+		req, _ := http.NewRequest(http.MethodPost, "/challenge-response/v1/newSession", http.NoBody)
+		req.Header.Set("Accept", "application/unsupported+ber")
+
+		router.ServeHTTP(w, req)
+	}
+
+	var body problems.DefaultProblem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Result().Header.Get("Retry-After"))
+	assert.Equal(t, expectedBody, body)
+}
+
 func testHandler_NewChallengeResponse_BadNonce(t *testing.T, queryParams url.Values, expectedErr string) {
 	h := &Handler{}
 
@@ -174,7 +253,7 @@ func testHandler_NewChallengeResponse_BadNonce(t *testing.T, queryParams url.Val
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.URL.RawQuery = queryParams.Encode()
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -227,7 +306,7 @@ func TestHandler_NewChallengeResponse_NoNonceParameters(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		SetSession(gomock.Any(), tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(gomock.Any(), defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -235,7 +314,7 @@ func TestHandler_NewChallengeResponse_NoNonceParameters(t *testing.T) {
 		SupportedMediaTypes().
 		Return(testSupportedMediaTypes, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	expectedCode := http.StatusCreated
 	expectedType := ChallengeResponseSessionMediaType
@@ -247,7 +326,7 @@ func TestHandler_NewChallengeResponse_NoNonceParameters(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPost, "/challenge-response/v1/newSession", http.NoBody)
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body ChallengeResponseSession
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -267,7 +346,7 @@ func TestHandler_NewChallengeResponse_NonceParameter(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		SetSession(gomock.Any(), tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(gomock.Any(), defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -275,7 +354,7 @@ func TestHandler_NewChallengeResponse_NonceParameter(t *testing.T) {
 		SupportedMediaTypes().
 		Return(testSupportedMediaTypes, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	expectedCode := http.StatusCreated
 	expectedType := ChallengeResponseSessionMediaType
@@ -293,7 +372,7 @@ func TestHandler_NewChallengeResponse_NonceParameter(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.URL.RawQuery = qParams.Encode()
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body ChallengeResponseSession
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -314,7 +393,7 @@ func TestHandler_NewChallengeResponse_NonceSizeParameter(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		SetSession(gomock.Any(), tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(gomock.Any(), defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -322,7 +401,7 @@ func TestHandler_NewChallengeResponse_NonceSizeParameter(t *testing.T) {
 		SupportedMediaTypes().
 		Return(testSupportedMediaTypes, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	expectedCode := http.StatusCreated
 	expectedType := ChallengeResponseSessionMediaType
@@ -339,7 +418,7 @@ func TestHandler_NewChallengeResponse_NonceSizeParameter(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.URL.RawQuery = qParams.Encode()
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body ChallengeResponseSession
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -354,6 +433,101 @@ func TestHandler_NewChallengeResponse_NonceSizeParameter(t *testing.T) {
 	assert.Equal(t, expectedSessionStatus, body.Status)
 }
 
+func TestHandler_NewChallengeResponse_Context(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionContext := `{"expected-instance-id": "AQID", "min-tcb": 3}`
+
+	var stored []byte
+	sm := mock_deps.NewMockISessionManager(ctrl)
+	sm.EXPECT().
+		SetSession(gomock.Any(), defaultTenantID, gomock.Any(), ConfigSessionTTL).
+		Do(func(_ uuid.UUID, _ string, session []byte, _ any) { stored = session }).
+		Return(nil)
+
+	v := mock_deps.NewMockIVerifier(ctrl)
+	v.EXPECT().
+		SupportedMediaTypes().
+		Return(testSupportedMediaTypes, nil)
+
+	h := NewHandler(sm, v, "1h", nil)
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(http.MethodPost, testNewSessionURL,
+		strings.NewReader(sessionContext))
+	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.URL.RawQuery = "nonceSize=32"
+
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
+
+	var body ChallengeResponseSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, sessionContext, string(body.Context))
+
+	var storedSession ChallengeResponseSession
+	require.NoError(t, json.Unmarshal(stored, &storedSession))
+	assert.JSONEq(t, sessionContext, string(storedSession.Context))
+}
+
+func TestHandler_NewChallengeResponse_BadContext(t *testing.T) {
+	vectors := []struct {
+		Name           string
+		ContentType    string
+		Body           string
+		ExpectedCode   int
+		ExpectedDetail string
+	}{
+		{
+			"bad content type", "text/plain", `{"k": "v"}`,
+			http.StatusUnsupportedMediaType,
+			"failed handling session context: the only supported context format is application/json",
+		},
+		{
+			"not an object", "application/json", `["k", "v"]`,
+			http.StatusBadRequest,
+			"failed handling session context: context must be a JSON object",
+		},
+		{
+			"reserved field", "application/json", `{"nonce": "AQID"}`,
+			http.StatusBadRequest,
+			`failed handling session context: "nonce" is reserved and cannot be set in the context`,
+		},
+	}
+
+	for _, tv := range vectors {
+		t.Run(tv.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sm := mock_deps.NewMockISessionManager(ctrl)
+			v := mock_deps.NewMockIVerifier(ctrl)
+
+			h := NewHandler(sm, v, "1h", nil)
+
+			w := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(http.MethodPost, testNewSessionURL,
+				strings.NewReader(tv.Body))
+			req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+			req.Header.Set("Content-Type", tv.ContentType)
+			req.URL.RawQuery = "nonceSize=32"
+
+			NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
+
+			var body problems.DefaultProblem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			assert.Equal(t, tv.ExpectedCode, w.Code)
+			assert.Equal(t, tv.ExpectedDetail, body.Detail)
+		})
+	}
+}
+
 func TestHandler_NewChallengeResponse_SetSessionFailure(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -371,7 +545,7 @@ func TestHandler_NewChallengeResponse_SetSessionFailure(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		SetSession(gomock.Any(), tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(gomock.Any(), defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(errors.New(sessionManagerError))
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -379,7 +553,7 @@ func TestHandler_NewChallengeResponse_SetSessionFailure(t *testing.T) {
 		SupportedMediaTypes().
 		Return(testSupportedMediaTypes, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	qParams := url.Values{}
 	qParams.Add("nonceSize", "32")
@@ -390,7 +564,7 @@ func TestHandler_NewChallengeResponse_SetSessionFailure(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.URL.RawQuery = qParams.Encode()
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -419,7 +593,7 @@ func testHandler_UnsupportedAccept(t *testing.T, method string) {
 	req, _ := http.NewRequest(method, url, http.NoBody)
 	req.Header.Set("Accept", "application/unsupported+ber")
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -460,7 +634,7 @@ func TestHandler_SubmitEvidence_unsupported_evidence_format(t *testing.T) {
 		IsSupportedMediaType(testUnsupportedMediaType).
 		Return(false, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -468,7 +642,7 @@ func TestHandler_SubmitEvidence_unsupported_evidence_format(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testUnsupportedMediaType)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -501,7 +675,7 @@ func TestHandler_SubmitEvidence_bad_session_id_url(t *testing.T) {
 		IsSupportedMediaType(testSupportedMediaTypeA).
 		Return(true, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -509,7 +683,7 @@ func TestHandler_SubmitEvidence_bad_session_id_url(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -538,7 +712,7 @@ func TestHandler_SubmitEvidence_session_not_found(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return(nil, errors.New(smErr))
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -546,7 +720,7 @@ func TestHandler_SubmitEvidence_session_not_found(t *testing.T) {
 		IsSupportedMediaType(testSupportedMediaTypeA).
 		Return(true, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -554,7 +728,7 @@ func TestHandler_SubmitEvidence_session_not_found(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -581,7 +755,7 @@ func TestHandler_SubmitEvidence_no_body(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	v := mock_deps.NewMockIVerifier(ctrl)
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -589,7 +763,7 @@ func TestHandler_SubmitEvidence_no_body(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -599,6 +773,41 @@ func TestHandler_SubmitEvidence_no_body(t *testing.T) {
 	assert.Equal(t, expectedBody, body)
 }
 
+func TestHandler_SubmitEvidence_too_large(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expectedCode := http.StatusRequestEntityTooLarge
+	expectedBody := problems.DefaultProblem{
+		Type:   "about:blank",
+		Title:  "Request Entity Too Large",
+		Status: http.StatusRequestEntityTooLarge,
+		Detail: "request body too large: the maximum size is 4 bytes",
+	}
+
+	limits := bodylimit.New(1024)
+	require.NoError(t, limits.Set(testSupportedMediaTypeA, 4))
+
+	sm := mock_deps.NewMockISessionManager(ctrl)
+	v := mock_deps.NewMockIVerifier(ctrl)
+	h := NewHandler(sm, v, "1h", limits)
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(http.MethodPost, path.Join(testSessionBaseURL, testUUIDString),
+		strings.NewReader(testJSONBody))
+	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+	req.Header.Set("Content-Type", testSupportedMediaTypeA)
+
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
+
+	var body problems.DefaultProblem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, expectedCode, w.Code)
+	assert.Equal(t, expectedBody, body)
+}
+
 func TestHandler_SubmitEvidence_process_evidence_failed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -613,13 +822,13 @@ func TestHandler_SubmitEvidence_process_evidence_failed(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return([]byte(testSession), nil)
 	// we cannot assert on the serialised session object (=> gomock.Any()), but
 	// it's not a problem because this is going to be checked anyway when
 	// matching the response body
 	sm.EXPECT().
-		SetSession(testUUID, tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(testUUID, defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -627,10 +836,10 @@ func TestHandler_SubmitEvidence_process_evidence_failed(t *testing.T) {
 		IsSupportedMediaType(testSupportedMediaTypeA).
 		Return(true, nil)
 	v.EXPECT().
-		ProcessEvidence(tenantID, testNonce, []byte(testJSONBody), testSupportedMediaTypeA).
+		ProcessEvidence(defaultTenantID, testUUIDString, testNonce, []byte(testJSONBody), testSupportedMediaTypeA, nil).
 		Return(nil, errors.New(vmErr))
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -638,7 +847,7 @@ func TestHandler_SubmitEvidence_process_evidence_failed(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	body := w.Body.Bytes()
 
@@ -659,10 +868,10 @@ func TestHandler_SubmitEvidence_process_ok_sync(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return([]byte(testSession), nil)
 	sm.EXPECT().
-		SetSession(testUUID, tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(testUUID, defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -670,10 +879,10 @@ func TestHandler_SubmitEvidence_process_ok_sync(t *testing.T) {
 		IsSupportedMediaType(testSupportedMediaTypeA).
 		Return(true, nil)
 	v.EXPECT().
-		ProcessEvidence(tenantID, testNonce, []byte(testJSONBody), testSupportedMediaTypeA).
+		ProcessEvidence(defaultTenantID, testUUIDString, testNonce, []byte(testJSONBody), testSupportedMediaTypeA, nil).
 		Return([]byte(testResult), nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -681,7 +890,7 @@ func TestHandler_SubmitEvidence_process_ok_sync(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	body := w.Body.Bytes()
 
@@ -690,6 +899,45 @@ func TestHandler_SubmitEvidence_process_ok_sync(t *testing.T) {
 	assert.JSONEq(t, expectedBody, string(body))
 }
 
+func TestHandler_SubmitEvidence_forwards_context(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pathOK := path.Join(testSessionBaseURL, testUUIDString)
+	sessionContext := `{"min-tcb":3}`
+	sessionWithContext := strings.Replace(testSession, `"status": "waiting",`,
+		`"status": "waiting", "context": `+sessionContext+`,`, 1)
+
+	sm := mock_deps.NewMockISessionManager(ctrl)
+	sm.EXPECT().
+		GetSession(testUUID, defaultTenantID).
+		Return([]byte(sessionWithContext), nil)
+	sm.EXPECT().
+		SetSession(testUUID, defaultTenantID, gomock.Any(), ConfigSessionTTL).
+		Return(nil)
+
+	v := mock_deps.NewMockIVerifier(ctrl)
+	v.EXPECT().
+		IsSupportedMediaType(testSupportedMediaTypeA).
+		Return(true, nil)
+	v.EXPECT().
+		ProcessEvidence(defaultTenantID, testUUIDString, testNonce, []byte(testJSONBody), testSupportedMediaTypeA,
+			[]byte(sessionContext)).
+		Return([]byte(testResult), nil)
+
+	h := NewHandler(sm, v, "1h", nil)
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(http.MethodPost, pathOK, strings.NewReader(testJSONBody))
+	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+	req.Header.Set("Content-Type", testSupportedMediaTypeA)
+
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 func TestHandler_SubmitEvidence_process_ok_async(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -702,10 +950,10 @@ func TestHandler_SubmitEvidence_process_ok_async(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return([]byte(testSession), nil)
 	sm.EXPECT().
-		SetSession(testUUID, tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(testUUID, defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -713,10 +961,10 @@ func TestHandler_SubmitEvidence_process_ok_async(t *testing.T) {
 		IsSupportedMediaType(testSupportedMediaTypeA).
 		Return(true, nil)
 	v.EXPECT().
-		ProcessEvidence(tenantID, testNonce, []byte(testJSONBody), testSupportedMediaTypeA).
+		ProcessEvidence(defaultTenantID, testUUIDString, testNonce, []byte(testJSONBody), testSupportedMediaTypeA, nil).
 		Return(nil, nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -724,7 +972,7 @@ func TestHandler_SubmitEvidence_process_ok_async(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	body := w.Body.Bytes()
 
@@ -755,7 +1003,7 @@ func TestHandler_GetSession_bad_session_id_url(t *testing.T) {
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -763,7 +1011,7 @@ func TestHandler_GetSession_bad_session_id_url(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -792,12 +1040,12 @@ func TestHandler_GetSession_session_not_found(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return(nil, errors.New(smErr))
 
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -805,7 +1053,7 @@ func TestHandler_GetSession_session_not_found(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -815,6 +1063,139 @@ func TestHandler_GetSession_session_not_found(t *testing.T) {
 	assert.Equal(t, expectedBody, body)
 }
 
+// tenantAuthorizer permits all requests, authenticating them as belonging to
+// the tenant.
+type tenantAuthorizer struct {
+	auth.IAuthorizer
+	tenantID string
+}
+
+func (o tenantAuthorizer) GetPermissionHandler(action string, getResource auth.ResourceGetter) gin.HandlerFunc {
+	permit := o.IAuthorizer.GetPermissionHandler(action, getResource)
+
+	return func(c *gin.Context) {
+		c.Set(auth.TenantKey, o.tenantID)
+		permit(c)
+	}
+}
+
+func TestHandler_GetSession_other_tenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	smErr := "session not found"
+
+	expectedCode := http.StatusNotFound
+	expectedBody := problems.DefaultProblem{
+		Type:   "about:blank",
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+		Detail: smErr,
+	}
+
+	// The session is looked up for the tenant of the principal, rather than
+	// the default tenant.
+	sm := mock_deps.NewMockISessionManager(ctrl)
+	sm.EXPECT().
+		GetSession(testUUID, "1").
+		Return(nil, errors.New(smErr))
+
+	v := mock_deps.NewMockIVerifier(ctrl)
+
+	h := NewHandler(sm, v, "1h", nil)
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(http.MethodGet, path.Join(testSessionBaseURL, testUUIDString), http.NoBody)
+	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+
+	authorizer := tenantAuthorizer{IAuthorizer: testAuthorizer, tenantID: "1"}
+	NewRouter(h, authorizer, nil, nil).ServeHTTP(w, req)
+
+	var body problems.DefaultProblem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, expectedCode, w.Code)
+	assert.Equal(t, expectedBody, body)
+}
+
+func TestHandler_NewChallengeResponse_NotPermitted(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "basic")
+	v.Set("users", map[string]any{
+		"user1": map[string]any{
+			// Passw0rd!
+			"password": "$2b$05$XgVBveh6QPrRHXI.8S/J9uobBR7Wv9z4CL8yACHEmKIQmYSSyKAqC",
+			"roles":    auth.ProvisionerRole,
+		},
+	})
+
+	authorizer, err := auth.NewAuthorizer(v, log.Named("auth"))
+	require.NoError(t, err)
+
+	h := &Handler{}
+
+	for _, tc := range []struct {
+		user         string
+		expectedCode int
+	}{
+		{user: "", expectedCode: http.StatusUnauthorized},
+		{user: "user1", expectedCode: http.StatusForbidden},
+	} {
+		w := httptest.NewRecorder()
+
+		req, _ := http.NewRequest(http.MethodPost, "/challenge-response/v1/newSession", http.NoBody)
+		req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+		if tc.user != "" {
+			req.SetBasicAuth(tc.user, "Passw0rd!")
+		}
+
+		NewRouter(h, authorizer, nil, nil).ServeHTTP(w, req)
+
+		assert.Equal(t, tc.expectedCode, w.Code, tc.user)
+	}
+}
+
+func TestHandler_NewChallengeResponse_NoTenant(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "basic")
+	v.Set("users", map[string]any{
+		"user1": map[string]any{
+			// Passw0rd!
+			"password": "$2b$05$XgVBveh6QPrRHXI.8S/J9uobBR7Wv9z4CL8yACHEmKIQmYSSyKAqC",
+			"roles":    auth.AttesterRole,
+		},
+	})
+
+	// the basic backend does not establish the tenant of the principal
+	authorizer, err := auth.NewAuthorizer(v, log.Named("auth"))
+	require.NoError(t, err)
+
+	h := &Handler{}
+
+	expectedCode := http.StatusForbidden
+	expectedBody := problems.DefaultProblem{
+		Type:   "about:blank",
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: "principal does not belong to a tenant",
+	}
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(http.MethodPost, "/challenge-response/v1/newSession", http.NoBody)
+	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
+	req.SetBasicAuth("user1", "Passw0rd!")
+
+	NewRouter(h, authorizer, nil, nil).ServeHTTP(w, req)
+
+	var body problems.DefaultProblem
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, expectedCode, w.Code)
+	assert.Equal(t, expectedBody, body)
+}
+
 func TestHandler_GetSession_ok(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -827,12 +1208,12 @@ func TestHandler_GetSession_ok(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return([]byte(testCompleteSession), nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -840,7 +1221,7 @@ func TestHandler_GetSession_ok(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", testSupportedMediaTypeA)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	body := w.Body.Bytes()
 
@@ -859,18 +1240,18 @@ func TestHandler_DelSession_ok(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		DelSession(testUUID, tenantID).
+		DelSession(testUUID, defaultTenantID).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodDelete, pathOK, http.NoBody)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	assert.Equal(t, expectedCode, w.Code)
 }
@@ -893,13 +1274,13 @@ func TestHandler_DelSession_bad_session_id(t *testing.T) {
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodDelete, badPath, http.NoBody)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -926,18 +1307,18 @@ func TestHandler_DelSession_session_id_does_not_exist(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		DelSession(testUUID, tenantID).
+		DelSession(testUUID, defaultTenantID).
 		Return(errors.New(`session id (` + testUUIDString + `) does not exist`))
 
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodDelete, pathOK, http.NoBody)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -975,14 +1356,14 @@ func TestHandler_GetWellKnownVerificationInfo_ok(t *testing.T) {
 		ApiEndpoints: publicApiMap,
 	}
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodGet, "/.well-known/veraison/verification", http.NoBody)
 	req.Header.Add("Accept", expectedType)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body capability.WellKnownInfo
 	bytes := w.Body.Bytes()
@@ -1008,13 +1389,13 @@ func TestHandler_GetWellKnownVerificationInfo_GetPublicKey_failure(t *testing.T)
 	expectedType := "application/problem+json"
 	expectedErrorTitle := "Internal Server Error"
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodGet, "/.well-known/veraison/verification", http.NoBody)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -1042,13 +1423,13 @@ func TestHandler_GetWellKnownVerificationInfo_Get_SupportedMediaTypes_fail(t *te
 	expectedType := "application/problem+json"
 	expectedErrorTitle := "Internal Server Error"
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodGet, "/.well-known/veraison/verification", http.NoBody)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -1080,13 +1461,13 @@ func TestHandler_GetWellKnownVerificationInfo_GetVTSState_fail(t *testing.T) {
 	expectedType := "application/problem+json"
 	expectedErrorTitle := "Internal Server Error"
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest(http.MethodGet, "/.well-known/veraison/verification", http.NoBody)
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -1114,7 +1495,7 @@ func TestHandler_GetWellKnownVerificationInfo_UnsupportedAccept(t *testing.T) {
 	g.Request, _ = http.NewRequest(http.MethodGet, "/.well-known/veraison/verification", http.NoBody)
 	g.Request.Header.Add("Accept", "application/unsupported+ber")
 
-	NewRouter(h).ServeHTTP(w, g.Request)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, g.Request)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
@@ -1145,10 +1526,10 @@ func TestHandler_SubmitEvidence_good_CMW(t *testing.T) {
 
 	sm := mock_deps.NewMockISessionManager(ctrl)
 	sm.EXPECT().
-		GetSession(testUUID, tenantID).
+		GetSession(testUUID, defaultTenantID).
 		Return([]byte(testSession), nil)
 	sm.EXPECT().
-		SetSession(testUUID, tenantID, gomock.Any(), ConfigSessionTTL).
+		SetSession(testUUID, defaultTenantID, gomock.Any(), ConfigSessionTTL).
 		Return(nil)
 
 	v := mock_deps.NewMockIVerifier(ctrl)
@@ -1156,10 +1537,10 @@ func TestHandler_SubmitEvidence_good_CMW(t *testing.T) {
 		IsSupportedMediaType(testSupportedMediaTypeA).
 		Return(true, nil)
 	v.EXPECT().
-		ProcessEvidence(tenantID, testNonce, []byte(testJSONBody), testSupportedMediaTypeA).
+		ProcessEvidence(defaultTenantID, testUUIDString, testNonce, []byte(testJSONBody), testSupportedMediaTypeA, nil).
 		Return([]byte(testResult), nil)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -1167,7 +1548,7 @@ func TestHandler_SubmitEvidence_good_CMW(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", "application/vnd.veraison.cmw")
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	_ = w.Body.Bytes()
 
@@ -1198,7 +1579,7 @@ func TestHandler_SubmitEvidence_bad_CMW(t *testing.T) {
 
 	v := mock_deps.NewMockIVerifier(ctrl)
 
-	h := NewHandler(sm, v, "1h")
+	h := NewHandler(sm, v, "1h", nil)
 
 	w := httptest.NewRecorder()
 
@@ -1206,7 +1587,7 @@ func TestHandler_SubmitEvidence_bad_CMW(t *testing.T) {
 	req.Header.Set("Accept", ChallengeResponseSessionMediaType)
 	req.Header.Set("Content-Type", "application/vnd.veraison.cmw")
 
-	NewRouter(h).ServeHTTP(w, req)
+	NewRouter(h, testAuthorizer, nil, nil).ServeHTTP(w, req)
 
 	var body problems.DefaultProblem
 	_ = json.Unmarshal(w.Body.Bytes(), &body)
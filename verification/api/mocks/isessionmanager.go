@@ -65,6 +65,20 @@ func (mr *MockISessionManagerMockRecorder) DelSession(id, tenant interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelSession", reflect.TypeOf((*MockISessionManager)(nil).DelSession), id, tenant)
 }
 
+// DelTenantSessions mocks base method.
+func (m *MockISessionManager) DelTenantSessions(tenant string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelTenantSessions", tenant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelTenantSessions indicates an expected call of DelTenantSessions.
+func (mr *MockISessionManagerMockRecorder) DelTenantSessions(tenant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelTenantSessions", reflect.TypeOf((*MockISessionManager)(nil).DelTenantSessions), tenant)
+}
+
 // GetSession mocks base method.
 func (m *MockISessionManager) GetSession(id uuid.UUID, tenant string) (json.RawMessage, error) {
 	m.ctrl.T.Helper()
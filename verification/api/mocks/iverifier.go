@@ -80,18 +80,18 @@ func (mr *MockIVerifierMockRecorder) IsSupportedMediaType(mt interface{}) *gomoc
 }
 
 // ProcessEvidence mocks base method.
-func (m *MockIVerifier) ProcessEvidence(tenantID string, nonce, data []byte, mt string) ([]byte, error) {
+func (m *MockIVerifier) ProcessEvidence(tenantID, sessionID string, nonce, data []byte, mt string, sessionContext []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessEvidence", tenantID, nonce, data, mt)
+	ret := m.ctrl.Call(m, "ProcessEvidence", tenantID, sessionID, nonce, data, mt, sessionContext)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ProcessEvidence indicates an expected call of ProcessEvidence.
-func (mr *MockIVerifierMockRecorder) ProcessEvidence(tenantID, nonce, data, mt interface{}) *gomock.Call {
+func (mr *MockIVerifierMockRecorder) ProcessEvidence(tenantID, sessionID, nonce, data, mt, sessionContext interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessEvidence", reflect.TypeOf((*MockIVerifier)(nil).ProcessEvidence), tenantID, nonce, data, mt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessEvidence", reflect.TypeOf((*MockIVerifier)(nil).ProcessEvidence), tenantID, sessionID, nonce, data, mt, sessionContext)
 }
 
 // SupportedMediaTypes mocks base method.
@@ -1,9 +1,14 @@
-// Copyright 2022-2023 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package api
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/veraison/services/auth"
+	"github.com/veraison/services/ratelimit"
+	"github.com/veraison/services/tenant"
 )
 
 var publicApiMap = make(map[string]string)
@@ -16,22 +21,71 @@ const (
 	getWellKnownVerificationInfoUrl = "/.well-known/veraison/verification"
 )
 
-func NewRouter(handler IHandler) *gin.Engine {
+// NewRouter returns the router for the verification API. Challenge-response
+// requests are only served for principals permitted to verify evidence by the
+// authorizer, and are scoped to the principal's tenant. Unless authentication
+// is disabled (i.e. the authorizer is a passthrough), principals must belong
+// to a tenant. If tenants is not nil, they are only served for registered
+// tenants that have not been suspended.
+// If limiter is not nil, they are subject to its rate limits and quotas.
+func NewRouter(
+	handler IHandler,
+	authorizer auth.IAuthorizer,
+	tenants *tenant.Checker,
+	limiter *ratelimit.Limiter,
+) *gin.Engine {
 	router := gin.New()
 
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
-	router.POST(newChallengeResponseSessionUrl, handler.NewChallengeResponse)
+	crGroup := router.Group("")
+	crGroup.Use(authorizer.GetPermissionHandler(auth.VerifyEvidenceAction, getTenantResource))
+	if _, ok := authorizer.(*auth.PassthroughAuthorizer); !ok {
+		crGroup.Use(requireTenant)
+	}
+	if tenants != nil {
+		crGroup.Use(tenants.GetGinHandler(getTenantID))
+	}
+	if limiter != nil {
+		limiter.SetTrustedProxies(router)
+		crGroup.Use(limiter.GetGinHandler(getTenantID))
+	}
+
+	crGroup.POST(newChallengeResponseSessionUrl, handler.NewChallengeResponse)
 	publicApiMap["newChallengeResponseSession"] = newChallengeResponseSessionUrl
 
-	router.POST(submitEvidenceUrl, handler.SubmitEvidence)
+	crGroup.POST(submitEvidenceUrl, handler.SubmitEvidence)
 
-	router.GET(getSessionUrl, handler.GetSession)
+	crGroup.GET(getSessionUrl, handler.GetSession)
 
-	router.DELETE(delSessionUrl, handler.DelSession)
+	crGroup.DELETE(delSessionUrl, handler.DelSession)
 
 	router.GET(getWellKnownVerificationInfoUrl, handler.GetWellKnownVerificationInfo)
 
 	return router
 }
+
+// requireTenant rejects requests for which the authorizer did not establish
+// the tenant of the principal, so that sessions of principals that do not
+// belong to a tenant are not lumped together in the default tenant.
+func requireTenant(c *gin.Context) {
+	if auth.GetTenant(c) == "" {
+		ReportProblem(c, http.StatusForbidden, "principal does not belong to a tenant")
+	}
+}
+
+// getTenantID returns the ID of the tenant a request is for: the tenant of the
+// authenticated principal, or the default tenant if authentication is disabled
+// (see requireTenant).
+func getTenantID(c *gin.Context) string {
+	if tenantID := auth.GetTenant(c); tenantID != "" {
+		return tenantID
+	}
+
+	return defaultTenantID
+}
+
+func getTenantResource(c *gin.Context) auth.Resource {
+	return auth.Resource{Tenant: getTenantID(c)}
+}
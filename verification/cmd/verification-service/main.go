@@ -5,10 +5,15 @@ package main
 import (
 	"context"
 	"errors"
+	"net/http"
 
+	"github.com/veraison/services/auth"
+	"github.com/veraison/services/bodylimit"
 	"github.com/veraison/services/config"
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/proto"
+	"github.com/veraison/services/ratelimit"
+	"github.com/veraison/services/tenant"
 	"github.com/veraison/services/verification/api"
 	"github.com/veraison/services/verification/sessionmanager"
 	"github.com/veraison/services/verification/verifier"
@@ -21,11 +26,13 @@ var (
 )
 
 type cfg struct {
-	ListenAddr      string `mapstructure:"listen-addr" valid:"dialstring"`
-	Protocol        string `mapstructure:"protocol" valid:"in(http|https)"`
-	Cert            string `mapstructure:"cert" config:"zerodefault"`
-	CertKey         string `mapstructure:"cert-key" config:"zerodefault"`
-	DiscoveryMaxAge string `mapstructure:"discovery-max-age" config:"zerodefault"`
+	ListenAddr      string           `mapstructure:"listen-addr" valid:"dialstring"`
+	Protocol        string           `mapstructure:"protocol" valid:"in(http|https)"`
+	Cert            string           `mapstructure:"cert" config:"zerodefault"`
+	CertKey         string           `mapstructure:"cert-key" config:"zerodefault"`
+	DiscoveryMaxAge string           `mapstructure:"discovery-max-age" config:"zerodefault"`
+	MaxBodySize     string           `mapstructure:"max-body-size" config:"zerodefault"`
+	MaxBodySizes    []map[string]any `mapstructure:"max-body-sizes" config:"zerodefault"`
 }
 
 func (o cfg) Validate() error {
@@ -54,7 +61,7 @@ func main() {
 	}
 
 	subs, err := config.GetSubs(v, "*vts", "*verifier", "*verification", "*logging",
-		"*sessionmanager")
+		"*sessionmanager", "*verification-auth")
 	if err != nil {
 		log.Fatalf("Could not read config: %v", err)
 	}
@@ -101,27 +108,102 @@ func main() {
 	log.Info("initializing verifier")
 	verifier := verifier.New(subs["verifier"], vtsClient)
 
-	apiHandler := api.NewHandler(sessionManager, verifier, cfg.DiscoveryMaxAge)
+	log.Info("initializing tenant checker")
+	tenants, err := tenant.NewCheckerFromConfig(v.Sub("tenant-store"), log.Named("tenant"))
+	if err != nil {
+		log.Fatalf("could not init tenant checker: %v", err)
+	}
+	if tenants != nil {
+		defer func() {
+			if err := tenants.Close(); err != nil {
+				log.Errorf("Could not close tenant store: %v", err)
+			}
+		}()
+
+		// the sessions of deleted tenants are not accessible to the
+		// management service, so they are purged here
+		tenants.OnUnknownTenant = sessionManager.DelTenantSessions
+	}
+
+	log.Info("initializing rate limiter")
+	limiter, err := ratelimit.NewLimiterFromConfig(v.Sub("rate-limit"),
+		tenants.GetStore(), log.Named("ratelimit"))
+	if err != nil {
+		log.Fatalf("could not init rate limiter: %v", err)
+	}
+	if limiter != nil {
+		defer func() {
+			if err := limiter.Close(); err != nil {
+				log.Errorf("Could not close rate limiter: %v", err)
+			}
+		}()
+	}
+
+	log.Info("initializing authorizer")
+	// The top-level auth config is shared with the provisioning and
+	// management services, and so is not used for verification, which
+	// authenticates a different set of principals (attesters and relying
+	// parties).
+	authorizer, err := auth.NewAuthorizer(subs["verification-auth"], log.Named("auth"))
+	if err != nil {
+		log.Fatalf("could not init authorizer: %v", err)
+	}
+	defer func() {
+		err := authorizer.Close()
+		if err != nil {
+			log.Errorf("Could not close authorizer: %v", err)
+		}
+	}()
+
+	if cfg.Protocol != "https" && auth.GetTLSConfig(authorizer) != nil {
+		log.Fatal(`the auth backend requires protocol to be "https"`)
+	}
+
+	bodyLimits, err := bodylimit.NewFromConfig(api.DefaultMaxBodySize,
+		cfg.MaxBodySize, cfg.MaxBodySizes)
+	if err != nil {
+		log.Fatalf("Could not load body size limits: %v", err)
+	}
+
+	apiHandler := api.NewHandler(sessionManager, verifier, cfg.DiscoveryMaxAge, bodyLimits)
 
 	if cfg.Protocol == "https" {
-		apiServerTLS(apiHandler, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
+		apiServerTLS(apiHandler, authorizer, tenants, limiter, cfg.ListenAddr, cfg.Cert, cfg.CertKey)
 	} else {
-		apiServer(apiHandler, cfg.ListenAddr)
+		apiServer(apiHandler, authorizer, tenants, limiter, cfg.ListenAddr)
 	}
 }
 
-func apiServer(apiHandler api.IHandler, listenAddr string) {
+func apiServer(
+	apiHandler api.IHandler,
+	authorizer auth.IAuthorizer,
+	tenants *tenant.Checker,
+	limiter *ratelimit.Limiter,
+	listenAddr string,
+) {
 	log.Infow("initializing verification API HTTP service", "address", listenAddr)
 
-	if err := api.NewRouter(apiHandler).Run(listenAddr); err != nil {
+	if err := api.NewRouter(apiHandler, authorizer, tenants, limiter).Run(listenAddr); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
 
-func apiServerTLS(apiHandler api.IHandler, listenAddr, certFile, keyFile string) {
+func apiServerTLS(
+	apiHandler api.IHandler,
+	authorizer auth.IAuthorizer,
+	tenants *tenant.Checker,
+	limiter *ratelimit.Limiter,
+	listenAddr, certFile, keyFile string,
+) {
 	log.Infow("initializing verification API HTTPS service", "address", listenAddr)
 
-	if err := api.NewRouter(apiHandler).RunTLS(listenAddr, certFile, keyFile); err != nil {
+	server := &http.Server{
+		Addr:      listenAddr,
+		Handler:   api.NewRouter(apiHandler, authorizer, tenants, limiter),
+		TLSConfig: auth.GetTLSConfig(authorizer),
+	}
+
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil {
 		log.Fatalf("Gin engine failed: %v", err)
 	}
 }
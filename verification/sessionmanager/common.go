@@ -1,9 +1,10 @@
-// Copyright 2025 Contributors to the Veraison project.
+// Copyright 2025-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package sessionmanager
 
 import (
 	"net/url"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -18,3 +19,9 @@ func makeKey(id uuid.UUID, tenant string) string {
 
 	return u.String()
 }
+
+// makeTenantPrefix returns the prefix of the keys of all sessions of the
+// tenant (see makeKey).
+func makeTenantPrefix(tenant string) string {
+	return strings.TrimSuffix(makeKey(uuid.Nil, tenant), uuid.Nil.String())
+}
@@ -1,4 +1,4 @@
-// Copyright 2022-2025 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package sessionmanager
 
@@ -15,5 +15,7 @@ type ISessionManager interface {
 	SetSession(id uuid.UUID, tenant string, session json.RawMessage, ttl time.Duration) error
 	GetSession(id uuid.UUID, tenant string) (json.RawMessage, error)
 	DelSession(id uuid.UUID, tenant string) error
+	// DelTenantSessions removes all sessions of the specified tenant.
+	DelTenantSessions(tenant string) error
 	Close() error
 }
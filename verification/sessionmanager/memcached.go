@@ -4,7 +4,11 @@ package sessionmanager
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bradfitz/gomemcache/memcache"
@@ -19,6 +23,10 @@ type memcachedConfig struct {
 	Servers []string `mapstructure:"servers"`
 }
 
+// Memcached keeps the sessions in memcached. As memcached does not support
+// enumerating keys, the sessions of a tenant are removed by bumping the
+// tenant's generation, which is recorded in the keys of its sessions, so that
+// the sessions of previous generations can no longer be accessed, and expire.
 type Memcached struct {
 	client *memcache.Client
 }
@@ -54,8 +62,13 @@ func (o *Memcached) SetSession(
 	session json.RawMessage,
 	ttl time.Duration,
 ) error {
+	key, err := o.makeKey(id, tenant)
+	if err != nil {
+		return err
+	}
+
 	item := &memcache.Item{
-		Key:        makeKey(id, tenant),
+		Key:        key,
 		Value:      session,
 		Expiration: int32(ttl.Seconds()),
 	}
@@ -63,11 +76,41 @@ func (o *Memcached) SetSession(
 }
 
 func (o *Memcached) DelSession(id uuid.UUID, tenant string) error {
-	return o.client.Delete(makeKey(id, tenant))
+	key, err := o.makeKey(id, tenant)
+	if err != nil {
+		return err
+	}
+
+	return o.client.Delete(key)
+}
+
+func (o *Memcached) DelTenantSessions(tenant string) error {
+	key := makeGenerationKey(tenant)
+
+	// the generation is created on first use; if it is concurrently
+	// created by someone else, it is incremented instead
+	for i := 0; i < 2; i++ {
+		_, err := o.client.Increment(key, 1)
+		if !errors.Is(err, memcache.ErrCacheMiss) {
+			return err
+		}
+
+		err = o.client.Add(&memcache.Item{Key: key, Value: []byte("1")})
+		if !errors.Is(err, memcache.ErrNotStored) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("could not remove the sessions of tenant %q", tenant)
 }
 
 func (o *Memcached) GetSession(id uuid.UUID, tenant string) (json.RawMessage, error) {
-	item, err := o.client.Get(makeKey(id, tenant))
+	key, err := o.makeKey(id, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := o.client.Get(key)
 	if err != nil {
 		if err.Error() == "memcache: cache miss" {
 			return nil, fmt.Errorf(
@@ -82,3 +125,33 @@ func (o *Memcached) GetSession(id uuid.UUID, tenant string) (json.RawMessage, er
 func (o *Memcached) Close() error {
 	return o.client.Close()
 }
+
+// makeKey returns the key of the session in the tenant's current generation.
+// Sessions of the initial generation use the same key as TTLCache.
+func (o *Memcached) makeKey(id uuid.UUID, tenant string) (string, error) {
+	item, err := o.client.Get(makeGenerationKey(tenant))
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return makeKey(id, tenant), nil
+	} else if err != nil {
+		return "", err
+	}
+
+	generation, err := strconv.ParseUint(strings.TrimSpace(string(item.Value)), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("bad session generation for tenant %q: %w", tenant, err)
+	}
+
+	return fmt.Sprintf("%s?generation=%d", makeKey(id, tenant), generation), nil
+}
+
+// makeGenerationKey returns the key of the tenant's generation (see
+// Memcached).
+func makeGenerationKey(tenant string) string {
+	// session-generation://{tenant}
+	u := url.URL{
+		Scheme: "session-generation",
+		Host:   tenant,
+	}
+
+	return u.String()
+}
@@ -12,11 +12,12 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func Test_Memcached_SetGetDelOK(t *testing.T) {
+func newTestMemcached(t *testing.T) *Memcached {
 	sm := NewMemcached()
 
 	listner, err := net.Listen("tcp", "localhost:0")
 	require.NoError(t, err)
+	t.Cleanup(func() { listner.Close() })
 
 	server := &testServer{}
 	go server.Serve(listner)
@@ -24,12 +25,16 @@ func Test_Memcached_SetGetDelOK(t *testing.T) {
 	cfg := viper.New()
 	cfg.Set("servers", []string{listner.Addr().String()})
 
-	err = sm.Init(cfg)
-	defer sm.Close()
+	require.NoError(t, sm.Init(cfg))
+	t.Cleanup(func() { sm.Close() })
 
-	assert.NoError(t, err)
+	return sm
+}
 
-	err = sm.SetSession(testUUID, testTenant, testSession, testTTL)
+func Test_Memcached_SetGetDelOK(t *testing.T) {
+	sm := newTestMemcached(t)
+
+	err := sm.SetSession(testUUID, testTenant, testSession, testTTL)
 	assert.NoError(t, err)
 
 	session, err := sm.GetSession(testUUID, testTenant)
@@ -44,3 +49,38 @@ func Test_Memcached_SetGetDelOK(t *testing.T) {
 	_, err = sm.GetSession(testUUID, testTenant)
 	assert.EqualError(t, err, expectedErr)
 }
+
+func Test_Memcached_DelTenantSessions(t *testing.T) {
+	sm := newTestMemcached(t)
+
+	otherTenant := testTenant + "0"
+
+	require.NoError(t, sm.SetSession(testUUID, testTenant, testSession, testTTL))
+	require.NoError(t, sm.SetSession(testUUID, otherTenant, testSession, testTTL))
+
+	expectedErr := fmt.Sprintf("session not found for (id, tenant)=(%s, %s)", testUUIDString, testTenant)
+
+	// the tenant's sessions may be removed repeatedly
+	for i := 0; i < 2; i++ {
+		require.NoError(t, sm.DelTenantSessions(testTenant))
+
+		_, err := sm.GetSession(testUUID, testTenant)
+		assert.EqualError(t, err, expectedErr)
+	}
+
+	// the sessions of other tenants are retained
+	_, err := sm.GetSession(testUUID, otherTenant)
+	assert.NoError(t, err)
+
+	// new sessions of the tenant may be created
+	require.NoError(t, sm.SetSession(testUUID, testTenant, testSession, testTTL))
+
+	session, err := sm.GetSession(testUUID, testTenant)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(testSession), string(session))
+
+	require.NoError(t, sm.DelSession(testUUID, testTenant))
+
+	_, err = sm.GetSession(testUUID, testTenant)
+	assert.EqualError(t, err, expectedErr)
+}
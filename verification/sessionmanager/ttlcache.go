@@ -1,10 +1,11 @@
-// Copyright 2022-2025 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package sessionmanager
 
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -53,6 +54,18 @@ func (o *TTLCache) DelSession(id uuid.UUID, tenant string) error {
 	return nil
 }
 
+func (o *TTLCache) DelTenantSessions(tenant string) error {
+	prefix := makeTenantPrefix(tenant)
+
+	for _, key := range o.cache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			o.cache.Delete(key)
+		}
+	}
+
+	return nil
+}
+
 func (o *TTLCache) GetSession(id uuid.UUID, tenant string) (json.RawMessage, error) {
 	if item := o.cache.Get(makeKey(id, tenant)); item != nil {
 		return item.Value(), nil
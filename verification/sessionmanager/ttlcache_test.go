@@ -1,4 +1,4 @@
-// Copyright 2022-2025 Contributors to the Veraison project.
+// Copyright 2022-2026 Contributors to the Veraison project.
 // SPDX-License-Identifier: Apache-2.0
 package sessionmanager
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_TTLCache_SetGetDelOK(t *testing.T) {
@@ -54,3 +55,26 @@ func Test_TTLCache_Eviction(t *testing.T) {
 	_, err = sm.GetSession(testUUID, testTenant)
 	assert.EqualError(t, err, expectedErr)
 }
+
+func Test_TTLCache_DelTenantSessions(t *testing.T) {
+	sm := TTLCache{}
+
+	err := sm.Init(nil)
+	defer sm.Close()
+
+	assert.NoError(t, err)
+
+	otherTenant := testTenant + "0"
+
+	require.NoError(t, sm.SetSession(testUUID, testTenant, testSession, testTTL))
+	require.NoError(t, sm.SetSession(testUUID, otherTenant, testSession, testTTL))
+
+	require.NoError(t, sm.DelTenantSessions(testTenant))
+
+	_, err = sm.GetSession(testUUID, testTenant)
+	assert.Error(t, err)
+
+	// the sessions of other tenants are retained
+	_, err = sm.GetSession(testUUID, otherTenant)
+	assert.NoError(t, err)
+}
@@ -67,19 +67,24 @@ func (o *Verifier) SupportedMediaTypes() ([]string, error) {
 
 func (o *Verifier) ProcessEvidence(
 	tenantID string,
+	sessionID string,
 	nonce []byte,
 	data []byte,
 	mt string,
+	sessionContext []byte,
 ) ([]byte, error) {
 	token := &proto.AttestationToken{
-		TenantId:  tenantID,
-		Data:      data,
-		MediaType: mt,
-		Nonce:     nonce,
+		TenantId:       tenantID,
+		Data:           data,
+		MediaType:      mt,
+		Nonce:          nonce,
+		SessionContext: sessionContext,
+		SessionId:      sessionID,
 	}
 
-	appraisalCtx, err := o.VTSClient.GetAttestation(
+	appraisalCtx, err := vtsclient.GetAttestation(
 		context.Background(),
+		o.VTSClient,
 		token,
 	)
 	if err != nil {
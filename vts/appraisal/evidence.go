@@ -10,24 +10,36 @@ type Evidence struct {
 	Data      []byte `json:"data"`
 	MediaType string `json:"media-type"`
 	Nonce     []byte `json:"nonce"`
+
+	// SessionContext is the JSON object supplied by the relying party
+	// when creating the session, if any.
+	SessionContext []byte `json:"session-context,omitempty"`
+
+	// SessionID identifies the verification session through which the
+	// evidence was submitted, if known.
+	SessionID string `json:"session-id,omitempty"`
 }
 
 // NewEvidenceFromProtobuf creates a new Evidence from a proto.AttestationToken
 func NewEvidenceFromProtobuf(token *proto.AttestationToken) *Evidence {
 	return &Evidence{
-		TenantID:  token.TenantId,
-		Data:      token.Data,
-		MediaType: token.MediaType,
-		Nonce:     token.Nonce,
+		TenantID:       token.TenantId,
+		Data:           token.Data,
+		MediaType:      token.MediaType,
+		Nonce:          token.Nonce,
+		SessionContext: token.SessionContext,
+		SessionID:      token.SessionId,
 	}
 }
 
 // ToProtobuf converts this Evidence to an proto.AttestationToken
 func (o *Evidence) ToProtobuf() *proto.AttestationToken {
 	return &proto.AttestationToken{
-		TenantId:  o.TenantID,
-		Data:      o.Data,
-		MediaType: o.MediaType,
-		Nonce:     o.Nonce,
+		TenantId:       o.TenantID,
+		Data:           o.Data,
+		MediaType:      o.MediaType,
+		Nonce:          o.Nonce,
+		SessionContext: o.SessionContext,
+		SessionId:      o.SessionID,
 	}
 }
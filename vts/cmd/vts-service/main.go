@@ -16,6 +16,8 @@ import (
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/plugin"
 	"github.com/veraison/services/policy"
+	"github.com/veraison/services/provenance"
+	"github.com/veraison/services/tenant"
 	"github.com/veraison/services/vts/coserv"
 	"github.com/veraison/services/vts/earsigner"
 	"github.com/veraison/services/vts/policymanager"
@@ -59,6 +61,23 @@ func main() {
 		log.Fatalf("policy manager initialization failed: %v", err)
 	}
 
+	if tenantCfg := v.Sub("tenant-store"); tenantCfg != nil {
+		log.Info("initializing tenant store")
+		policyManager.Tenants, err = tenant.NewStore(tenantCfg, log.Named("tenant-store"))
+		if err != nil {
+			log.Fatalf("tenant store initialization failed: %v", err)
+		}
+	}
+
+	var provenanceStore *provenance.Store
+	if provenanceCfg := v.Sub("provenance-store"); provenanceCfg != nil {
+		log.Info("initializing provenance store")
+		provenanceStore, err = provenance.NewStore(provenanceCfg, log.Named("provenance-store"))
+		if err != nil {
+			log.Fatalf("provenance store initialization failed: %v", err)
+		}
+	}
+
 	log.Info("loading attestation schemes")
 	var schemePluginManager plugin.IManager[handler.ISchemeHandler]
 	var coservProxyPluginManager plugin.IManager[handler.ICoservProxyHandler]
@@ -160,10 +179,11 @@ func main() {
 	log.Info("initializing service")
 	// from this point onwards taStore, enStore, evPluginManager,
 	// endPluginManager, storePluginManager, coservProxyPluginManager,
-	// policyManager and earSigner are owned by vts
+	// policyManager, earSigner and provenanceStore are owned by vts
 	vts := trustedservices.NewGRPC(enStore,
 		schemePluginManager, coservProxyPluginManager,
-		policyManager, earSigner, coservContext, log.Named("vts"))
+		policyManager, earSigner, coservContext, provenanceStore,
+		log.Named("vts"))
 
 	if err = vts.Init(subs["vts"]); err != nil {
 		log.Fatalf("VTS initialisation failed: %v", err)
@@ -52,18 +52,18 @@ func (mr *MockIAgentMockRecorder) Close() *gomock.Call {
 }
 
 // Evaluate mocks base method.
-func (m *MockIAgent) Evaluate(ctx context.Context, sessionContext map[string]any, appraisalContext *appraisal.Context, policy *policy.Policy, submod string, appraisal *ear.Appraisal, endorsements []*comid.ValueTriple) (*ear.Appraisal, error) {
+func (m *MockIAgent) Evaluate(ctx context.Context, sessionContext map[string]any, appraisalContext *appraisal.Context, policy *policy.Policy, data *policy.PolicyData, submod string, appraisal *ear.Appraisal, endorsements []*comid.ValueTriple) (*ear.Appraisal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Evaluate", ctx, sessionContext, appraisalContext, policy, submod, appraisal, endorsements)
+	ret := m.ctrl.Call(m, "Evaluate", ctx, sessionContext, appraisalContext, policy, data, submod, appraisal, endorsements)
 	ret0, _ := ret[0].(*ear.Appraisal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Evaluate indicates an expected call of Evaluate.
-func (mr *MockIAgentMockRecorder) Evaluate(ctx, sessionContext, appraisalContext, policy, submod, appraisal, endorsements interface{}) *gomock.Call {
+func (mr *MockIAgentMockRecorder) Evaluate(ctx, sessionContext, appraisalContext, policy, data, submod, appraisal, endorsements interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockIAgent)(nil).Evaluate), ctx, sessionContext, appraisalContext, policy, submod, appraisal, endorsements)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockIAgent)(nil).Evaluate), ctx, sessionContext, appraisalContext, policy, data, submod, appraisal, endorsements)
 }
 
 // GetBackendName mocks base method.
@@ -80,6 +80,20 @@ func (mr *MockIAgentMockRecorder) GetBackendName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackendName", reflect.TypeOf((*MockIAgent)(nil).GetBackendName))
 }
 
+// GetDecisionLog mocks base method.
+func (m *MockIAgent) GetDecisionLog() policy.IDecisionLog {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDecisionLog")
+	ret0, _ := ret[0].(policy.IDecisionLog)
+	return ret0
+}
+
+// GetDecisionLog indicates an expected call of GetDecisionLog.
+func (mr *MockIAgentMockRecorder) GetDecisionLog() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDecisionLog", reflect.TypeOf((*MockIAgent)(nil).GetDecisionLog))
+}
+
 // Init mocks base method.
 func (m *MockIAgent) Init(v *viper.Viper) error {
 	m.ctrl.T.Helper()
@@ -94,16 +108,28 @@ func (mr *MockIAgentMockRecorder) Init(v interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockIAgent)(nil).Init), v)
 }
 
+// Invalidate mocks base method.
+func (m *MockIAgent) Invalidate(policyID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Invalidate", policyID)
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockIAgentMockRecorder) Invalidate(policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockIAgent)(nil).Invalidate), policyID)
+}
+
 // Validate mocks base method.
-func (m *MockIAgent) Validate(ctx context.Context, policyRules string) error {
+func (m *MockIAgent) Validate(ctx context.Context, policyType, policyRules string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Validate", ctx, policyRules)
+	ret := m.ctrl.Call(m, "Validate", ctx, policyType, policyRules)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Validate indicates an expected call of Validate.
-func (mr *MockIAgentMockRecorder) Validate(ctx, policyRules interface{}) *gomock.Call {
+func (mr *MockIAgentMockRecorder) Validate(ctx, policyType, policyRules interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockIAgent)(nil).Validate), ctx, policyRules)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockIAgent)(nil).Validate), ctx, policyType, policyRules)
 }
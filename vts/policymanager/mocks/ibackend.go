@@ -48,18 +48,18 @@ func (mr *MockIBackendMockRecorder) Close() *gomock.Call {
 }
 
 // Evaluate mocks base method.
-func (m *MockIBackend) Evaluate(ctx context.Context, sessionContext map[string]any, scheme, policy string, result, evidence map[string]any, endorsements []map[string]any) (map[string]any, error) {
+func (m *MockIBackend) Evaluate(ctx context.Context, sessionContext map[string]any, scheme, policyID, policy string, data map[string]any, dataRevision string, result, evidence map[string]any, endorsements []map[string]any) (map[string]any, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Evaluate", ctx, sessionContext, scheme, policy, result, evidence, endorsements)
+	ret := m.ctrl.Call(m, "Evaluate", ctx, sessionContext, scheme, policyID, policy, data, dataRevision, result, evidence, endorsements)
 	ret0, _ := ret[0].(map[string]any)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Evaluate indicates an expected call of Evaluate.
-func (mr *MockIBackendMockRecorder) Evaluate(ctx, sessionContext, scheme, policy, result, evidence, endorsements interface{}) *gomock.Call {
+func (mr *MockIBackendMockRecorder) Evaluate(ctx, sessionContext, scheme, policyID, policy, data, dataRevision, result, evidence, endorsements interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockIBackend)(nil).Evaluate), ctx, sessionContext, scheme, policy, result, evidence, endorsements)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockIBackend)(nil).Evaluate), ctx, sessionContext, scheme, policyID, policy, data, dataRevision, result, evidence, endorsements)
 }
 
 // GetName mocks base method.
@@ -90,6 +90,18 @@ func (mr *MockIBackendMockRecorder) Init(v interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockIBackend)(nil).Init), v)
 }
 
+// Invalidate mocks base method.
+func (m *MockIBackend) Invalidate(policyID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Invalidate", policyID)
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockIBackendMockRecorder) Invalidate(policyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockIBackend)(nil).Invalidate), policyID)
+}
+
 // Validate mocks base method.
 func (m *MockIBackend) Validate(ctx context.Context, policy string) error {
 	m.ctrl.T.Helper()
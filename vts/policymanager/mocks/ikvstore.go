@@ -9,6 +9,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	viper "github.com/spf13/viper"
+	kvstore "github.com/veraison/services/kvstore"
 	zap "go.uber.org/zap"
 )
 
@@ -63,6 +64,20 @@ func (mr *MockIKVStoreMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockIKVStore)(nil).Close))
 }
 
+// CompareAndSwap mocks base method.
+func (m *MockIKVStore) CompareAndSwap(key string, version kvstore.Version, vals []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndSwap", key, version, vals)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareAndSwap indicates an expected call of CompareAndSwap.
+func (mr *MockIKVStoreMockRecorder) CompareAndSwap(key, version, vals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndSwap", reflect.TypeOf((*MockIKVStore)(nil).CompareAndSwap), key, version, vals)
+}
+
 // Del mocks base method.
 func (m *MockIKVStore) Del(key string) error {
 	m.ctrl.T.Helper()
@@ -107,6 +122,22 @@ func (mr *MockIKVStoreMockRecorder) GetKeys() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeys", reflect.TypeOf((*MockIKVStore)(nil).GetKeys))
 }
 
+// GetVersioned mocks base method.
+func (m *MockIKVStore) GetVersioned(key string) ([]string, kvstore.Version, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersioned", key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(kvstore.Version)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetVersioned indicates an expected call of GetVersioned.
+func (mr *MockIKVStoreMockRecorder) GetVersioned(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersioned", reflect.TypeOf((*MockIKVStore)(nil).GetVersioned), key)
+}
+
 // Init mocks base method.
 func (m *MockIKVStore) Init(v *viper.Viper, logger *zap.SugaredLogger) error {
 	m.ctrl.T.Helper()
@@ -121,6 +152,22 @@ func (mr *MockIKVStoreMockRecorder) Init(v, logger interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockIKVStore)(nil).Init), v, logger)
 }
 
+// ScanKeys mocks base method.
+func (m *MockIKVStore) ScanKeys(prefix, cursor string, limit int) ([]string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanKeys", prefix, cursor, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ScanKeys indicates an expected call of ScanKeys.
+func (mr *MockIKVStoreMockRecorder) ScanKeys(prefix, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanKeys", reflect.TypeOf((*MockIKVStore)(nil).ScanKeys), prefix, cursor, limit)
+}
+
 // Set mocks base method.
 func (m *MockIKVStore) Set(key, val string) error {
 	m.ctrl.T.Helper()
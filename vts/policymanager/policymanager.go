@@ -4,11 +4,15 @@ package policymanager
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/spf13/viper"
 	"github.com/veraison/corim/comid"
 	"github.com/veraison/services/policy"
+	"github.com/veraison/services/tenant"
 	"github.com/veraison/services/vts/appraisal"
 	"go.uber.org/zap"
 )
@@ -17,6 +21,19 @@ type PolicyManager struct {
 	Store *policy.Store
 	Agent policy.IAgent
 
+	// Tenants is the tenant registry, used to look up tenants' default
+	// policies. It is nil if the registry is not used.
+	Tenants *tenant.Store
+
+	// active tracks the policy last seen as active for each policy key,
+	// along with the revision of the policy data it was evaluated with, so
+	// that the agent can be told to discard cached state for a policy once
+	// it is no longer active, or its data has changed. Backends key cached
+	// state on the data revision, so this only releases stale state early;
+	// it is not needed for correctness.
+	active   map[string]activePolicy
+	activeMu sync.Mutex
+
 	logger *zap.SugaredLogger
 }
 
@@ -33,50 +50,162 @@ func New(v *viper.Viper, store *policy.Store, logger *zap.SugaredLogger) (*Polic
 	return pm, nil
 }
 
+// Evaluate applies the chain of policies configured for the tenant and scheme
+// of the appraisal to its result. Policies are evaluated in chain order, each
+// one seeing the result updated by the previous ones, and the ID of every
+// policy applied is recorded in the appraisal policy ID. Policies in the chain
+// that do not exist are skipped.
 func (o *PolicyManager) Evaluate(
 	ctx context.Context,
 	appraisalContext *appraisal.Context,
 	endorsements []*comid.ValueTriple,
 ) error {
-	policyKey := o.getPolicyKey(appraisalContext)
-
-	pol, err := o.getPolicy(policyKey)
+	policyKeys, err := o.getPolicyKeys(appraisalContext)
 	if err != nil {
-		if errors.Is(err, policy.ErrNoPolicy) {
-			o.logger.Debugw("no policy", "policy-id", policyKey)
-			return nil // No policy? No problem!
-		}
-
 		return err
 	}
 
-	sessionContext := map[string]any{
-		"nonce": appraisalContext.Result.Nonce,
-	}
+	var sessionContext map[string]any
 
-	for submodName, submodAppraisal := range appraisalContext.Result.Submods {
-		evaluated, err := o.Agent.Evaluate(
-			ctx,
-			sessionContext,
-			appraisalContext,
-			pol,
-			submodName,
-			submodAppraisal,
-			endorsements,
-		)
+	for _, policyKey := range policyKeys {
+		pol, data, err := o.getPolicy(policyKey)
 		if err != nil {
+			if errors.Is(err, policy.ErrNoPolicy) {
+				o.logger.Debugw("no policy", "policy-id", policyKey)
+				continue // No policy? No problem!
+			}
+
 			return err
 		}
-		appraisalContext.Result.Submods[submodName] = evaluated
-	}
 
-	if err := appraisalContext.UpdatePolicyID(pol.UUID.String()); err != nil {
-		return err
+		if sessionContext == nil {
+			sessionContext, err = getSessionContext(appraisalContext)
+			if err != nil {
+				return err
+			}
+		}
+
+		for submodName, submodAppraisal := range appraisalContext.Result.Submods {
+			evaluated, err := o.Agent.Evaluate(
+				ctx,
+				sessionContext,
+				appraisalContext,
+				pol,
+				data,
+				submodName,
+				submodAppraisal,
+				endorsements,
+			)
+			if err != nil {
+				return err
+			}
+			appraisalContext.Result.Submods[submodName] = evaluated
+		}
+
+		if err := appraisalContext.UpdatePolicyID(pol.UUID.String()); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// getSessionContext returns the session context exposed to the policy. This
+// consists of the context supplied by the relying party when the session was
+// created (if any), along with the session nonce.
+func getSessionContext(appraisalContext *appraisal.Context) (map[string]any, error) {
+	sessionContext := make(map[string]any)
+
+	if sc := appraisalContext.Evidence.SessionContext; len(sc) != 0 {
+		if err := json.Unmarshal(sc, &sessionContext); err != nil {
+			return nil, fmt.Errorf("bad session context: %w", err)
+		}
+	}
+
+	sessionContext["nonce"] = appraisalContext.Result.Nonce
+
+	return sessionContext, nil
+}
+
+// getPolicyKeys returns the keys of the policies in the chain for the tenant
+// and scheme of the appraisal, in evaluation order. If a chain has not been
+// set, the chain consists of just the default policy (see getPolicyKey), or
+// the tenant's default policy, if one is set in the tenant registry.
+func (o *PolicyManager) getPolicyKeys(a *appraisal.Context) ([]policy.PolicyKey, error) {
+	chain, err := o.Store.GetChain(a.Evidence.TenantID, a.Scheme)
+	if err != nil {
+		if errors.Is(err, policy.ErrNoPolicyChain) {
+			key := o.getPolicyKey(a)
+
+			name, err := o.getTenantDefaultPolicy(key.TenantId)
+			if err != nil {
+				return nil, err
+			}
+
+			if name != "" {
+				key.Name = name
+			}
+
+			return []policy.PolicyKey{key}, nil
+		}
+
+		return nil, err
+	}
+
+	return chain.Keys(), nil
+}
+
+// IsSchemeEnabled returns true iff the specified scheme is enabled for the
+// specified tenant in the tenant registry. All schemes are enabled for tenants
+// that are not in the registry, or if the registry is not used (the services
+// reject requests for unregistered tenants when the registry is used).
+func (o *PolicyManager) IsSchemeEnabled(tenantID, scheme string) (bool, error) {
+	t, err := o.getTenant(tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	if t == nil {
+		return true, nil
+	}
+
+	return t.IsSchemeEnabled(scheme), nil
+}
+
+// getTenantDefaultPolicy returns the name of the default policy set for the
+// specified tenant in the tenant registry, or an empty string if there isn't
+// one (or the registry is not used).
+func (o *PolicyManager) getTenantDefaultPolicy(tenantID string) (string, error) {
+	t, err := o.getTenant(tenantID)
+	if err != nil || t == nil {
+		return "", err
+	}
+
+	return t.DefaultPolicy, nil
+}
+
+// getTenant returns the specified tenant's entry in the tenant registry, or
+// nil if there isn't one (or the registry is not used).
+func (o *PolicyManager) getTenant(tenantID string) (*tenant.Tenant, error) {
+	if o.Tenants == nil {
+		return nil, nil
+	}
+
+	t, err := o.Tenants.Get(tenantID)
+	if err != nil {
+		if errors.Is(err, tenant.ErrNoTenant) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// getPolicyKey returns the key of the default policy for the tenant and
+// scheme of the appraisal. The default policy is named after the agent's
+// backend.
 func (o *PolicyManager) getPolicyKey(a *appraisal.Context) policy.PolicyKey {
 	return policy.PolicyKey{
 		TenantId: a.Evidence.TenantID,
@@ -85,11 +214,69 @@ func (o *PolicyManager) getPolicyKey(a *appraisal.Context) policy.PolicyKey {
 	}
 }
 
-func (o *PolicyManager) getPolicy(policyKey policy.PolicyKey) (*policy.Policy, error) {
+// getPolicy returns the active policy for the specified key, along with the
+// policy data for the key's tenant and scheme (nil if there is none). The
+// active policy reflects any scheduled activations that have become due, so the
+// agent is told to invalidate the previous policy when one takes effect.
+func (o *PolicyManager) getPolicy(
+	policyKey policy.PolicyKey,
+) (*policy.Policy, *policy.PolicyData, error) {
 	p, err := o.Store.GetActive(policyKey)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, policy.ErrNoPolicy) || errors.Is(err, policy.ErrNoActivePolicy) {
+			o.updateActive(policyKey, activePolicy{})
+		}
+		return nil, nil, err
 	}
 
-	return p, nil
+	current := activePolicy{PolicyID: p.UUID.String()}
+
+	pd, err := o.Store.GetData(policyKey.TenantId, policyKey.Scheme)
+	if err != nil && !errors.Is(err, policy.ErrNoPolicyData) {
+		return nil, nil, err
+	}
+
+	if pd != nil {
+		current.DataRevision = pd.Revision.String()
+	}
+
+	o.updateActive(policyKey, current)
+
+	return p, pd, nil
+}
+
+// activePolicy identifies the active policy for a key, and the revision of the
+// policy data it is evaluated with.
+type activePolicy struct {
+	PolicyID     string
+	DataRevision string
+}
+
+// updateActive records current as the active policy for the specified key.
+// If this differs from the previously recorded active policy (or the policy
+// data has changed), the agent is told to invalidate any state cached for the
+// previous policy.
+func (o *PolicyManager) updateActive(policyKey policy.PolicyKey, current activePolicy) {
+	o.activeMu.Lock()
+	defer o.activeMu.Unlock()
+
+	if o.active == nil {
+		o.active = make(map[string]activePolicy)
+	}
+
+	key := policyKey.String()
+	prev, ok := o.active[key]
+	if ok && prev == current {
+		return
+	}
+
+	if ok && prev.PolicyID != "" {
+		o.Agent.Invalidate(prev.PolicyID)
+	}
+
+	if current.PolicyID == "" {
+		delete(o.active, key)
+	} else {
+		o.active[key] = current
+	}
 }
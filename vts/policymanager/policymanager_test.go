@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/spf13/viper"
@@ -16,6 +17,7 @@ import (
 	"github.com/veraison/services/kvstore"
 	"github.com/veraison/services/log"
 	"github.com/veraison/services/policy"
+	"github.com/veraison/services/tenant"
 	"github.com/veraison/services/vts/appraisal"
 	mock_deps "github.com/veraison/services/vts/policymanager/mocks"
 )
@@ -45,7 +47,7 @@ func TestPolicyMgr_getPolicy_not_found(t *testing.T) {
 	polKey := pm.getPolicyKey(appraisal)
 	assert.Equal(t, "0:TPM_ENACTTRUST:opa", polKey.String())
 
-	pol, err := pm.getPolicy(polKey)
+	pol, _, err := pm.getPolicy(polKey)
 	assert.Nil(t, pol)
 	assert.ErrorIs(t, err, policy.ErrNoPolicy)
 }
@@ -57,6 +59,9 @@ func TestPolicyMgr_getPolicy_OK(t *testing.T) {
 	store.EXPECT().
 		Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
 		Return([]string{`{"uuid": "7df7714e-aa04-4638-bcbf-434b1dd720f1", "active": true}`}, nil)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST")).
+		Return(nil, kvstore.ErrKeyNotFound)
 
 	agent := mock_deps.NewMockIAgent(ctrl)
 	agent.EXPECT().GetBackendName().Return("opa")
@@ -73,7 +78,7 @@ func TestPolicyMgr_getPolicy_OK(t *testing.T) {
 	polKey := pm.getPolicyKey(appraisal)
 	assert.Equal(t, "0:TPM_ENACTTRUST:opa", polKey.String())
 
-	_, err := pm.getPolicy(polKey)
+	_, _, err := pm.getPolicy(polKey)
 	require.NoError(t, err)
 }
 
@@ -116,9 +121,15 @@ func TestPolicyMgr_Evaluate_OK(t *testing.T) {
 	}
 
 	store := mock_deps.NewMockIKVStore(ctrl)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:@chain")).
+		Return(nil, kvstore.ErrKeyNotFound)
 	store.EXPECT().
 		Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
 		Return([]string{`{"uuid": "7df7714e-aa04-4638-bcbf-434b1dd720f1", "active": true}`}, nil)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST")).
+		Return(nil, kvstore.ErrKeyNotFound)
 
 	agent := mock_deps.NewMockIAgent(ctrl)
 	agent.EXPECT().GetBackendName().Return("opa")
@@ -128,6 +139,7 @@ func TestPolicyMgr_Evaluate_OK(t *testing.T) {
 			gomock.Any(),
 			appraisalContext,
 			gomock.Any(),
+			gomock.Nil(),
 			"test",
 			ar.Submods["test"],
 			endorsements,
@@ -147,9 +159,15 @@ func TestPolicyMgr_Evaluate_NOK(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	store := mock_deps.NewMockIKVStore(ctrl)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:@chain")).
+		Return(nil, kvstore.ErrKeyNotFound)
 	store.EXPECT().
 		Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
 		Return([]string{`{"uuid": "7df7714e-aa04-4638-bcbf-434b1dd720f1", "active": true}`}, nil)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST")).
+		Return(nil, kvstore.ErrKeyNotFound)
 
 	ar := ear.NewAttestationResult("test", "test", "test")
 	expectedErr := errors.New("could not evaluate policy: policy returned bad update")
@@ -169,6 +187,7 @@ func TestPolicyMgr_Evaluate_NOK(t *testing.T) {
 		gomock.Any(),
 		appraisalContext,
 		gomock.Any(),
+		gomock.Nil(),
 		"test",
 		ar.Submods["test"],
 		endorsements,
@@ -183,3 +202,299 @@ func TestPolicyMgr_Evaluate_NOK(t *testing.T) {
 	assert.ErrorIs(t, err, expectedErr)
 
 }
+
+func TestPolicyMgr_getPolicy_invalidates_on_activation_change(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	firstID := "7df7714e-aa04-4638-bcbf-434b1dd720f1"
+	secondID := "2d5e2a8e-9b0a-4f1e-8c0d-6a4b9d2e1f30"
+
+	store := mock_deps.NewMockIKVStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
+			Times(2).
+			Return([]string{`{"uuid": "` + firstID + `", "active": true}`}, nil),
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
+			Return([]string{`{"uuid": "` + secondID + `", "active": true}`}, nil),
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
+			Return(nil, kvstore.ErrKeyNotFound),
+	)
+
+	agent := mock_deps.NewMockIAgent(ctrl)
+	gomock.InOrder(
+		agent.EXPECT().Invalidate(firstID),
+		agent.EXPECT().Invalidate(secondID),
+	)
+
+	pm := &PolicyManager{Store: &policy.Store{KVStore: store}, Agent: agent}
+	polKey := policy.PolicyKey{TenantId: "0", Scheme: "TPM_ENACTTRUST", Name: "opa"}
+
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST")).
+		AnyTimes().
+		Return(nil, kvstore.ErrKeyNotFound)
+
+	for i := 0; i < 3; i++ {
+		_, _, err := pm.getPolicy(polKey)
+		require.NoError(t, err)
+	}
+
+	_, _, err := pm.getPolicy(polKey)
+	assert.ErrorIs(t, err, policy.ErrNoPolicy)
+}
+
+func TestPolicyMgr_getPolicy_scheduled_activation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	firstID := "7df7714e-aa04-4638-bcbf-434b1dd720f1"
+	secondID := "2d5e2a8e-9b0a-4f1e-8c0d-6a4b9d2e1f30"
+
+	// the same stored versions, before and after the scheduled time
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	store := mock_deps.NewMockIKVStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
+			Return([]string{
+				`{"uuid": "` + firstID + `", "active": true}`,
+				`{"uuid": "` + secondID + `", "scheduled_activation": "` + future + `"}`,
+			}, nil),
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
+			Return([]string{
+				`{"uuid": "` + firstID + `", "active": true}`,
+				`{"uuid": "` + secondID + `", "scheduled_activation": "` + past + `"}`,
+			}, nil),
+	)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST")).
+		AnyTimes().
+		Return(nil, kvstore.ErrKeyNotFound)
+
+	agent := mock_deps.NewMockIAgent(ctrl)
+	agent.EXPECT().Invalidate(firstID)
+
+	pm := &PolicyManager{Store: &policy.Store{KVStore: store}, Agent: agent}
+	polKey := policy.PolicyKey{TenantId: "0", Scheme: "TPM_ENACTTRUST", Name: "opa"}
+
+	pol, _, err := pm.getPolicy(polKey)
+	require.NoError(t, err)
+	assert.Equal(t, firstID, pol.UUID.String())
+
+	pol, _, err = pm.getPolicy(polKey)
+	require.NoError(t, err)
+	assert.Equal(t, secondID, pol.UUID.String())
+}
+
+func TestPolicyMgr_getPolicy_invalidates_on_data_change(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	polID := "7df7714e-aa04-4638-bcbf-434b1dd720f1"
+	firstRev := "2d5e2a8e-9b0a-4f1e-8c0d-6a4b9d2e1f30"
+	secondRev := "c4a1b0f2-5d3e-4b6a-9f8e-1a2b3c4d5e6f"
+
+	store := mock_deps.NewMockIKVStore(ctrl)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:opa")).
+		Times(3).
+		Return([]string{`{"uuid": "` + polID + `", "active": true}`}, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST")).
+			Times(2).
+			Return([]string{`{"revision": "` + firstRev + `", "data": {"allowed": [1]}}`}, nil),
+		store.EXPECT().
+			Get(gomock.Eq("0:TPM_ENACTTRUST")).
+			Return([]string{`{"revision": "` + secondRev + `", "data": {"allowed": [2]}}`}, nil),
+	)
+
+	agent := mock_deps.NewMockIAgent(ctrl)
+	agent.EXPECT().Invalidate(polID).Times(1)
+
+	pm := &PolicyManager{Store: &policy.Store{KVStore: store}, Agent: agent}
+	polKey := policy.PolicyKey{TenantId: "0", Scheme: "TPM_ENACTTRUST", Name: "opa"}
+
+	expected := []any{float64(1), float64(1), float64(2)}
+	for i := 0; i < 3; i++ {
+		_, data, err := pm.getPolicy(polKey)
+		require.NoError(t, err)
+		assert.Equal(t, []any{expected[i]}, data.Data["allowed"])
+	}
+}
+
+func TestPolicyMgr_getSessionContext(t *testing.T) {
+	nonce := "Ce4CGfQF1vE="
+	ar := ear.NewAttestationResult("test", "test", "test")
+	ar.Nonce = &nonce
+
+	appraisalContext := &appraisal.Context{
+		Evidence: &appraisal.Evidence{TenantID: "0"},
+		Result:   ar,
+	}
+
+	sc, err := getSessionContext(appraisalContext)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"nonce": &nonce}, sc)
+
+	appraisalContext.Evidence.SessionContext = []byte(
+		`{"expected-instance-id": "AQID", "min-tcb": 3, "nonce": "spoofed"}`)
+
+	sc, err = getSessionContext(appraisalContext)
+	require.NoError(t, err)
+	assert.Equal(t, "AQID", sc["expected-instance-id"])
+	assert.Equal(t, float64(3), sc["min-tcb"])
+	assert.Equal(t, &nonce, sc["nonce"])
+
+	appraisalContext.Evidence.SessionContext = []byte(`["not", "an", "object"]`)
+
+	_, err = getSessionContext(appraisalContext)
+	assert.ErrorContains(t, err, "bad session context")
+}
+
+func TestPolicyMgr_Evaluate_chain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	baselineID := "7df7714e-aa04-4638-bcbf-434b1dd720f1"
+	overlayID := "2d5e2a8e-9b0a-4f1e-8c0d-6a4b9d2e1f30"
+
+	endorsements := []*comid.ValueTriple{}
+	ar := ear.NewAttestationResult("TPM_ENACTTRUST", "test", "test")
+	appraisalContext := &appraisal.Context{
+		Scheme: "TPM_ENACTTRUST",
+		Evidence: &appraisal.Evidence{
+			TenantID: "0",
+		},
+		Result: ar,
+	}
+	appraisalContext.InitPolicyID()
+
+	store := mock_deps.NewMockIKVStore(ctrl)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:@chain")).
+		Return([]string{`{"tenant_id": "0", "scheme": "TPM_ENACTTRUST",
+			"policies": ["baseline", "missing", "overlay"]}`}, nil)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:baseline")).
+		Return([]string{`{"uuid": "` + baselineID + `", "active": true}`}, nil)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:missing")).
+		Return(nil, kvstore.ErrKeyNotFound)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST:overlay")).
+		Return([]string{`{"uuid": "` + overlayID + `", "active": true}`}, nil)
+	store.EXPECT().
+		Get(gomock.Eq("0:TPM_ENACTTRUST")).
+		Times(2).
+		Return(nil, kvstore.ErrKeyNotFound)
+
+	var evaluated []string
+	agent := mock_deps.NewMockIAgent(ctrl)
+	agent.EXPECT().
+		Evaluate(context.TODO(), gomock.Any(), appraisalContext, gomock.Any(), gomock.Nil(),
+			"TPM_ENACTTRUST", gomock.Any(), endorsements).
+		Times(2).
+		DoAndReturn(func(_ context.Context, _ map[string]any, _ *appraisal.Context,
+			pol *policy.Policy, _ *policy.PolicyData, _ string, a *ear.Appraisal,
+			_ []*comid.ValueTriple,
+		) (*ear.Appraisal, error) {
+			evaluated = append(evaluated, pol.StoreKey.Name)
+			return a, nil
+		})
+
+	pm := &PolicyManager{
+		Store:  &policy.Store{KVStore: store, Logger: log.Named("store")},
+		Agent:  agent,
+		logger: log.Named("manager"),
+	}
+	err := pm.Evaluate(context.TODO(), appraisalContext, endorsements)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"baseline", "overlay"}, evaluated)
+	assert.Equal(t, "policy:TPM_ENACTTRUST/"+baselineID+"/"+overlayID,
+		*ar.Submods["TPM_ENACTTRUST"].AppraisalPolicyID)
+}
+
+func TestPolicyMgr_getPolicyKeys_tenant_default(t *testing.T) {
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	poStore, err := policy.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer poStore.Close()
+
+	tenants, err := tenant.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer tenants.Close()
+
+	_, err = tenants.Add(&tenant.Tenant{ID: "1", DefaultPolicy: "baseline"}, "")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	agent := mock_deps.NewMockIAgent(ctrl)
+	agent.EXPECT().GetBackendName().AnyTimes().Return("opa")
+
+	pm := &PolicyManager{Store: poStore, Agent: agent, Tenants: tenants}
+
+	appraisalContext := &appraisal.Context{
+		Evidence: &appraisal.Evidence{TenantID: "1"},
+		Scheme:   "TPM_ENACTTRUST",
+	}
+
+	keys, err := pm.getPolicyKeys(appraisalContext)
+	require.NoError(t, err)
+	assert.Equal(t, []policy.PolicyKey{{TenantId: "1", Scheme: "TPM_ENACTTRUST", Name: "baseline"}}, keys)
+
+	// tenants that are not in the registry use the backend's policy
+	appraisalContext.Evidence.TenantID = "2"
+
+	keys, err = pm.getPolicyKeys(appraisalContext)
+	require.NoError(t, err)
+	assert.Equal(t, []policy.PolicyKey{{TenantId: "2", Scheme: "TPM_ENACTTRUST", Name: "opa"}}, keys)
+
+	// an explicit chain takes precedence
+	_, err = poStore.SetChain("1", "TPM_ENACTTRUST", []string{"opa"}, "")
+	require.NoError(t, err)
+	appraisalContext.Evidence.TenantID = "1"
+
+	keys, err = pm.getPolicyKeys(appraisalContext)
+	require.NoError(t, err)
+	assert.Equal(t, []policy.PolicyKey{{TenantId: "1", Scheme: "TPM_ENACTTRUST", Name: "opa"}}, keys)
+}
+
+func TestPolicyMgr_IsSchemeEnabled(t *testing.T) {
+	pm := &PolicyManager{}
+
+	// all schemes are enabled if the registry is not used
+	enabled, err := pm.IsSchemeEnabled("1", "PSA_IOT")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	v := viper.New()
+	v.Set("backend", "memory")
+
+	tenants, err := tenant.NewStore(v, log.Named("test"))
+	require.NoError(t, err)
+	defer tenants.Close()
+
+	_, err = tenants.Add(&tenant.Tenant{ID: "1", Schemes: []string{"ARM_CCA"}}, "")
+	require.NoError(t, err)
+
+	pm.Tenants = tenants
+
+	enabled, err = pm.IsSchemeEnabled("1", "PSA_IOT")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	enabled, err = pm.IsSchemeEnabled("1", "ARM_CCA")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = pm.IsSchemeEnabled("2", "PSA_IOT")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+}
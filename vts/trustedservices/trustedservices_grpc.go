@@ -10,10 +10,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"net"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -31,6 +33,7 @@ import (
 	handlermod "github.com/veraison/services/handler"
 	"github.com/veraison/services/plugin"
 	"github.com/veraison/services/proto"
+	"github.com/veraison/services/provenance"
 	"github.com/veraison/services/vts/appraisal"
 	vtscoserv "github.com/veraison/services/vts/coserv"
 	"github.com/veraison/services/vts/earsigner"
@@ -44,6 +47,10 @@ const DummyTenantID = "0"
 
 var ErrMeasurementsNotSupported = errors.New("measurements in CoSERV queries are not supported")
 
+// MaxStreamedDataSize is the maximum size of the data (evidence or
+// endorsements) that may be re-assembled from a client stream.
+var MaxStreamedDataSize int64 = 256 << 20
+
 // Supported parameters:
 //
 //   - vts.server-addr: string w/ syntax specified in
@@ -73,6 +80,7 @@ type GRPC struct {
 	PolicyManager            *policymanager.PolicyManager
 	EarSigner                earsigner.IEarSigner
 	CoservContext             *vtscoserv.Context
+	Provenance               *provenance.Store
 	rootCerts                *x509.CertPool
 
 	Server *grpc.Server
@@ -90,6 +98,7 @@ func NewGRPC(
 	policyManager *policymanager.PolicyManager,
 	earSigner earsigner.IEarSigner,
 	coservConfig *vtscoserv.Context,
+	provenanceStore *provenance.Store,
 	logger *zap.SugaredLogger,
 ) ITrustedServices {
 	return &GRPC{
@@ -99,6 +108,7 @@ func NewGRPC(
 		PolicyManager:            policyManager,
 		EarSigner:                earSigner,
 		CoservContext:             coservConfig,
+		Provenance:               provenanceStore,
 		logger:                   logger,
 	}
 }
@@ -193,6 +203,12 @@ func (o *GRPC) Close() error {
 		}
 	}
 
+	if o.Provenance != nil {
+		if err := o.Provenance.Close(); err != nil {
+			o.logger.Errorf("provenance store closure failed: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -217,7 +233,17 @@ func (o *GRPC) SubmitEndorsements(
 	ctx context.Context,
 	req *proto.SubmitEndorsementsRequest,
 ) (*proto.SubmitEndorsementsResponse, error) {
-	o.logger.Debugw("SubmitEndorsements", "media-type", req.MediaType)
+	o.logger.Debugw("SubmitEndorsements", "media-type", req.MediaType, "tenant-id", req.TenantId)
+
+	tenantID := req.TenantId
+	if tenantID == "" {
+		// submissions from provisioning services that are not tenant
+		// aware are for the default tenant
+		tenantID = DummyTenantID
+	} else if strings.ContainsAny(tenantID, ":/") {
+		err := fmt.Errorf("invalid tenant ID %q", tenantID)
+		return submitEndorsementErrorResponse(err), nil
+	}
 
 	mt, mtParams, err := mime.ParseMediaType(req.MediaType)
 	if err != nil {
@@ -225,10 +251,13 @@ func (o *GRPC) SubmitEndorsements(
 	}
 	profile := mtParams["profile"]
 
-	var uc *corim.UnsignedCorim
+	var (
+		uc     *corim.UnsignedCorim
+		signer string
+	)
 	switch mt {
 	case "application/rim+cose":
-		uc, err = o.decodeAndValidateSignedCorim(req.Data)
+		uc, signer, err = o.decodeAndValidateSignedCorim(req.Data)
 		if err != nil {
 			return submitEndorsementErrorResponse(err), nil
 		}
@@ -271,14 +300,79 @@ func (o *GRPC) SubmitEndorsements(
 		return submitEndorsementErrorResponse(resp.Error()), nil
 	}
 
-	label := fmt.Sprintf("%s/%s", DummyTenantID, handlerPlugin.GetAttestationScheme())
+	label := fmt.Sprintf("%s/%s", tenantID, handlerPlugin.GetAttestationScheme())
 	if err := o.Store.AddBytes(req.Data, label, true); err != nil {
 		return submitEndorsementErrorResponse(err), nil
 	}
 
+	if o.Provenance != nil {
+		rec := provenance.Record{
+			TagID:       uc.GetID(),
+			Submitter:   req.Submitter,
+			SubmittedAt: time.Now().UTC(),
+			Signer:      signer,
+		}
+
+		// The endorsements have already been added, so failing to
+		// record their provenance does not fail the submission.
+		if err := o.Provenance.Add(label, uc, rec); err != nil {
+			o.logger.Errorw("could not record provenance", "tag-id", rec.TagID, "error", err)
+		}
+	}
+
 	return submitEndorsementSuccessResponse(), nil
 }
 
+// SubmitEndorsementsStream re-assembles endorsements streamed in chunks (the
+// first of which carries the media type, submitter and tenant), and submits
+// them as per SubmitEndorsements.
+func (o *GRPC) SubmitEndorsementsStream(stream proto.VTS_SubmitEndorsementsStreamServer) error {
+	var (
+		req  proto.SubmitEndorsementsRequest
+		data bytes.Buffer
+	)
+
+	for first := true; ; first = false {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if first {
+			req.MediaType = chunk.GetMediaType()
+			req.Submitter = chunk.GetSubmitter()
+			req.TenantId = chunk.GetTenantId()
+		}
+
+		if err := appendChunk(&data, chunk.GetData()); err != nil {
+			return err
+		}
+	}
+
+	req.Data = data.Bytes()
+
+	resp, err := o.SubmitEndorsements(stream.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(resp)
+}
+
+// appendChunk appends a chunk of streamed data to buf, making sure that
+// the re-assembled data does not exceed MaxStreamedDataSize.
+func appendChunk(buf *bytes.Buffer, chunk []byte) error {
+	if int64(buf.Len()+len(chunk)) > MaxStreamedDataSize {
+		return fmt.Errorf("streamed data exceeds the maximum size of %d bytes",
+			MaxStreamedDataSize)
+	}
+
+	_, err := buf.Write(chunk)
+	return err
+}
+
 func submitEndorsementSuccessResponse() *proto.SubmitEndorsementsResponse {
 	return &proto.SubmitEndorsementsResponse{
 		Status: &proto.Status{
@@ -296,19 +390,21 @@ func submitEndorsementErrorResponse(err error) *proto.SubmitEndorsementsResponse
 	}
 }
 
-func (o *GRPC) decodeAndValidateSignedCorim(data []byte) (*corim.UnsignedCorim, error) {
+// decodeAndValidateSignedCorim returns the unsigned CoRIM inside the signed
+// CoRIM, along with the subject of the certificate it was signed with.
+func (o *GRPC) decodeAndValidateSignedCorim(data []byte) (*corim.UnsignedCorim, string, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("empty corim data")
+		return nil, "", fmt.Errorf("empty corim data")
 	}
 
 	// Parse the signed CoRIM which extracts certificate chain automatically through extractX5Chain
 	sc, err := corim.UnmarshalAndValidateSignedCorimFromCBOR(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse signed CoRIM: %w", err)
+		return nil, "", fmt.Errorf("failed to parse signed CoRIM: %w", err)
 	}
 
 	if sc.SigningCert == nil {
-		return nil, fmt.Errorf("no signing certificate found in the CoRIM")
+		return nil, "", fmt.Errorf("no signing certificate found in the CoRIM")
 	}
 
 	intermediateCertPool := x509.NewCertPool()
@@ -325,15 +421,15 @@ func (o *GRPC) decodeAndValidateSignedCorim(data []byte) (*corim.UnsignedCorim,
 
 	_, err = sc.SigningCert.Verify(verifyOpts)
 	if err != nil {
-		return nil, fmt.Errorf("certificate chain verification failed: %w", err)
+		return nil, "", fmt.Errorf("certificate chain verification failed: %w", err)
 	}
 
 	// Verify the signature using the signing certificate's public key
 	if err := sc.Verify(sc.SigningCert.PublicKey); err != nil {
-		return nil, fmt.Errorf("signature verification failed: %w", err)
+		return nil, "", fmt.Errorf("signature verification failed: %w", err)
 	}
 
-	return &sc.UnsignedCorim, nil
+	return &sc.UnsignedCorim, sc.SigningCert.Subject.String(), nil
 }
 
 func (o *GRPC) decodeAndValidateUnsignedCorim(data []byte) (*corim.UnsignedCorim, error) {
@@ -361,6 +457,18 @@ func (o *GRPC) GetAttestation(
 		return o.finalize(appraisal, err)
 	}
 
+	enabled, err := o.PolicyManager.IsSchemeEnabled(evidence.TenantID, appraisal.Scheme)
+	if err != nil {
+		return o.finalize(appraisal, err)
+	}
+
+	if !enabled {
+		appraisal.SetAllClaims(ear.UnexpectedEvidenceClaim)
+		appraisal.AddPolicyClaim("problem", "scheme is not enabled for the tenant")
+		return o.finalize(appraisal, handlermod.BadEvidence(
+			"scheme %s is not enabled for tenant %q", appraisal.Scheme, evidence.TenantID))
+	}
+
 	appraisal.TrustAnchorIDs, err = handler.GetTrustAnchorIDs(evidence)
 	if err != nil {
 		if errors.Is(err, handlermod.BadEvidenceError{}) {
@@ -465,6 +573,49 @@ func (o *GRPC) GetAttestation(
 	return o.finalize(appraisal, nil)
 }
 
+// GetAttestationStream re-assembles an attestation token streamed in chunks
+// (the first of which carries the token fields other than its data), and
+// appraises it as per GetAttestation.
+func (o *GRPC) GetAttestationStream(stream proto.VTS_GetAttestationStreamServer) error {
+	var (
+		token *proto.AttestationToken
+		data  bytes.Buffer
+	)
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if token == nil {
+			token = chunk.GetToken()
+			if token == nil {
+				return errors.New("attestation token not set in the first chunk")
+			}
+		}
+
+		if err := appendChunk(&data, chunk.GetData()); err != nil {
+			return err
+		}
+	}
+
+	if token == nil {
+		return errors.New("no attestation token received")
+	}
+
+	token.Data = data.Bytes()
+
+	appraisalCtx, err := o.GetAttestation(stream.Context(), token)
+	if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(appraisalCtx)
+}
+
 func (o *GRPC) getKeyTriples(
 	trustAnchorIDs []*comid.Environment,
 	label string,
@@ -513,6 +664,24 @@ func (c *GRPC) GetSupportedProvisioningMediaTypes(context.Context, *emptypb.Empt
 	return &proto.MediaTypeList{MediaTypes: mts}, nil
 }
 
+func (c *GRPC) GetProvisioningMediaTypeSchemes(
+	context.Context, *emptypb.Empty,
+) (*proto.MediaTypeSchemes, error) {
+	mts := c.SchemePluginManager.GetRegisteredMediaTypesByCategory("provisioning")
+	schemes := make(map[string]string, len(mts))
+
+	for _, mt := range mts {
+		handlerPlugin, err := c.SchemePluginManager.LookupByMediaType(mt)
+		if err != nil {
+			return nil, err
+		}
+
+		schemes[mt] = handlerPlugin.GetAttestationScheme()
+	}
+
+	return &proto.MediaTypeSchemes{Schemes: schemes}, nil
+}
+
 func (c *GRPC) assembleCoservMediaTypes(mts []string, filter string) []string {
 	mediaTypes := make([]string, 0, 10)
 
@@ -734,6 +903,17 @@ func (o *GRPC) finalize(
 
 	appraisal.Result.UpdateStatusFromTrustVector()
 
+	// Replace the endorsements recorded by the scheme with their
+	// provenance (or drop them, if provenance is not being kept).
+	if o.Provenance != nil {
+		if provErr := o.Provenance.Annotate(appraisal.StoreLabel(), appraisal.Result); provErr != nil {
+			o.logger.Errorw("could not resolve endorsement provenance", "error", provErr)
+			provenance.Strip(appraisal.Result)
+		}
+	} else {
+		provenance.Strip(appraisal.Result)
+	}
+
 	appraisal.SignedEAR, signErr = o.EarSigner.Sign(*appraisal.Result)
 	if signErr != nil {
 		// Signing error overrides whatever the problem that got us
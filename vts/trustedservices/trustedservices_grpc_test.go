@@ -0,0 +1,105 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package trustedservices
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corimstore "github.com/veraison/corim-store/pkg/store"
+	"github.com/veraison/corim/comid"
+	"github.com/veraison/services/builtin"
+	"github.com/veraison/services/handler"
+	"github.com/veraison/services/log"
+	"github.com/veraison/services/proto"
+	"github.com/veraison/services/vts/appraisal"
+	"github.com/veraison/services/vts/store"
+)
+
+const testPSAMediaType = `application/rim+cbor; profile="http://arm.com/psa/iot/1"`
+
+func newTestGRPC(t *testing.T) *GRPC {
+	sv := viper.New()
+	sv.Set("dbms", "sqlite3")
+	sv.Set("dsn", "file::memory:?cache=shared")
+
+	enStore, err := store.New(sv, log.Named("test-store"))
+	require.NoError(t, err)
+	t.Cleanup(func() { enStore.Close() })
+
+	loader, err := builtin.CreateBuiltinLoader(nil, nil, log.Named("builtin"))
+	require.NoError(t, err)
+
+	schemes, err := builtin.CreateBuiltinManagerWithLoader[handler.ISchemeHandler](
+		loader, log.Named("builtin"), "scheme-handler")
+	require.NoError(t, err)
+	t.Cleanup(func() { schemes.Close() })
+
+	return NewGRPC(enStore, schemes, nil, nil, nil, nil, nil, log.Named("test")).(*GRPC)
+}
+
+// getTestEndorsements returns the endorsements the VTS would match the evidence
+// of the specified tenant against.
+func getTestEndorsements(
+	t *testing.T,
+	vts *GRPC,
+	tenantID string,
+) ([]*comid.KeyTriple, []*comid.ValueTriple) {
+	appraisalCtx := appraisal.NewContext(&appraisal.Evidence{TenantID: tenantID})
+	require.NoError(t, appraisalCtx.SetScheme("PSA_IOT"))
+
+	anyEnv := []*comid.Environment{{}}
+
+	keyTriples, err := vts.getKeyTriples(anyEnv, appraisalCtx.StoreLabel(), false)
+	if err != nil {
+		require.ErrorIs(t, err, corimstore.ErrNoMatch)
+	}
+
+	valueTriples, err := vts.getValueTriples(anyEnv, appraisalCtx.StoreLabel(), false)
+	require.NoError(t, err)
+
+	return keyTriples, valueTriples
+}
+
+func TestGRPC_SubmitEndorsements_tenant(t *testing.T) {
+	vts := newTestGRPC(t)
+
+	data, err := os.ReadFile("../../scheme/psa-iot/test/corim/corim-psa-valid.cbor")
+	require.NoError(t, err)
+
+	resp, err := vts.SubmitEndorsements(context.Background(), &proto.SubmitEndorsementsRequest{
+		MediaType: testPSAMediaType,
+		Data:      data,
+		TenantId:  "acme",
+	})
+	require.NoError(t, err)
+	require.True(t, resp.GetStatus().GetResult(), resp.GetStatus().GetErrorDetail())
+
+	// the endorsements are matched against the evidence of the tenant they
+	// were provisioned for...
+	keyTriples, valueTriples := getTestEndorsements(t, vts, "acme")
+	assert.NotEmpty(t, keyTriples)
+	assert.NotEmpty(t, valueTriples)
+
+	// ...and not against that of other tenants
+	keyTriples, valueTriples = getTestEndorsements(t, vts, DummyTenantID)
+	assert.Empty(t, keyTriples)
+	assert.Empty(t, valueTriples)
+}
+
+func TestGRPC_SubmitEndorsements_bad_tenant(t *testing.T) {
+	vts := &GRPC{logger: log.Named("test")}
+
+	resp, err := vts.SubmitEndorsements(context.Background(), &proto.SubmitEndorsementsRequest{
+		MediaType: testPSAMediaType,
+		TenantId:  "acme/PSA_IOT",
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.GetStatus().GetResult())
+	assert.Contains(t, resp.GetStatus().GetErrorDetail(), `invalid tenant ID "acme/PSA_IOT"`)
+}
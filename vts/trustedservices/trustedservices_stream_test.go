@@ -0,0 +1,149 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package trustedservices
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/proto"
+	"google.golang.org/grpc"
+)
+
+// setTestMaxStreamedDataSize sets MaxStreamedDataSize for the duration of the
+// test.
+func setTestMaxStreamedDataSize(t *testing.T, size int64) {
+	old := MaxStreamedDataSize
+	MaxStreamedDataSize = size
+	t.Cleanup(func() { MaxStreamedDataSize = old })
+}
+
+// fakeTokenStream is a VTS_GetAttestationStreamServer receiving the chunks.
+type fakeTokenStream struct {
+	grpc.ServerStream
+
+	chunks []*proto.AttestationTokenChunk
+	sent   *proto.AppraisalContext
+}
+
+func (o *fakeTokenStream) Context() context.Context {
+	return context.Background()
+}
+
+func (o *fakeTokenStream) Recv() (*proto.AttestationTokenChunk, error) {
+	if len(o.chunks) == 0 {
+		return nil, io.EOF
+	}
+
+	chunk := o.chunks[0]
+	o.chunks = o.chunks[1:]
+
+	return chunk, nil
+}
+
+func (o *fakeTokenStream) SendAndClose(appraisalCtx *proto.AppraisalContext) error {
+	o.sent = appraisalCtx
+	return nil
+}
+
+// fakeEndorsementsStream is a VTS_SubmitEndorsementsStreamServer receiving
+// the chunks.
+type fakeEndorsementsStream struct {
+	grpc.ServerStream
+
+	chunks []*proto.SubmitEndorsementsChunk
+	sent   *proto.SubmitEndorsementsResponse
+}
+
+func (o *fakeEndorsementsStream) Context() context.Context {
+	return context.Background()
+}
+
+func (o *fakeEndorsementsStream) Recv() (*proto.SubmitEndorsementsChunk, error) {
+	if len(o.chunks) == 0 {
+		return nil, io.EOF
+	}
+
+	chunk := o.chunks[0]
+	o.chunks = o.chunks[1:]
+
+	return chunk, nil
+}
+
+func (o *fakeEndorsementsStream) SendAndClose(resp *proto.SubmitEndorsementsResponse) error {
+	o.sent = resp
+	return nil
+}
+
+func Test_appendChunk(t *testing.T) {
+	setTestMaxStreamedDataSize(t, 8)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, appendChunk(&buf, []byte("abcd")))
+	require.NoError(t, appendChunk(&buf, []byte("efgh")))
+	assert.Equal(t, "abcdefgh", buf.String())
+
+	err := appendChunk(&buf, []byte("i"))
+	assert.EqualError(t, err, "streamed data exceeds the maximum size of 8 bytes")
+	assert.Equal(t, "abcdefgh", buf.String())
+}
+
+func TestGRPC_GetAttestationStream_no_token(t *testing.T) {
+	vts := &GRPC{}
+
+	// the stream is empty
+	err := vts.GetAttestationStream(&fakeTokenStream{})
+	assert.EqualError(t, err, "no attestation token received")
+
+	// the token is only set in the second chunk
+	stream := &fakeTokenStream{
+		chunks: []*proto.AttestationTokenChunk{
+			{Data: []byte("abcd")},
+			{Token: &proto.AttestationToken{TenantId: "0"}, Data: []byte("efgh")},
+		},
+	}
+
+	err = vts.GetAttestationStream(stream)
+	assert.EqualError(t, err, "attestation token not set in the first chunk")
+	assert.Nil(t, stream.sent)
+}
+
+func TestGRPC_GetAttestationStream_too_large(t *testing.T) {
+	setTestMaxStreamedDataSize(t, 8)
+
+	vts := &GRPC{}
+
+	stream := &fakeTokenStream{
+		chunks: []*proto.AttestationTokenChunk{
+			{Token: &proto.AttestationToken{TenantId: "0"}, Data: []byte("abcd")},
+			{Data: []byte("efgh")},
+			{Data: []byte("i")},
+		},
+	}
+
+	err := vts.GetAttestationStream(stream)
+	assert.EqualError(t, err, "streamed data exceeds the maximum size of 8 bytes")
+	assert.Nil(t, stream.sent)
+}
+
+func TestGRPC_SubmitEndorsementsStream_too_large(t *testing.T) {
+	setTestMaxStreamedDataSize(t, 8)
+
+	vts := &GRPC{}
+
+	stream := &fakeEndorsementsStream{
+		chunks: []*proto.SubmitEndorsementsChunk{
+			{MediaType: "application/corim-unsigned+cbor", Data: []byte("abcdefgh")},
+			{Data: []byte("i")},
+		},
+	}
+
+	err := vts.SubmitEndorsementsStream(stream)
+	assert.EqualError(t, err, "streamed data exceeds the maximum size of 8 bytes")
+	assert.Nil(t, stream.sent)
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package vtsclient
+
+import (
+	"context"
+
+	"github.com/veraison/services/proto"
+)
+
+// ChunkSize is the size of the chunks in which large payloads are streamed
+// to the VTS. Payloads no larger than this are sent in a single message.
+var ChunkSize = 1 << 20
+
+// GetAttestation returns the appraisal context for the specified token. If
+// the token's data is larger than ChunkSize, it is streamed to the VTS in
+// chunks.
+func GetAttestation(
+	ctx context.Context,
+	c proto.VTSClient,
+	token *proto.AttestationToken,
+) (*proto.AppraisalContext, error) {
+	if len(token.Data) <= ChunkSize {
+		return c.GetAttestation(ctx, token)
+	}
+
+	stream, err := c.GetAttestationStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first chunk carries the token without its data
+	header := &proto.AttestationToken{
+		TenantId:       token.TenantId,
+		MediaType:      token.MediaType,
+		Nonce:          token.Nonce,
+		SessionContext: token.SessionContext,
+		SessionId:      token.SessionId,
+	}
+
+	for i, data := range splitChunks(token.Data) {
+		chunk := &proto.AttestationTokenChunk{Data: data}
+		if i == 0 {
+			chunk.Token = header
+		}
+
+		if err := stream.Send(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// SubmitEndorsements submits the endorsements in the specified request. If
+// the endorsements are larger than ChunkSize, they are streamed to the VTS in
+// chunks.
+func SubmitEndorsements(
+	ctx context.Context,
+	c proto.VTSClient,
+	req *proto.SubmitEndorsementsRequest,
+) (*proto.SubmitEndorsementsResponse, error) {
+	if len(req.Data) <= ChunkSize {
+		return c.SubmitEndorsements(ctx, req)
+	}
+
+	stream, err := c.SubmitEndorsementsStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, data := range splitChunks(req.Data) {
+		chunk := &proto.SubmitEndorsementsChunk{Data: data}
+		if i == 0 {
+			chunk.MediaType = req.MediaType
+			chunk.Submitter = req.Submitter
+			chunk.TenantId = req.TenantId
+		}
+
+		if err := stream.Send(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func splitChunks(data []byte) [][]byte {
+	var chunks [][]byte
+
+	for len(data) > ChunkSize {
+		chunks = append(chunks, data[:ChunkSize])
+		data = data[ChunkSize:]
+	}
+
+	return append(chunks, data)
+}
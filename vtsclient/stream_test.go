@@ -0,0 +1,224 @@
+// Copyright 2026 Contributors to the Veraison project.
+// SPDX-License-Identifier: Apache-2.0
+package vtsclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/veraison/services/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// setTestChunkSize sets ChunkSize for the duration of the test.
+func setTestChunkSize(t *testing.T, size int) {
+	old := ChunkSize
+	ChunkSize = size
+	t.Cleanup(func() { ChunkSize = old })
+}
+
+// recordingVTS records the requests it receives, and the chunks they were
+// streamed in.
+type recordingVTS struct {
+	proto.UnimplementedVTSServer
+
+	unaryCalls        int
+	tokenChunks       []*proto.AttestationTokenChunk
+	endorsementChunks []*proto.SubmitEndorsementsChunk
+}
+
+func (o *recordingVTS) GetAttestation(
+	ctx context.Context,
+	token *proto.AttestationToken,
+) (*proto.AppraisalContext, error) {
+	o.unaryCalls++
+	return &proto.AppraisalContext{Evidence: &proto.EvidenceContext{TenantId: token.TenantId}}, nil
+}
+
+func (o *recordingVTS) GetAttestationStream(stream proto.VTS_GetAttestationStreamServer) error {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		o.tokenChunks = append(o.tokenChunks, chunk)
+	}
+
+	return stream.SendAndClose(&proto.AppraisalContext{
+		Evidence: &proto.EvidenceContext{TenantId: o.tokenChunks[0].GetToken().GetTenantId()},
+	})
+}
+
+func (o *recordingVTS) SubmitEndorsements(
+	ctx context.Context,
+	req *proto.SubmitEndorsementsRequest,
+) (*proto.SubmitEndorsementsResponse, error) {
+	o.unaryCalls++
+	return &proto.SubmitEndorsementsResponse{Status: &proto.Status{Result: true}}, nil
+}
+
+func (o *recordingVTS) SubmitEndorsementsStream(stream proto.VTS_SubmitEndorsementsStreamServer) error {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		o.endorsementChunks = append(o.endorsementChunks, chunk)
+	}
+
+	return stream.SendAndClose(&proto.SubmitEndorsementsResponse{Status: &proto.Status{Result: true}})
+}
+
+func newTestVTSClient(t *testing.T, srv proto.VTSServer) proto.VTSClient {
+	lis := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer()
+	proto.RegisterVTSServer(server, srv)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return proto.NewVTSClient(conn)
+}
+
+func Test_splitChunks(t *testing.T) {
+	setTestChunkSize(t, 4)
+
+	for _, tc := range []struct {
+		data     string
+		expected []string
+	}{
+		{"", []string{""}},
+		{"abc", []string{"abc"}},
+		{"abcd", []string{"abcd"}},
+		{"abcde", []string{"abcd", "e"}},
+		{"abcdefgh", []string{"abcd", "efgh"}},
+		{"abcdefghi", []string{"abcd", "efgh", "i"}},
+	} {
+		var chunks []string
+		for _, chunk := range splitChunks([]byte(tc.data)) {
+			chunks = append(chunks, string(chunk))
+		}
+
+		assert.Equal(t, tc.expected, chunks, tc.data)
+	}
+}
+
+func TestGetAttestation_unary(t *testing.T) {
+	setTestChunkSize(t, 4)
+
+	srv := &recordingVTS{}
+	client := newTestVTSClient(t, srv)
+
+	// payloads no larger than ChunkSize are not streamed
+	ret, err := GetAttestation(context.Background(), client,
+		&proto.AttestationToken{TenantId: "acme", Data: []byte("abcd")})
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", ret.Evidence.TenantId)
+	assert.Equal(t, 1, srv.unaryCalls)
+	assert.Empty(t, srv.tokenChunks)
+}
+
+func TestGetAttestation_stream(t *testing.T) {
+	setTestChunkSize(t, 4)
+
+	srv := &recordingVTS{}
+	client := newTestVTSClient(t, srv)
+
+	token := &proto.AttestationToken{
+		TenantId:       "acme",
+		MediaType:      "application/eat+cwt",
+		Nonce:          []byte{0x01, 0x02},
+		SessionContext: []byte(`{"k": "v"}`),
+		SessionId:      "e3a5a1de-9f6a-4bd7-a5d9-0a2f8de5b7c3",
+		Data:           []byte("abcdefghi"),
+	}
+
+	ret, err := GetAttestation(context.Background(), client, token)
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", ret.Evidence.TenantId)
+	assert.Zero(t, srv.unaryCalls)
+	require.Len(t, srv.tokenChunks, 3)
+
+	// only the first chunk carries the token, without its data
+	header := srv.tokenChunks[0].GetToken()
+	require.NotNil(t, header)
+	assert.Equal(t, token.TenantId, header.TenantId)
+	assert.Equal(t, token.MediaType, header.MediaType)
+	assert.Equal(t, token.Nonce, header.Nonce)
+	assert.Equal(t, token.SessionContext, header.SessionContext)
+	assert.Equal(t, token.SessionId, header.SessionId)
+	assert.Empty(t, header.Data)
+
+	var data bytes.Buffer
+	for i, chunk := range srv.tokenChunks {
+		if i > 0 {
+			assert.Nil(t, chunk.GetToken(), i)
+		}
+		data.Write(chunk.GetData())
+	}
+
+	assert.Equal(t, token.Data, data.Bytes())
+}
+
+func TestSubmitEndorsements_stream(t *testing.T) {
+	setTestChunkSize(t, 4)
+
+	srv := &recordingVTS{}
+	client := newTestVTSClient(t, srv)
+
+	req := &proto.SubmitEndorsementsRequest{
+		MediaType: "application/corim-unsigned+cbor",
+		Submitter: "alice",
+		TenantId:  "acme",
+		Data:      []byte("abcdefgh"),
+	}
+
+	ret, err := SubmitEndorsements(context.Background(), client, req)
+	require.NoError(t, err)
+
+	assert.True(t, ret.Status.Result)
+	assert.Zero(t, srv.unaryCalls)
+	require.Len(t, srv.endorsementChunks, 2)
+
+	assert.Equal(t, req.MediaType, srv.endorsementChunks[0].GetMediaType())
+	assert.Equal(t, req.Submitter, srv.endorsementChunks[0].GetSubmitter())
+	assert.Equal(t, req.TenantId, srv.endorsementChunks[0].GetTenantId())
+	assert.Equal(t, "abcd", string(srv.endorsementChunks[0].GetData()))
+	assert.Empty(t, srv.endorsementChunks[1].GetMediaType())
+	assert.Empty(t, srv.endorsementChunks[1].GetSubmitter())
+	assert.Empty(t, srv.endorsementChunks[1].GetTenantId())
+	assert.Equal(t, "efgh", string(srv.endorsementChunks[1].GetData()))
+
+	// payloads no larger than ChunkSize are not streamed
+	_, err = SubmitEndorsements(context.Background(), client,
+		&proto.SubmitEndorsementsRequest{MediaType: req.MediaType, Data: []byte("abc")})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, srv.unaryCalls)
+	assert.Len(t, srv.endorsementChunks, 2)
+}
@@ -159,6 +159,32 @@ func (o *GRPC) SubmitEndorsements(
 	return c.SubmitEndorsements(ctx, in, opts...)
 }
 
+func (o *GRPC) GetAttestationStream(
+	ctx context.Context, opts ...grpc.CallOption,
+) (proto.VTS_GetAttestationStreamClient, error) {
+	if err := o.EnsureConnection(); err != nil {
+		return nil, NewNoConnectionError("GetAttestationStream", err)
+	}
+	c := o.GetProvisionerClient()
+	if c == nil {
+		return nil, ErrNoClient
+	}
+	return c.GetAttestationStream(ctx, opts...)
+}
+
+func (o *GRPC) SubmitEndorsementsStream(
+	ctx context.Context, opts ...grpc.CallOption,
+) (proto.VTS_SubmitEndorsementsStreamClient, error) {
+	if err := o.EnsureConnection(); err != nil {
+		return nil, NewNoConnectionError("SubmitEndorsementsStream", err)
+	}
+	c := o.GetProvisionerClient()
+	if c == nil {
+		return nil, ErrNoClient
+	}
+	return c.SubmitEndorsementsStream(ctx, opts...)
+}
+
 func (o *GRPC) GetProvisionerClient() proto.VTSClient {
 	if o.Connection == nil {
 		return nil
@@ -208,6 +234,26 @@ func (o *GRPC) GetCoservSigningPublicKey(ctx context.Context, in *emptypb.Empty,
 	return c.GetCoservSigningPublicKey(ctx, in, opts...)
 }
 
+func (o *GRPC) GetProvisioningMediaTypeSchemes(
+	ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption,
+) (*proto.MediaTypeSchemes, error) {
+	if err := o.EnsureConnection(); err != nil {
+		return nil, NewNoConnectionError("GetProvisioningMediaTypeSchemes", err)
+	}
+
+	c := o.GetProvisionerClient()
+	if c == nil {
+		return nil, ErrNoClient
+	}
+
+	mts, err := c.GetProvisioningMediaTypeSchemes(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeMediaTypeSchemes(mts), nil
+}
+
 func (o *GRPC) GetEndorsements(
 	ctx context.Context, in *proto.EndorsementQueryIn, opts ...grpc.CallOption,
 ) (*proto.EndorsementQueryOut, error) {
@@ -257,3 +303,18 @@ func normalizeMediaTypeList(mts *proto.MediaTypeList) *proto.MediaTypeList {
 
 	return &proto.MediaTypeList{MediaTypes: nmts}
 }
+
+func normalizeMediaTypeSchemes(mts *proto.MediaTypeSchemes) *proto.MediaTypeSchemes {
+	nmts := make(map[string]string, len(mts.GetSchemes()))
+
+	for mt, scheme := range mts.GetSchemes() {
+		nmt, err := api.NormalizeMediaType(mt)
+		if err != nil {
+			// skip invalid media type
+			continue
+		}
+		nmts[nmt] = scheme
+	}
+
+	return &proto.MediaTypeSchemes{Schemes: nmts}
+}